@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/buylist-manager/backend/internal/config"
+	"github.com/buylist-manager/backend/internal/database"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+const usage = `Usage: admin <command>
+
+Commands:
+  grant <email>    Give the user access to /api/v1/admin
+  revoke <email>   Take it away
+  list             List the admins
+
+Admin access is only granted from here, by whoever runs the server: the API
+never lets a user make itself admin.`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+
+	// Connect to database
+	db, err := database.Connect(cfg)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	users := repository.NewUserRepository(db)
+
+	switch os.Args[1] {
+	case "grant", "revoke":
+		if len(os.Args) != 3 {
+			fmt.Println(usage)
+			os.Exit(2)
+		}
+		email := strings.ToLower(strings.TrimSpace(os.Args[2]))
+		grant := os.Args[1] == "grant"
+		if err := users.SetAdmin(email, grant); err != nil {
+			log.Fatalf("Failed to update %s: %v", email, err)
+		}
+		if grant {
+			fmt.Printf("✅ %s is now an admin\n", email)
+		} else {
+			fmt.Printf("↩️  %s is no longer an admin\n", email)
+		}
+
+	case "list":
+		admins, err := users.FindAdmins()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(admins) == 0 {
+			fmt.Println("No admins yet: use admin grant <email>")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tEMAIL\tNAME")
+		for _, u := range admins {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", u.ID, u.Email, u.Name)
+		}
+		w.Flush()
+
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
@@ -1,13 +1,24 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/buylist-manager/backend/internal/config"
 	"github.com/buylist-manager/backend/internal/database"
 	"github.com/buylist-manager/backend/internal/handlers"
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/migrations"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/notifier"
+	"github.com/buylist-manager/backend/internal/provider"
 	"github.com/buylist-manager/backend/internal/repository"
+	"github.com/buylist-manager/backend/internal/scheduler"
 	"github.com/buylist-manager/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -28,12 +39,23 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
-	// Run database seeds (only in development)
+	// Apply pending migrations automatically only in development.
+	// En producción se corren explícitamente con: go run ./cmd/migrate up
 	if cfg.Env == "development" {
-		if err := database.Seed(db); err != nil {
-			log.Printf("Warning: Failed to seed database: %v", err)
-		} else {
-			log.Println("Database seeded successfully")
+		applied, err := migrations.Up(db)
+		if err != nil {
+			log.Fatal("Failed to run migrations:", err)
+		}
+		for _, m := range applied {
+			log.Printf("Applied migration %04d_%s", m.Version, m.Name)
+		}
+	} else {
+		pending, err := migrations.Pending(db)
+		if err != nil {
+			log.Fatal("Failed to check migrations:", err)
+		}
+		if len(pending) > 0 {
+			log.Fatalf("Database has %d pending migrations, run: go run ./cmd/migrate up", len(pending))
 		}
 	}
 
@@ -41,6 +63,21 @@ func main() {
 	categoryRepo := repository.NewCategoryRepository(db)
 	subcategoryRepo := repository.NewSubcategoryRepository(db)
 	productRepo := repository.NewProductRepository(db)
+	priceHistoryRepo := repository.NewPriceHistoryRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db)
+	priceIndexRepo := repository.NewPriceIndexRepository(db)
+	offerRepo := repository.NewOfferRepository(db)
+	storeRepo := repository.NewStoreRepository(db)
+	taxProfileRepo := repository.NewTaxProfileRepository(db)
+	scheduledJobRepo := repository.NewScheduledJobRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookDeliveryRepo := repository.NewWebhookDeliveryRepository(db)
+	statsSnapshotRepo := repository.NewStatsSnapshotRepository(db)
+	transactor := repository.NewTransactor(db)
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
@@ -52,19 +89,145 @@ func main() {
 	app.Use(logger.New())  // Request logging
 
 	// CORS configuration
+	// Quick-add tiene su propia política, sólo en esa ruta (ver más abajo)
+	isQuickAdd := func(c *fiber.Ctx) bool {
+		return strings.TrimSuffix(c.Path(), "/") == "/api/v1/quick-add"
+	}
 	app.Use(cors.New(cors.Config{
+		Next:         isQuickAdd,
 		AllowOrigins: cfg.FrontendURL,
 		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
 		AllowMethods: "GET, POST, PUT, DELETE, PATCH, OPTIONS",
 	}))
 
 	// Initialize services
-	productService := services.NewProductService(productRepo, categoryRepo, subcategoryRepo)
+	authService := services.NewAuthService(
+		userRepo, refreshTokenRepo, categoryRepo, subcategoryRepo, transactor,
+		cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL,
+	)
+	apiTokenService := services.NewAPITokenService(apiTokenRepo)
+	webhookService := services.NewWebhookService(webhookRepo, webhookDeliveryRepo, cfg.WebhookAllowPrivate)
+
+	// Initialize alert notifier (log, webhook, smtp): cada usuario recibe sólo las suyas
+	alertNotifier, err := notifier.New(cfg, webhookService)
+	if err != nil {
+		log.Fatal("Failed to configure alert notifier:", err)
+	}
+	alertService := services.NewAlertService(alertRepo, userRepo, alertNotifier)
+
+	// Domain events: lo que pasa cuando cambia un producto, en orden de suscripción
+	events := services.NewEventBus()
+	services.SubscribePurchaseDate(events, productRepo)
+	services.SubscribePriceHistory(events, priceHistoryRepo)
+	services.SubscribeAlerts(events, alertService)
+	services.SubscribeWebhooks(events, webhookService)
+
+	exchangeRateService := services.NewExchangeRateService(exchangeRateRepo, transactor)
+	priceIndexService := services.NewPriceIndexService(priceIndexRepo, transactor, cfg.DefaultCurrency, cfg.StalePriceThreshold)
+	productService := services.NewProductService(productRepo, categoryRepo, subcategoryRepo, priceHistoryRepo, storeRepo, taxProfileRepo, transactor, events, exchangeRateService, priceIndexService, cfg.DefaultCurrency)
+	offerService := services.NewOfferService(offerRepo, transactor, productService, exchangeRateService)
+	storeService := services.NewStoreService(storeRepo, productRepo, taxProfileRepo, transactor, productService, exchangeRateService, cfg.DefaultCurrency)
+	taxProfileService := services.NewTaxProfileService(taxProfileRepo, productRepo, storeRepo, transactor, productService)
+	exportService := services.NewExportService(categoryRepo, subcategoryRepo, productRepo, priceHistoryRepo, alertRepo, exchangeRateRepo, priceIndexRepo, offerRepo, storeRepo, taxProfileRepo, webhookRepo)
+	importService := services.NewImportService(categoryRepo, subcategoryRepo, productService, transactor)
+	quickAddService := services.NewQuickAddService(productRepo, subcategoryRepo, productService)
+	priceRefreshService := services.NewPriceRefreshService(provider.New(cfg), productRepo, offerRepo, productService, offerService)
+	budgetService := services.NewBudgetService(categoryRepo, subcategoryRepo, productRepo, exchangeRateService, cfg.DefaultCurrency)
+	statsSnapshotService := services.NewStatsSnapshotService(statsSnapshotRepo, userRepo, productService, cfg.DefaultCurrency)
+
+	// Background jobs. El schedule es el inicial: después manda el guardado en la base
+	jobs := scheduler.New(scheduledJobRepo)
+	mustRegister := func(job scheduler.Job) {
+		if err := jobs.Register(job); err != nil {
+			log.Fatal("Failed to register job:", err)
+		}
+	}
+	// Avanza las fechas de cobro de las suscripciones vencidas, al iniciar y cada hora
+	mustRegister(scheduler.Job{
+		Name:       "renew_subscriptions",
+		Schedule:   "@hourly",
+		RunOnStart: true,
+		Run: func(ctx context.Context) (string, error) {
+			renewed, err := productService.RenewSubscriptions(time.Now())
+			return fmt.Sprintf("renewed %d subscriptions", renewed), err
+		},
+	})
+	// Consulta los precios a las tiendas; los cambios disparan las alertas
+	mustRegister(scheduler.Job{
+		Name:     "refresh_prices",
+		Schedule: "0 6 * * *",
+		Run: func(ctx context.Context) (string, error) {
+			summary, err := priceRefreshService.RefreshAll(ctx, time.Now().Add(-time.Hour))
+			if summary == nil {
+				return "", err
+			}
+			return fmt.Sprintf("checked %d products, %d changed, %d skipped, %d failed",
+				summary.Checked, summary.Changed, summary.Skipped, summary.Failed), err
+		},
+	})
+	// Reintenta las entregas de webhooks que fallaron (las nuevas salen al momento)
+	mustRegister(scheduler.Job{
+		Name:     "deliver_webhooks",
+		Schedule: "@every 1m",
+		Run: func(ctx context.Context) (string, error) {
+			summary, err := webhookService.DeliverDue(ctx)
+			if summary == nil {
+				return "", err
+			}
+			return fmt.Sprintf("sent %d deliveries, %d to retry, %d failed",
+				summary.Sent, summary.Retrying, summary.Failed), err
+		},
+	})
+	// Guarda los totales del día de cada usuario para ver su evolución
+	mustRegister(scheduler.Job{
+		Name:     "snapshot_stats",
+		Schedule: "0 2 * * *",
+		Run: func(ctx context.Context) (string, error) {
+			summary, err := statsSnapshotService.SnapshotAll(time.Now())
+			if summary == nil {
+				return "", err
+			}
+			return fmt.Sprintf("took %d snapshots, %d skipped for missing exchange rates",
+				summary.Taken, summary.Skipped), err
+		},
+	})
+	if cfg.BackupDir != "" {
+		backupService := services.NewBackupService(userRepo, exportService, cfg.BackupDir, cfg.BackupKeep)
+		mustRegister(scheduler.Job{
+			Name:     "backup",
+			Schedule: "0 3 * * *",
+			Run: func(ctx context.Context) (string, error) {
+				written, err := backupService.Backup(time.Now())
+				return fmt.Sprintf("wrote %d backups to %s", written, cfg.BackupDir), err
+			},
+		})
+	}
+	if cfg.SchedulerEnabled {
+		if err := jobs.Start(); err != nil {
+			log.Fatal("Failed to start scheduler:", err)
+		}
+	}
 
 	// Initialize handlers
+	authHandler := handlers.NewAuthHandler(authService)
+	apiTokenHandler := handlers.NewAPITokenHandler(apiTokenService)
 	categoryHandler := handlers.NewCategoryHandler(categoryRepo)
 	subcategoryHandler := handlers.NewSubcategoryHandler(subcategoryRepo, categoryRepo)
 	productHandler := handlers.NewProductHandler(productRepo, productService)
+	alertHandler := handlers.NewAlertHandler(alertService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	importHandler := handlers.NewImportHandler(importService)
+	quickAddHandler := handlers.NewQuickAddHandler(quickAddService)
+	priceRefreshHandler := handlers.NewPriceRefreshHandler(productRepo, priceRefreshService)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateService)
+	priceIndexHandler := handlers.NewPriceIndexHandler(priceIndexService)
+	offerHandler := handlers.NewOfferHandler(productRepo, offerRepo, offerService)
+	storeHandler := handlers.NewStoreHandler(storeRepo, storeService)
+	taxProfileHandler := handlers.NewTaxProfileHandler(taxProfileRepo, taxProfileService)
+	budgetHandler := handlers.NewBudgetHandler(categoryRepo, subcategoryRepo, budgetService)
+	webhookHandler := handlers.NewWebhookHandler(webhookRepo, webhookService)
+	statsSnapshotHandler := handlers.NewStatsSnapshotHandler(statsSnapshotService)
+	jobHandler := handlers.NewJobHandler(jobs)
 
 	// Routes
 	api := app.Group("/api/v1")
@@ -77,6 +240,35 @@ func main() {
 		})
 	})
 
+	// Auth routes (públicas)
+	auth := api.Group("/auth")
+	auth.Post("/register", authHandler.Register)                // POST /api/v1/auth/register
+	auth.Post("/login", authHandler.Login)                      // POST /api/v1/auth/login
+	auth.Post("/refresh", authHandler.Refresh)                  // POST /api/v1/auth/refresh
+	auth.Post("/logout", authHandler.Logout)                    // POST /api/v1/auth/logout
+
+	// Quick-add (bookmarklet): postea desde la página de la tienda, así que acepta
+	// cualquier origen. Se autentica con un API token de scope quick_add, no con
+	// cookies ni con el access token, porque la página puede leerlo.
+	quickAddCORS := cors.New(cors.Config{
+		AllowOrigins: "*",
+		AllowHeaders: "Content-Type, Authorization",
+		AllowMethods: "POST",
+	})
+	quickAddAuth := middleware.RequireAPIToken(apiTokenService, models.APITokenScopeQuickAdd)
+	api.Options("/quick-add", quickAddCORS)                     // Preflight
+	api.Post("/quick-add", quickAddCORS, quickAddAuth, quickAddHandler.QuickAdd) // POST /api/v1/quick-add
+
+	// Todo lo que sigue requiere "Authorization: Bearer <access_token>"
+	api.Use(middleware.RequireAuth(authService))
+	api.Get("/auth/me", authHandler.Me)                         // GET /api/v1/auth/me
+
+	// API tokens (bookmarklet)
+	apiTokens := api.Group("/api-tokens")
+	apiTokens.Get("/", apiTokenHandler.GetAll)                  // GET /api/v1/api-tokens
+	apiTokens.Post("/", apiTokenHandler.Create)                 // POST /api/v1/api-tokens
+	apiTokens.Delete("/:id", apiTokenHandler.Revoke)            // DELETE /api/v1/api-tokens/1
+
 	// Category routes
 	categories := api.Group("/categories")
 	categories.Get("/", categoryHandler.GetAll)
@@ -84,6 +276,8 @@ func main() {
 	categories.Post("/", categoryHandler.Create)
 	categories.Put("/:id", categoryHandler.Update)
 	categories.Delete("/:id", categoryHandler.Delete)
+	categories.Put("/:id/budget", budgetHandler.SetCategoryBudget)          // PUT /api/v1/categories/1/budget
+	categories.Delete("/:id/budget", budgetHandler.DeleteCategoryBudget)    // DELETE /api/v1/categories/1/budget
 
 	// Subcategory routes
 	subcategories := api.Group("/subcategories")
@@ -92,23 +286,123 @@ func main() {
 	subcategories.Post("/", subcategoryHandler.Create)          // POST /api/v1/subcategories
 	subcategories.Put("/:id", subcategoryHandler.Update)        // PUT /api/v1/subcategories/1
 	subcategories.Delete("/:id", subcategoryHandler.Delete)     // DELETE /api/v1/subcategories/1
+	subcategories.Put("/:id/budget", budgetHandler.SetSubcategoryBudget)       // PUT /api/v1/subcategories/1/budget
+	subcategories.Delete("/:id/budget", budgetHandler.DeleteSubcategoryBudget) // DELETE /api/v1/subcategories/1/budget
+
+	// Budget routes
+	budgets := api.Group("/budgets")
+	budgets.Get("/status", budgetHandler.GetStatus)              // GET /api/v1/budgets/status
 
 	// Product routes
 	products := api.Group("/products")
 	products.Get("/", productHandler.GetAll)                    // GET /api/v1/products?pending=true&category_id=1
-	products.Get("/stats", productHandler.GetStats)             // GET /api/v1/products/stats
+	products.Get("/stats", productHandler.GetStats)             // GET /api/v1/products/stats?currency=USD
+	products.Get("/stats/history", statsSnapshotHandler.GetAll) // GET /api/v1/products/stats/history?from=2026-01
+	products.Get("/search", productHandler.Search)              // GET /api/v1/products/search?q=teclado
+	products.Get("/stale-prices", productHandler.GetStalePrices) // GET /api/v1/products/stale-prices
 	products.Get("/:id", productHandler.GetByID)                // GET /api/v1/products/1
+	products.Get("/:id/price-history", productHandler.GetPriceHistory) // GET /api/v1/products/1/price-history?from=2026-01-01&to=2026-02-01
+	products.Get("/:id/installments", productHandler.GetInstallments)  // GET /api/v1/products/1/installments
+	products.Get("/:id/offers", offerHandler.Compare)                  // GET /api/v1/products/1/offers
+	products.Post("/:id/offers", offerHandler.Create)                  // POST /api/v1/products/1/offers
+	products.Put("/:id/offers/:offer_id", offerHandler.Update)         // PUT /api/v1/products/1/offers/2
+	products.Delete("/:id/offers/:offer_id", offerHandler.Delete)      // DELETE /api/v1/products/1/offers/2
+	products.Put("/:id/chosen-offer", offerHandler.Choose)             // PUT /api/v1/products/1/chosen-offer
+	products.Post("/:id/refresh-price", priceRefreshHandler.Refresh)   // POST /api/v1/products/1/refresh-price
 	products.Post("/", productHandler.Create)                   // POST /api/v1/products
 	products.Put("/:id", productHandler.Update)                 // PUT /api/v1/products/1
 	products.Delete("/:id", productHandler.Delete)              // DELETE /api/v1/products/1
 
+	// Alert routes
+	alerts := api.Group("/alerts")
+	alerts.Get("/", alertHandler.GetAll)                        // GET /api/v1/alerts?acknowledged=false
+	alerts.Patch("/:id/acknowledge", alertHandler.Acknowledge)  // PATCH /api/v1/alerts/1/acknowledge
+
+	// Exchange rate routes
+	exchangeRates := api.Group("/exchange-rates")
+	exchangeRates.Get("/", exchangeRateHandler.GetAll)          // GET /api/v1/exchange-rates?currency=USD
+	exchangeRates.Post("/", exchangeRateHandler.Create)         // POST /api/v1/exchange-rates
+	exchangeRates.Post("/import", exchangeRateHandler.Import)   // POST /api/v1/exchange-rates/import
+	exchangeRates.Delete("/:id", exchangeRateHandler.Delete)    // DELETE /api/v1/exchange-rates/1
+
+	// Price index routes (inflación)
+	priceIndexes := api.Group("/price-indexes")
+	priceIndexes.Get("/", priceIndexHandler.GetAll)             // GET /api/v1/price-indexes?currency=ARS
+	priceIndexes.Post("/", priceIndexHandler.Create)            // POST /api/v1/price-indexes
+	priceIndexes.Post("/import", priceIndexHandler.Import)      // POST /api/v1/price-indexes/import
+	priceIndexes.Delete("/:id", priceIndexHandler.Delete)       // DELETE /api/v1/price-indexes/1
+
+	// Store routes
+	stores := api.Group("/stores")
+	stores.Get("/", storeHandler.GetAll)                        // GET /api/v1/stores
+	stores.Get("/stats", storeHandler.GetStats)                 // GET /api/v1/stores/stats?currency=ARS
+	stores.Get("/:id", storeHandler.GetByID)                    // GET /api/v1/stores/1
+	stores.Post("/", storeHandler.Create)                       // POST /api/v1/stores
+	stores.Put("/:id", storeHandler.Update)                     // PUT /api/v1/stores/1
+	stores.Delete("/:id", storeHandler.Delete)                  // DELETE /api/v1/stores/1
+
+	// Tax profile routes
+	taxProfiles := api.Group("/tax-profiles")
+	taxProfiles.Get("/", taxProfileHandler.GetAll)              // GET /api/v1/tax-profiles
+	taxProfiles.Get("/:id", taxProfileHandler.GetByID)          // GET /api/v1/tax-profiles/1
+	taxProfiles.Post("/", taxProfileHandler.Create)             // POST /api/v1/tax-profiles
+	taxProfiles.Put("/:id", taxProfileHandler.Update)           // PUT /api/v1/tax-profiles/1
+	taxProfiles.Delete("/:id", taxProfileHandler.Delete)        // DELETE /api/v1/tax-profiles/1
+
+	// Export routes
+	export := api.Group("/export")
+	export.Get("/", exportHandler.Export)                       // GET /api/v1/export?format=csv&pending=true
+	export.Get("/dump", exportHandler.Dump)                     // GET /api/v1/export/dump
+
+	// Import routes
+	imports := api.Group("/import")
+	imports.Post("/products", importHandler.ImportProducts)     // POST /api/v1/import/products?dry_run=true
+
+	// Webhook routes
+	webhooks := api.Group("/webhooks")
+	webhooks.Get("/", webhookHandler.GetAll)                    // GET /api/v1/webhooks
+	webhooks.Get("/:id", webhookHandler.GetByID)                // GET /api/v1/webhooks/1
+	webhooks.Get("/:id/deliveries", webhookHandler.GetDeliveries) // GET /api/v1/webhooks/1/deliveries?status=failed
+	webhooks.Post("/", webhookHandler.Create)                   // POST /api/v1/webhooks
+	webhooks.Put("/:id", webhookHandler.Update)                 // PUT /api/v1/webhooks/1
+	webhooks.Delete("/:id", webhookHandler.Delete)              // DELETE /api/v1/webhooks/1
+
+	// Admin routes (usuarios con is_admin, ver cmd/admin)
+	admin := api.Group("/admin", middleware.RequireAdmin(authService))
+	admin.Get("/jobs", jobHandler.GetAll)                       // GET /api/v1/admin/jobs
+	admin.Patch("/jobs/:name", jobHandler.Update)               // PATCH /api/v1/admin/jobs/backup
+	admin.Post("/jobs/:name/run", jobHandler.Run)               // POST /api/v1/admin/jobs/backup/run
+
 	// Start server
 	addr := fmt.Sprintf(":%s", cfg.Port)
 	log.Printf("🚀 Server starting on http://localhost%s", addr)
 	log.Printf("📖 Environment: %s", cfg.Env)
 	log.Printf("🗄️  Database: %s", cfg.DBDriver)
 
-	if err := app.Listen(addr); err != nil {
+	// Con SIGINT/SIGTERM se dejan de aceptar requests y se espera a que terminen
+	// los requests y los jobs en curso
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(addr)
+	}()
+
+	select {
+	case err := <-listenErr:
 		log.Fatal("Failed to start server:", err)
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down...")
+	if err := app.ShutdownWithTimeout(10 * time.Second); err != nil {
+		log.Printf("Warning: server shutdown: %v", err)
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := jobs.Stop(shutdownCtx); err != nil {
+		log.Printf("Warning: background jobs didn't stop in time: %v", err)
 	}
+	log.Println("Server stopped")
 }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/buylist-manager/backend/internal/pagemeta"
+)
+
+const usage = `Usage: pagemeta <file.html> [page-url]
+
+Prints what quick-add extracts from a saved page, without a network or a
+database. The page URL resolves relative image URLs.
+
+Example:
+  go run ./cmd/pagemeta internal/pagemeta/testdata/jsonld.html https://tienda.example.com/p/1`
+
+func main() {
+	if len(os.Args) < 2 || len(os.Args) > 3 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	page, err := os.ReadFile(os.Args[1])
+	if err != nil {
+		log.Fatal("Failed to read page:", err)
+	}
+	pageURL := ""
+	if len(os.Args) == 3 {
+		pageURL = os.Args[2]
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(pagemeta.Extract(pageURL, string(page))); err != nil {
+		log.Fatal(err)
+	}
+}
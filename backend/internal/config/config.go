@@ -1,9 +1,13 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
+	"github.com/buylist-manager/backend/internal/money"
 	"github.com/joho/godotenv"
 )
 
@@ -25,6 +29,37 @@ type Config struct {
 
 	// CORS
 	FrontendURL string
+
+	// Auth
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// Moneda de los productos sin currency y de las estadísticas por defecto
+	DefaultCurrency string
+
+	// Inflación (en %) desde price_date a partir de la cual un precio se marca como desactualizado
+	StalePriceThreshold float64
+
+	// Alerts
+	AlertNotifiers string // Comma separated: "log", "webhook", "smtp"
+	SMTPHost       string
+	SMTPPort       string
+	SMTPUser       string
+	SMTPPassword   string
+	SMTPFrom       string
+
+	// Price providers (la URL base se puede apuntar a un servidor local para probar)
+	MercadoLibreAPIURL string
+	MercadoLibreToken  string // Opcional
+
+	// Webhooks a direcciones privadas o loopback: sólo para instalaciones en una red propia
+	WebhookAllowPrivate bool
+
+	// Background jobs
+	SchedulerEnabled bool
+	BackupDir        string // Vacío = sin job de backup
+	BackupKeep       int    // Backups que se conservan por usuario
 }
 
 // Load loads configuration from environment variables
@@ -44,6 +79,53 @@ func Load() (*Config, error) {
 		Port:        getEnv("PORT", "8080"),
 		Env:         getEnv("ENV", "development"),
 		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:5173"),
+
+		AlertNotifiers: getEnv("ALERT_NOTIFIERS", "log"),
+		SMTPHost:       getEnv("SMTP_HOST", ""),
+		SMTPPort:       getEnv("SMTP_PORT", "587"),
+		SMTPUser:       getEnv("SMTP_USER", ""),
+		SMTPPassword:   getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:       getEnv("SMTP_FROM", ""),
+
+		MercadoLibreAPIURL: getEnv("MERCADOLIBRE_API_URL", "https://api.mercadolibre.com"),
+		MercadoLibreToken:  getEnv("MERCADOLIBRE_ACCESS_TOKEN", ""),
+
+		BackupDir: getEnv("BACKUP_DIR", ""),
+
+		JWTSecret: getEnv("JWT_SECRET", ""),
+	}
+
+	var err error
+	if cfg.AccessTokenTTL, err = time.ParseDuration(getEnv("JWT_ACCESS_TTL", "15m")); err != nil {
+		return nil, fmt.Errorf("invalid JWT_ACCESS_TTL: %w", err)
+	}
+	if cfg.RefreshTokenTTL, err = time.ParseDuration(getEnv("JWT_REFRESH_TTL", "720h")); err != nil {
+		return nil, fmt.Errorf("invalid JWT_REFRESH_TTL: %w", err)
+	}
+
+	if cfg.DefaultCurrency, err = money.NormalizeCurrency(getEnv("DEFAULT_CURRENCY", "ARS")); err != nil {
+		return nil, fmt.Errorf("invalid DEFAULT_CURRENCY: %w", err)
+	}
+	if cfg.StalePriceThreshold, err = strconv.ParseFloat(getEnv("STALE_PRICE_THRESHOLD", "10"), 64); err != nil || cfg.StalePriceThreshold <= 0 {
+		return nil, errors.New("invalid STALE_PRICE_THRESHOLD: must be a positive percentage")
+	}
+
+	if cfg.WebhookAllowPrivate, err = strconv.ParseBool(getEnv("WEBHOOK_ALLOW_PRIVATE", "false")); err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_ALLOW_PRIVATE: %w", err)
+	}
+	if cfg.SchedulerEnabled, err = strconv.ParseBool(getEnv("SCHEDULER_ENABLED", "true")); err != nil {
+		return nil, fmt.Errorf("invalid SCHEDULER_ENABLED: %w", err)
+	}
+	if cfg.BackupKeep, err = strconv.Atoi(getEnv("BACKUP_KEEP", "7")); err != nil || cfg.BackupKeep < 1 {
+		return nil, errors.New("invalid BACKUP_KEEP: must be a positive number")
+	}
+
+	// En desarrollo se permite un secret fijo para no tener que configurarlo
+	if cfg.JWTSecret == "" {
+		if cfg.Env != "development" {
+			return nil, errors.New("JWT_SECRET is required outside development")
+		}
+		cfg.JWTSecret = "buylist-development-secret"
 	}
 
 	return cfg, nil
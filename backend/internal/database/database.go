@@ -2,23 +2,30 @@ package database
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/buylist-manager/backend/internal/config"
-	"github.com/buylist-manager/backend/internal/models"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// Connect establishes a database connection and runs migrations
+// Connect establishes a database connection.
+// Schema changes are applied separately with the migrations package (cmd/migrate).
 func Connect(cfg *config.Config) (*gorm.DB, error) {
 	var dialector gorm.Dialector
 
-	// Choose database driver (only PostgreSQL supported for now)
-	if cfg.DBDriver != "postgres" {
-		return nil, fmt.Errorf("only postgres driver is supported, got: %s", cfg.DBDriver)
+	// Choose database driver
+	switch cfg.DBDriver {
+	case "postgres":
+		dialector = postgres.Open(cfg.GetDatabaseDSN())
+	case "sqlite":
+		// Pure Go driver (sin CGO), así el binario sigue siendo uno solo
+		dialector = sqlite.Open(sqliteDSN(cfg.GetDatabaseDSN()))
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s (use postgres or sqlite)", cfg.DBDriver)
 	}
-	dialector = postgres.Open(cfg.GetDatabaseDSN())
 
 	// Configure GORM
 	gormConfig := &gorm.Config{}
@@ -32,61 +39,23 @@ func Connect(cfg *config.Config) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Run migrations
-	if err := migrate(db); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-
 	return db, nil
 }
 
-// migrate runs all database migrations
-func migrate(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&models.Category{},
-		&models.Subcategory{},
-		&models.Product{},
-	)
-}
-
-// Seed populates the database with initial data
-func Seed(db *gorm.DB) error {
-	// Check if categories already exist
-	var count int64
-	db.Model(&models.Category{}).Count(&count)
-	if count > 0 {
-		return nil // Already seeded
-	}
-
-	// Create categories
-	categories := []models.Category{
-		{Name: "Compra Única", Type: "one_time"},
-		{Name: "Suscripción Mensual", Type: "recurring"},
-		{Name: "Suscripción Anual", Type: "recurring"},
+// sqliteDSN adds the pragmas the app relies on to a SQLite DSN:
+// foreign keys (ON DELETE CASCADE), WAL + busy timeout for concurrent requests,
+// and immediate transactions so read-then-write transactions don't fail with SQLITE_BUSY.
+func sqliteDSN(dsn string) string {
+	params := []string{
+		"_pragma=foreign_keys(1)",
+		"_pragma=journal_mode(WAL)",
+		"_pragma=busy_timeout(5000)",
+		"_txlock=immediate",
 	}
 
-	for i := range categories {
-		if err := db.Create(&categories[i]).Error; err != nil {
-			return fmt.Errorf("failed to seed categories: %w", err)
-		}
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
 	}
-
-	// Create subcategories
-	subcategories := []models.Subcategory{
-		{CategoryID: 1, Name: "Reparación de Electrónicos"},
-		{CategoryID: 1, Name: "Trabajo/Productividad"},
-		{CategoryID: 1, Name: "Gaming"},
-		{CategoryID: 1, Name: "Hogar"},
-		{CategoryID: 2, Name: "IA y Herramientas"},
-		{CategoryID: 2, Name: "Entretenimiento"},
-		{CategoryID: 3, Name: "Software Profesional"},
-	}
-
-	for i := range subcategories {
-		if err := db.Create(&subcategories[i]).Error; err != nil {
-			return fmt.Errorf("failed to seed subcategories: %w", err)
-		}
-	}
-
-	return nil
+	return dsn + separator + strings.Join(params, "&")
 }
@@ -0,0 +1,58 @@
+// Package dbtest opens real databases for the tests of the packages that
+// query them
+package dbtest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/config"
+	"github.com/buylist-manager/backend/internal/database"
+	"github.com/buylist-manager/backend/internal/migrations"
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// New opens a SQLite database in a temporary file with every migration
+// applied, so the queries run against the real schema
+func New(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := database.Connect(&config.Config{
+		DBDriver: "sqlite",
+		DBDSN:    filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if _, err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// CreateUser inserts a user to own the test data
+func CreateUser(t *testing.T, db *gorm.DB, email string) *models.User {
+	t.Helper()
+	user := &models.User{Email: email, PasswordHash: "x"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// MustCreate inserts each record or fails the test
+func MustCreate(t *testing.T, db *gorm.DB, records ...interface{}) {
+	t.Helper()
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AlertHandler handles HTTP requests for price alerts
+type AlertHandler struct {
+	service services.AlertService
+}
+
+// NewAlertHandler creates a new AlertHandler
+func NewAlertHandler(service services.AlertService) *AlertHandler {
+	return &AlertHandler{service: service}
+}
+
+// GetAll retrieves triggered alerts
+// Filtro opcional: ?acknowledged=false para ver solo las no leídas
+func (h *AlertHandler) GetAll(c *fiber.Ctx) error {
+	var acknowledged *bool
+	if value := c.Query("acknowledged"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid acknowledged parameter",
+			})
+		}
+		acknowledged = &parsed
+	}
+
+	alerts, err := h.service.GetAlerts(middleware.UserID(c), acknowledged)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch alerts",
+		})
+	}
+
+	return c.JSON(alerts)
+}
+
+// Acknowledge marks an alert as seen
+func (h *AlertHandler) Acknowledge(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid alert ID",
+		})
+	}
+
+	alert, err := h.service.Acknowledge(middleware.UserID(c), uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Alert not found",
+		})
+	}
+
+	return c.JSON(alert)
+}
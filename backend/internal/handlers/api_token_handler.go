@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// APITokenHandler handles HTTP requests for the user's API tokens
+type APITokenHandler struct {
+	service services.APITokenService
+}
+
+// NewAPITokenHandler creates a new APITokenHandler
+func NewAPITokenHandler(service services.APITokenService) *APITokenHandler {
+	return &APITokenHandler{service: service}
+}
+
+// APITokenRequest represents the request body for creating an API token
+type APITokenRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Scope string `json:"scope"` // Default "quick_add"
+}
+
+// APITokenResponse is a new API token with its value, shown only once
+type APITokenResponse struct {
+	*models.APIToken
+	Token string `json:"token"`
+}
+
+// GetAll lists the user's API tokens, without their values
+func (h *APITokenHandler) GetAll(c *fiber.Ctx) error {
+	tokens, err := h.service.List(middleware.UserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch API tokens",
+		})
+	}
+
+	return c.JSON(tokens)
+}
+
+// Create issues an API token. Its value can't be retrieved again.
+func (h *APITokenHandler) Create(c *fiber.Ctx) error {
+	var req APITokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	token, value, err := h.service.Create(middleware.UserID(c), req.Name, req.Scope)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(APITokenResponse{APIToken: token, Token: value})
+}
+
+// Revoke stops an API token from working
+func (h *APITokenHandler) Revoke(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid API token ID",
+		})
+	}
+
+	if err := h.service.Revoke(middleware.UserID(c), uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "API token not found",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuthHandler handles HTTP requests for registration, login and tokens
+type AuthHandler struct {
+	service services.AuthService
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(service services.AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+// RegisterRequest represents the request body for creating an account
+type RegisterRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8"`
+	Name     string `json:"name" validate:"max=100"`
+}
+
+// Register creates a new account and returns its tokens
+func (h *AuthHandler) Register(c *fiber.Ctx) error {
+	var req RegisterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, tokens, err := h.service.Register(req.Email, req.Password, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEmailTaken):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, services.ErrInvalidRegistration):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		default:
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to register user",
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"user":   user,
+		"tokens": tokens,
+	})
+}
+
+// LoginRequest represents the request body for logging in
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+// Login validates credentials and returns a new token pair
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	var req LoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, tokens, err := h.service.Login(req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to log in",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"user":   user,
+		"tokens": tokens,
+	})
+}
+
+// RefreshRequest represents the request body for refreshing or revoking tokens
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// Refresh exchanges a refresh token for a new token pair (the old one is revoked)
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req RefreshRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	tokens, err := h.service.Refresh(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidToken) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to refresh token",
+		})
+	}
+
+	return c.JSON(tokens)
+}
+
+// Logout revokes a refresh token
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req RefreshRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if err := h.service.Logout(req.RefreshToken); err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid refresh token",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Me returns the authenticated user
+func (h *AuthHandler) Me(c *fiber.Ctx) error {
+	user, err := h.service.GetUser(middleware.UserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	return c.JSON(user)
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// BudgetHandler handles HTTP requests for category and subcategory budgets
+type BudgetHandler struct {
+	categoryRepo    repository.CategoryRepository
+	subcategoryRepo repository.SubcategoryRepository
+	service         services.BudgetService
+}
+
+// NewBudgetHandler creates a new BudgetHandler
+func NewBudgetHandler(
+	categoryRepo repository.CategoryRepository,
+	subcategoryRepo repository.SubcategoryRepository,
+	service services.BudgetService,
+) *BudgetHandler {
+	return &BudgetHandler{
+		categoryRepo:    categoryRepo,
+		subcategoryRepo: subcategoryRepo,
+		service:         service,
+	}
+}
+
+// SetBudgetRequest represents the request body for setting a budget
+type SetBudgetRequest struct {
+	Amount   *money.Amount `json:"amount" validate:"required,gte=0"`
+	Period   string        `json:"period"`   // "month" (default) o "year"
+	Currency string        `json:"currency"` // Vacía = la moneda por defecto
+}
+
+// budget converts the request into a models.Budget
+func (r *SetBudgetRequest) budget() models.Budget {
+	budget := models.Budget{BudgetAmount: r.Amount}
+	if r.Period != "" {
+		budget.BudgetPeriod = &r.Period
+	}
+	if r.Currency != "" {
+		budget.BudgetCurrency = &r.Currency
+	}
+	return budget
+}
+
+// SetCategoryBudget sets or replaces the budget of a category
+func (h *BudgetHandler) SetCategoryBudget(c *fiber.Ctx) error {
+	category, ok := h.findCategory(c)
+	if !ok {
+		return nil
+	}
+
+	var req SetBudgetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.Amount == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "amount is required",
+		})
+	}
+
+	if err := h.service.SetCategoryBudget(category, req.budget()); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(category)
+}
+
+// DeleteCategoryBudget removes the budget of a category
+func (h *BudgetHandler) DeleteCategoryBudget(c *fiber.Ctx) error {
+	category, ok := h.findCategory(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.service.SetCategoryBudget(category, models.Budget{}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete budget",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// SetSubcategoryBudget sets or replaces the budget of a subcategory
+func (h *BudgetHandler) SetSubcategoryBudget(c *fiber.Ctx) error {
+	subcategory, ok := h.findSubcategory(c)
+	if !ok {
+		return nil
+	}
+
+	var req SetBudgetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if req.Amount == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "amount is required",
+		})
+	}
+
+	if err := h.service.SetSubcategoryBudget(subcategory, req.budget()); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(subcategory)
+}
+
+// DeleteSubcategoryBudget removes the budget of a subcategory
+func (h *BudgetHandler) DeleteSubcategoryBudget(c *fiber.Ctx) error {
+	subcategory, ok := h.findSubcategory(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.service.SetSubcategoryBudget(subcategory, models.Budget{}); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete budget",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetStatus compares every budget with the pending one-time spend and the
+// normalized recurring spend of its category or subcategory
+func (h *BudgetHandler) GetStatus(c *fiber.Ctx) error {
+	statuses, err := h.service.GetStatus(middleware.UserID(c))
+	if err != nil {
+		return statsError(c, err)
+	}
+
+	overBudget := 0
+	for _, status := range statuses {
+		if status.OverBudget {
+			overBudget++
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"budgets":     statuses,
+		"over_budget": overBudget,
+	})
+}
+
+// findCategory loads the category in the :id param. When it can't, it writes
+// the error response and returns false.
+func (h *BudgetHandler) findCategory(c *fiber.Ctx) (*models.Category, bool) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid category ID",
+		})
+		return nil, false
+	}
+
+	category, err := h.categoryRepo.FindByID(middleware.UserID(c), uint(id))
+	if err != nil {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Category not found",
+		})
+		return nil, false
+	}
+	return category, true
+}
+
+// findSubcategory loads the subcategory in the :id param. When it can't, it
+// writes the error response and returns false.
+func (h *BudgetHandler) findSubcategory(c *fiber.Ctx) (*models.Subcategory, bool) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid subcategory ID",
+		})
+		return nil, false
+	}
+
+	subcategory, err := h.subcategoryRepo.FindByID(middleware.UserID(c), uint(id))
+	if err != nil {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Subcategory not found",
+		})
+		return nil, false
+	}
+	return subcategory, true
+}
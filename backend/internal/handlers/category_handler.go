@@ -3,6 +3,7 @@ package handlers
 import (
 	"strconv"
 
+	"github.com/buylist-manager/backend/internal/middleware"
 	"github.com/buylist-manager/backend/internal/models"
 	"github.com/buylist-manager/backend/internal/repository"
 	"github.com/gofiber/fiber/v2"
@@ -26,7 +27,7 @@ func NewCategoryHandler(repo repository.CategoryRepository) *CategoryHandler {
 // @Success 200 {array} models.Category
 // @Router /api/v1/categories [get]
 func (h *CategoryHandler) GetAll(c *fiber.Ctx) error {
-	categories, err := h.repo.FindAll()
+	categories, err := h.repo.FindAll(middleware.UserID(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch categories",
@@ -52,7 +53,7 @@ func (h *CategoryHandler) GetByID(c *fiber.Ctx) error {
 		})
 	}
 
-	category, err := h.repo.FindByID(uint(id))
+	category, err := h.repo.FindByID(middleware.UserID(c), uint(id))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Category not found",
@@ -93,8 +94,9 @@ func (h *CategoryHandler) Create(c *fiber.Ctx) error {
 	}
 
 	category := &models.Category{
-		Name: req.Name,
-		Type: req.Type,
+		UserID: middleware.UserID(c),
+		Name:   req.Name,
+		Type:   req.Type,
 	}
 
 	if err := h.repo.Create(category); err != nil {
@@ -131,7 +133,7 @@ func (h *CategoryHandler) Update(c *fiber.Ctx) error {
 	}
 
 	// Check if category exists
-	category, err := h.repo.FindByID(uint(id))
+	category, err := h.repo.FindByID(middleware.UserID(c), uint(id))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Category not found",
@@ -180,7 +182,7 @@ func (h *CategoryHandler) Delete(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.repo.Delete(uint(id)); err != nil {
+	if err := h.repo.Delete(middleware.UserID(c), uint(id)); err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Category not found",
 		})
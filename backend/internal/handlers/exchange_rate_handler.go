@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExchangeRateHandler handles HTTP requests for exchange rates
+type ExchangeRateHandler struct {
+	service services.ExchangeRateService
+}
+
+// NewExchangeRateHandler creates a new ExchangeRateHandler
+func NewExchangeRateHandler(service services.ExchangeRateService) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: service}
+}
+
+// GetAll lists the exchange rates, newest first.
+// Con ?currency=USD sólo devuelve las cotizaciones que involucran esa moneda.
+func (h *ExchangeRateHandler) GetAll(c *fiber.Ctx) error {
+	currency := c.Query("currency")
+	if currency != "" {
+		var err error
+		if currency, err = money.NormalizeCurrency(currency); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid currency parameter",
+			})
+		}
+	}
+
+	rates, err := h.service.List(middleware.UserID(c), currency)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch exchange rates",
+		})
+	}
+
+	return c.JSON(rates)
+}
+
+// SetExchangeRateRequest represents the request body for loading a rate
+type SetExchangeRateRequest struct {
+	FromCurrency string  `json:"from_currency" validate:"required,len=3"`
+	ToCurrency   string  `json:"to_currency" validate:"required,len=3"`
+	Rate         float64 `json:"rate" validate:"required,gt=0"` // 1 from_currency = rate to_currency
+	Date         string  `json:"date"`                          // YYYY-MM-DD; vacío = hoy
+}
+
+// Create loads a rate. A rate for the same pair and date is replaced.
+func (h *ExchangeRateHandler) Create(c *fiber.Ctx) error {
+	var req SetExchangeRateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	date, err := parseDateParam(req.Date, false)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid date",
+		})
+	}
+
+	rate := &models.ExchangeRate{
+		UserID:       middleware.UserID(c),
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+		Rate:         req.Rate,
+	}
+	if date != nil {
+		rate.Date = *date
+	} else {
+		rate.Date = time.Now()
+	}
+
+	if err := h.service.SetRate(rate); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rate)
+}
+
+// Delete deletes an exchange rate by ID
+func (h *ExchangeRateHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid exchange rate ID",
+		})
+	}
+
+	if err := h.service.Delete(middleware.UserID(c), uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Exchange rate not found",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Import loads rates from a CSV (date, from_currency, to_currency, rate), sent
+// as the "file" field of a multipart form or as the raw request body.
+// Si alguna fila es inválida no se guarda ninguna.
+func (h *ExchangeRateHandler) Import(c *fiber.Ctx) error {
+	file, err := uploadedFile(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportRates(middleware.UserID(c), file)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidImport) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to import exchange rates",
+		})
+	}
+
+	if len(result.Errors) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(result)
+	}
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportHandler handles HTTP requests for data exports
+type ExportHandler struct {
+	service services.ExportService
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(service services.ExportService) *ExportHandler {
+	return &ExportHandler{service: service}
+}
+
+// Export streams the products as a CSV or JSON download.
+// Acepta los mismos filtros y orden que GET /products; la paginación se ignora.
+func (h *ExportHandler) Export(c *fiber.Ctx) error {
+	format := c.Query("format", services.ExportFormatCSV)
+	contentType := ""
+	switch format {
+	case services.ExportFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case services.ExportFormatJSON:
+		contentType = fiber.MIMEApplicationJSONCharsetUTF8
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid format parameter (csv or json)",
+		})
+	}
+
+	query, err := parseProductQuery(c)
+	if err == nil {
+		err = query.Validate()
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// La primera tanda se carga acá: si la base falla, todavía se puede responder 500
+	userID := middleware.UserID(c)
+	write, err := h.service.ExportProducts(userID, format, query)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to export products",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Attachment(fmt.Sprintf("products-%s.%s", time.Now().Format("2006-01-02"), format))
+
+	// El body se escribe después de que el handler retorna, cuando el 200 ya salió.
+	// Si el export falla a mitad, el pipe devuelve el error y fasthttp corta la
+	// conexión sin el chunk final, así el cliente no toma el archivo como completo.
+	pr, pw := io.Pipe()
+	go func() {
+		bw := bufio.NewWriter(pw)
+		err := write(bw)
+		if err == nil {
+			err = bw.Flush()
+		}
+		if err != nil && !errors.Is(err, io.ErrClosedPipe) {
+			log.Printf("Export for user %d failed: %v", userID, err)
+		}
+		pw.CloseWithError(err)
+	}()
+	c.Context().SetBodyStream(pr, -1)
+	return nil
+}
+
+// Dump returns a full JSON copy of the user's data (categories, subcategories,
+// products, price history, alerts, exchange rates, price indexes, offers,
+// stores, tax profiles and webhooks) for archival
+func (h *ExportHandler) Dump(c *fiber.Ctx) error {
+	dump, err := h.service.Dump(middleware.UserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to export data",
+		})
+	}
+
+	c.Attachment(fmt.Sprintf("buylist-dump-%s.json", dump.ExportedAt.Format("2006-01-02")))
+	return c.JSON(dump)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImportHandler handles HTTP requests for data imports
+type ImportHandler struct {
+	service services.ImportService
+}
+
+// NewImportHandler creates a new ImportHandler
+func NewImportHandler(service services.ImportService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+// ImportProducts imports products from a CSV, sent as the "file" field of a
+// multipart form or as the raw request body (text/csv).
+// Con ?dry_run=true sólo valida y devuelve los errores por fila, sin escribir nada.
+func (h *ImportHandler) ImportProducts(c *fiber.Ctx) error {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid dry_run parameter",
+			})
+		}
+		dryRun = parsed
+	}
+
+	file, err := uploadedFile(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportProducts(middleware.UserID(c), file, dryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidImport) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to import products",
+		})
+	}
+
+	// Un import real con errores no escribe nada
+	if !dryRun && len(result.Errors) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(result)
+	}
+	if !dryRun {
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+	return c.JSON(result)
+}
+
+// uploadedFile returns the "file" field of a multipart form, or the raw request
+// body when the request isn't multipart
+func uploadedFile(c *fiber.Ctx) (io.ReadCloser, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return io.NopCloser(bytes.NewReader(c.Body())), nil
+	}
+
+	headers := form.File["file"]
+	if len(headers) == 0 {
+		return nil, errors.New("Missing file field")
+	}
+	f, err := headers[0].Open()
+	if err != nil {
+		return nil, errors.New("Invalid file upload")
+	}
+	return f, nil
+}
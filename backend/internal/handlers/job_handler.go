@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/buylist-manager/backend/internal/scheduler"
+	"github.com/gofiber/fiber/v2"
+)
+
+// JobHandler handles the admin requests for the background jobs
+type JobHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+// NewJobHandler creates a new JobHandler
+func NewJobHandler(scheduler *scheduler.Scheduler) *JobHandler {
+	return &JobHandler{scheduler: scheduler}
+}
+
+// UpdateJobRequest changes how a job is scheduled; omitted fields stay as they are
+type UpdateJobRequest struct {
+	Enabled  *bool   `json:"enabled"`
+	Schedule *string `json:"schedule"` // "0 6 * * *", "@hourly", "@every 30m"
+}
+
+// GetAll lists the jobs with their last run
+func (h *JobHandler) GetAll(c *fiber.Ctx) error {
+	jobs, err := h.scheduler.Status()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch jobs",
+		})
+	}
+	return c.JSON(jobs)
+}
+
+// Update enables, disables or reschedules a job
+func (h *JobHandler) Update(c *fiber.Ctx) error {
+	var req UpdateJobRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	job, err := h.scheduler.Configure(c.Params("name"), req.Enabled, req.Schedule)
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(job)
+}
+
+// Run starts a job now, without waiting for its schedule
+func (h *JobHandler) Run(c *fiber.Ctx) error {
+	if err := h.scheduler.RunNow(c.Params("name")); err != nil {
+		return jobError(c, err)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Job started",
+	})
+}
+
+// jobError reports an invalid schedule as 400, an unknown job as 404 and a
+// job already running as 409
+func jobError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, scheduler.ErrInvalidSchedule):
+		status = fiber.StatusBadRequest
+	case errors.Is(err, scheduler.ErrUnknownJob):
+		status = fiber.StatusNotFound
+	case errors.Is(err, scheduler.ErrJobRunning):
+		status = fiber.StatusConflict
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// OfferHandler handles HTTP requests for the offers of a product
+type OfferHandler struct {
+	productRepo repository.ProductRepository
+	offerRepo   repository.OfferRepository
+	service     services.OfferService
+}
+
+// NewOfferHandler creates a new OfferHandler
+func NewOfferHandler(
+	productRepo repository.ProductRepository,
+	offerRepo repository.OfferRepository,
+	service services.OfferService,
+) *OfferHandler {
+	return &OfferHandler{
+		productRepo: productRepo,
+		offerRepo:   offerRepo,
+		service:     service,
+	}
+}
+
+// OfferRequest represents the request body for adding or updating an offer
+type OfferRequest struct {
+	Store        string       `json:"store" validate:"required,max=100"`
+	URL          string       `json:"url" validate:"omitempty,url,max=500"`
+	BasePrice    money.Amount `json:"base_price" validate:"required,gte=0"`
+	ShippingCost money.Amount `json:"shipping_cost" validate:"gte=0"`
+	Taxes        money.Amount `json:"taxes" validate:"gte=0"`
+	Currency     string       `json:"currency"`    // ISO 4217; vacía = la moneda por defecto
+	ObservedAt   string       `json:"observed_at"` // YYYY-MM-DD o RFC3339; vacío = ahora
+}
+
+// applyTo copies the request into an offer
+func (r *OfferRequest) applyTo(offer *models.Offer) error {
+	observedAt, err := parseDateParam(r.ObservedAt, false)
+	if err != nil {
+		return errors.New("Invalid observed_at date")
+	}
+
+	offer.Store = r.Store
+	offer.URL = r.URL
+	offer.BasePrice = r.BasePrice
+	offer.ShippingCost = r.ShippingCost
+	offer.Taxes = r.Taxes
+	offer.Currency = r.Currency
+	offer.ObservedAt = time.Time{}
+	if observedAt != nil {
+		offer.ObservedAt = *observedAt
+	}
+	return nil
+}
+
+// Compare lists the offers of a product converted to its currency, cheapest first
+func (h *OfferHandler) Compare(c *fiber.Ctx) error {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return nil
+	}
+
+	comparison, err := h.service.Compare(product)
+	if err != nil {
+		return offerError(c, err)
+	}
+
+	return c.JSON(comparison)
+}
+
+// Create adds an offer to a product. The product's price becomes the one of
+// the chosen offer, or of the cheapest.
+func (h *OfferHandler) Create(c *fiber.Ctx) error {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return nil
+	}
+
+	var req OfferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	offer := &models.Offer{}
+	if err := req.applyTo(offer); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err := h.service.AddOffer(product, offer); err != nil {
+		return offerError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(offer)
+}
+
+// Update replaces the data of an offer
+func (h *OfferHandler) Update(c *fiber.Ctx) error {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return nil
+	}
+	offer, ok := h.findOffer(c, product)
+	if !ok {
+		return nil
+	}
+
+	var req OfferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if err := req.applyTo(offer); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err := h.service.UpdateOffer(product, offer); err != nil {
+		return offerError(c, err)
+	}
+
+	return c.JSON(offer)
+}
+
+// Delete removes an offer
+func (h *OfferHandler) Delete(c *fiber.Ctx) error {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return nil
+	}
+	offer, ok := h.findOffer(c, product)
+	if !ok {
+		return nil
+	}
+
+	if err := h.service.DeleteOffer(product, offer.ID); err != nil {
+		return offerError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ChooseOfferRequest represents the request body for choosing an offer
+type ChooseOfferRequest struct {
+	OfferID *uint `json:"offer_id"` // null = volver a la más barata
+}
+
+// Choose pins the product's price to one of its offers and returns the comparison
+func (h *OfferHandler) Choose(c *fiber.Ctx) error {
+	product, ok := h.findProduct(c)
+	if !ok {
+		return nil
+	}
+
+	var req ChooseOfferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.OfferID != nil {
+		if _, err := h.offerRepo.FindByID(product.UserID, product.ID, *req.OfferID); err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Offer not found",
+			})
+		}
+	}
+
+	if err := h.service.ChooseOffer(product, req.OfferID); err != nil {
+		return offerError(c, err)
+	}
+
+	comparison, err := h.service.Compare(product)
+	if err != nil {
+		return offerError(c, err)
+	}
+	return c.JSON(comparison)
+}
+
+// findProduct loads the product in the :id param. When it can't, it writes
+// the error response and returns false.
+func (h *OfferHandler) findProduct(c *fiber.Ctx) (*models.Product, bool) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid product ID",
+		})
+		return nil, false
+	}
+
+	product, err := h.productRepo.FindByID(middleware.UserID(c), uint(id))
+	if err != nil {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Product not found",
+		})
+		return nil, false
+	}
+	return product, true
+}
+
+// findOffer loads the product's offer in the :offer_id param. When it can't,
+// it writes the error response and returns false.
+func (h *OfferHandler) findOffer(c *fiber.Ctx, product *models.Product) (*models.Offer, bool) {
+	id, err := strconv.ParseUint(c.Params("offer_id"), 10, 32)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid offer ID",
+		})
+		return nil, false
+	}
+
+	offer, err := h.offerRepo.FindByID(product.UserID, product.ID, uint(id))
+	if err != nil {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Offer not found",
+		})
+		return nil, false
+	}
+	return offer, true
+}
+
+// offerError reports a purchased product as 409, a missing exchange rate as
+// 422 and validation errors as 400
+func offerError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadRequest
+	switch {
+	case errors.Is(err, services.ErrPurchasedPrice):
+		status = fiber.StatusConflict
+	case errors.Is(err, services.ErrNoExchangeRate):
+		status = fiber.StatusUnprocessableEntity
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// Límites de resultados para la búsqueda de productos
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Rango del gasto mensual en las estadísticas
+const (
+	defaultStatsMonths = 12
+	maxStatsMonths     = 120
+)
+
+// parseDateParam parses a query param as a date (2006-01-02) or RFC3339 timestamp.
+// An empty value returns nil. When endOfDay is true a plain date is moved to the
+// start of the next day, so it can be used as an exclusive upper bound.
+func parseDateParam(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// parseMonthParam parses a month (2006-01), or a date whose month is used. An
+// empty value returns nil. The result is the first day of the month in local
+// time, like the bounds of parseRangeParam.
+func parseMonthParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01", value, time.Local)
+	if err != nil {
+		date, err := parseDateParam(value, false)
+		if err != nil {
+			return nil, err
+		}
+		local := date.In(time.Local)
+		t = time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, time.Local)
+	}
+	return &t, nil
+}
+
+// parseRangeParam parses a bound of a date range: a month covers all of it
+// (to=2026-10 ends on November 1st), a date or timestamp works like parseDateParam
+func parseRangeParam(value string, end bool) (*time.Time, error) {
+	t, err := time.ParseInLocation("2006-01", value, time.Local)
+	if err != nil {
+		return parseDateParam(value, end)
+	}
+	if end {
+		t = t.AddDate(0, 1, 0)
+	}
+	return &t, nil
+}
+
+// parseAdjustParam parses ?adjust=. The only adjustment is "inflation"; an
+// empty value means prices as stored.
+func parseAdjustParam(value string) (bool, error) {
+	switch value {
+	case "":
+		return false, nil
+	case "inflation":
+		return true, nil
+	default:
+		return false, errors.New("Invalid adjust parameter")
+	}
+}
+
+// parseLimitParam parses a positive page size. An empty value returns def and
+// values above max are capped.
+func parseLimitParam(value string, def, max int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if limit < 1 {
+		return 0, errors.New("limit must be positive")
+	}
+	if limit > max {
+		limit = max
+	}
+	return limit, nil
+}
+
+// parseUintParam parses an optional ID param. An empty value returns nil.
+func parseUintParam(value string) (*uint, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, err
+	}
+	id := uint(n)
+	return &id, nil
+}
+
+// parseAmountParam parses an optional non-negative amount. An empty value returns nil.
+func parseAmountParam(value string) (*money.Amount, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := money.ParseInput(value)
+	if err != nil {
+		return nil, err
+	}
+	if amount.IsNegative() {
+		return nil, errors.New("value must not be negative")
+	}
+	return &amount, nil
+}
+
+// parseBoolParam parses an optional "true"/"false" param. An empty value returns nil.
+func parseBoolParam(value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// parseStatsRange parses the from/to dates or months of the monthly spend.
+// Without from the range starts defaultStatsMonths months ago; without to it
+// ends with the current month. The returned to is exclusive.
+func parseStatsRange(fromValue, toValue string, now time.Time) (time.Time, time.Time, error) {
+	to, err := parseRangeParam(toValue, true)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid to date")
+	}
+	if to == nil {
+		nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+		to = &nextMonth
+	}
+
+	from, err := parseRangeParam(fromValue, false)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("invalid from date")
+	}
+	if from == nil {
+		// Si to cae a mitad de mes, ese mes es el último de los 12
+		last := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, to.Location())
+		if last.Equal(*to) {
+			last = last.AddDate(0, -1, 0)
+		}
+		start := last.AddDate(0, 1-defaultStatsMonths, 0)
+		from = &start
+	}
+
+	if !from.Before(*to) {
+		return time.Time{}, time.Time{}, errors.New("from must be before to")
+	}
+	if to.After(from.AddDate(0, maxStatsMonths, 0)) {
+		return time.Time{}, time.Time{}, errors.New("date range cannot exceed 120 months")
+	}
+	return *from, *to, nil
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMonthParam(t *testing.T) {
+	// Argentina (UTC-3): cerca de medianoche UTC y local caen en meses distintos
+	local := time.Local
+	time.Local = time.FixedZone("ART", -3*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	october := time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2026-10", october},
+		{"2026-10-31", october},
+		{"2026-11-01T01:30:00Z", october}, // 31/10 22:30 en Argentina
+		{"2026-10-31T23:30:00-03:00", october},
+		{"2026-11-01T00:00:00-03:00", time.Date(2026, 11, 1, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseMonthParam(tt.value)
+		if err != nil {
+			t.Errorf("parseMonthParam(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseMonthParam(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	// Un mes es el mismo instante que el inicio del rango de las estadísticas
+	from, err := parseRangeParam("2026-10", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !from.Equal(october) {
+		t.Errorf("parseRangeParam(2026-10) = %v, want %v", from, october)
+	}
+
+	for _, value := range []string{"2026-13", "octubre", "2026/10"} {
+		if got, err := parseMonthParam(value); err == nil {
+			t.Errorf("parseMonthParam(%q) = %v, want an error", value, got)
+		}
+	}
+	if got, err := parseMonthParam(""); got != nil || err != nil {
+		t.Errorf("parseMonthParam(\"\") = %v, %v; want nil, nil", got, err)
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PriceIndexHandler handles HTTP requests for price indexes (CPI)
+type PriceIndexHandler struct {
+	service services.PriceIndexService
+}
+
+// NewPriceIndexHandler creates a new PriceIndexHandler
+func NewPriceIndexHandler(service services.PriceIndexService) *PriceIndexHandler {
+	return &PriceIndexHandler{service: service}
+}
+
+// GetAll lists the index values, newest month first.
+// Con ?currency=ARS sólo devuelve el índice de esa moneda.
+func (h *PriceIndexHandler) GetAll(c *fiber.Ctx) error {
+	currency := c.Query("currency")
+	if currency != "" {
+		var err error
+		if currency, err = money.NormalizeCurrency(currency); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid currency parameter",
+			})
+		}
+	}
+
+	indexes, err := h.service.List(middleware.UserID(c), currency)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch price indexes",
+		})
+	}
+
+	return c.JSON(indexes)
+}
+
+// SetPriceIndexRequest represents the request body for loading an index value
+type SetPriceIndexRequest struct {
+	Month    string  `json:"month" validate:"required"`      // YYYY-MM
+	Value    float64 `json:"value" validate:"required,gt=0"` // Valor del índice ese mes
+	Currency string  `json:"currency"`                       // Vacía = la moneda por defecto
+}
+
+// Create loads an index value. A value for the same currency and month is replaced.
+func (h *PriceIndexHandler) Create(c *fiber.Ctx) error {
+	var req SetPriceIndexRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	month, err := parseMonthParam(req.Month)
+	if err != nil || month == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid month",
+		})
+	}
+
+	index := &models.PriceIndex{
+		UserID:   middleware.UserID(c),
+		Currency: req.Currency,
+		Month:    *month,
+		Value:    req.Value,
+	}
+	if err := h.service.SetIndex(index); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(index)
+}
+
+// Delete deletes an index value by ID
+func (h *PriceIndexHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid price index ID",
+		})
+	}
+
+	if err := h.service.Delete(middleware.UserID(c), uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Price index not found",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Import loads index values from a CSV (month, value and optionally currency),
+// sent as the "file" field of a multipart form or as the raw request body.
+// Si alguna fila es inválida no se guarda ninguna.
+func (h *PriceIndexHandler) Import(c *fiber.Ctx) error {
+	file, err := uploadedFile(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportIndexes(middleware.UserID(c), file)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidImport) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to import price indexes",
+		})
+	}
+
+	if len(result.Errors) > 0 {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(result)
+	}
+	return c.Status(fiber.StatusCreated).JSON(result)
+}
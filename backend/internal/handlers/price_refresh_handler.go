@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/provider"
+	"github.com/buylist-manager/backend/internal/repository"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PriceRefreshHandler handles the price refreshes from the stores' APIs
+type PriceRefreshHandler struct {
+	productRepo repository.ProductRepository
+	service     services.PriceRefreshService
+}
+
+// NewPriceRefreshHandler creates a new PriceRefreshHandler
+func NewPriceRefreshHandler(productRepo repository.ProductRepository, service services.PriceRefreshService) *PriceRefreshHandler {
+	return &PriceRefreshHandler{productRepo: productRepo, service: service}
+}
+
+// Refresh updates a product's price with the one its store reports now
+func (h *PriceRefreshHandler) Refresh(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid product ID",
+		})
+	}
+
+	product, err := h.productRepo.FindByID(middleware.UserID(c), uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Product not found",
+		})
+	}
+
+	refresh, err := h.service.Refresh(c.UserContext(), product)
+	if err != nil {
+		return priceRefreshError(c, err)
+	}
+
+	return c.JSON(refresh)
+}
+
+// priceRefreshError reports a purchased product as 409, a URL no provider can
+// price as 422 and a failure talking to the store as 502
+func priceRefreshError(c *fiber.Ctx, err error) error {
+	status := fiber.StatusBadGateway
+	switch {
+	case errors.Is(err, services.ErrPurchasedPrice):
+		status = fiber.StatusConflict
+	case errors.Is(err, provider.ErrNoProvider), errors.Is(err, provider.ErrUnsupportedURL),
+		errors.Is(err, provider.ErrNotFound), errors.Is(err, services.ErrNoExchangeRate):
+		status = fiber.StatusUnprocessableEntity
+	}
+	return c.Status(status).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/buylist-manager/backend/internal/middleware"
 	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
 	"github.com/buylist-manager/backend/internal/repository"
 	"github.com/buylist-manager/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
@@ -24,72 +29,162 @@ func NewProductHandler(repo repository.ProductRepository, service services.Produ
 	}
 }
 
-// GetAll retrieves all products with optional filters
+// GetAll lists products. Filters combine (AND), results are sorted and paginated.
+// Query params: pending|purchased, category_id, subcategory_id, store_id, min_price, max_price,
+// recurrence_unit, subscription_status, currency, created_from/to, price_date_from/to, purchase_date_from/to,
+// sort, order, limit, offset, cursor. With adjust=inflation (and optionally to=2026-10)
+// each product also has its price restated in that month's money.
 func (h *ProductHandler) GetAll(c *fiber.Ctx) error {
-	// Filtros opcionales por query params
-	categoryIDStr := c.Query("category_id")
-	subcategoryIDStr := c.Query("subcategory_id")
-	pendingStr := c.Query("pending")
-
-	// Filtrar por pending (productos no comprados)
-	if pendingStr == "true" {
-		products, err := h.repo.FindPending()
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch products",
-			})
-		}
-		return c.JSON(products)
+	query, err := parseProductQuery(c)
+	if err == nil {
+		err = query.Validate()
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	adjustTo, err := parseInflationTarget(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
 	}
 
-	// Filtrar por categoría
-	if categoryIDStr != "" {
-		categoryID, err := strconv.ParseUint(categoryIDStr, 10, 32)
-		if err != nil {
+	page, err := h.repo.List(middleware.UserID(c), query)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid category_id parameter",
+				"error": "Invalid cursor parameter",
 			})
 		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch products",
+		})
+	}
 
-		products, err := h.repo.FindByCategoryID(uint(categoryID))
-		if err != nil {
+	if adjustTo != nil {
+		if err := h.service.AdjustForInflation(middleware.UserID(c), page.Items, *adjustTo); err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch products",
+				"error": "Failed to adjust prices",
 			})
 		}
-		return c.JSON(products)
 	}
 
-	// Filtrar por subcategoría
-	if subcategoryIDStr != "" {
-		subcategoryID, err := strconv.ParseUint(subcategoryIDStr, 10, 32)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid subcategory_id parameter",
-			})
+	return c.JSON(page)
+}
+
+// parseInflationTarget returns the month to restate prices to when the request
+// has adjust=inflation: ?to=2026-10, or the current month. Nil means no adjustment.
+func parseInflationTarget(c *fiber.Ctx) (*time.Time, error) {
+	adjust, err := parseAdjustParam(c.Query("adjust"))
+	if err != nil || !adjust {
+		return nil, err
+	}
+
+	to, err := parseMonthParam(c.Query("to"))
+	if err != nil {
+		return nil, errors.New("Invalid to parameter")
+	}
+	if to == nil {
+		now := models.MonthOf(time.Now())
+		to = &now
+	}
+	return to, nil
+}
+
+// parseProductQuery builds a ProductQuery from the request's query params
+func parseProductQuery(c *fiber.Ctx) (repository.ProductQuery, error) {
+	var q repository.ProductQuery
+	var err error
+
+	// pending=true equivale a purchased=false
+	pending, err := parseBoolParam(c.Query("pending"))
+	if err != nil {
+		return q, errors.New("Invalid pending parameter")
+	}
+	purchased, err := parseBoolParam(c.Query("purchased"))
+	if err != nil {
+		return q, errors.New("Invalid purchased parameter")
+	}
+	if pending != nil {
+		notPending := !*pending
+		if purchased != nil && *purchased != notPending {
+			return q, errors.New("pending and purchased contradict each other")
 		}
+		purchased = &notPending
+	}
+	q.Purchased = purchased
 
-		products, err := h.repo.FindBySubcategoryID(uint(subcategoryID))
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch products",
-			})
+	if q.CategoryID, err = parseUintParam(c.Query("category_id")); err != nil {
+		return q, errors.New("Invalid category_id parameter")
+	}
+	if q.SubcategoryID, err = parseUintParam(c.Query("subcategory_id")); err != nil {
+		return q, errors.New("Invalid subcategory_id parameter")
+	}
+	if q.StoreID, err = parseUintParam(c.Query("store_id")); err != nil {
+		return q, errors.New("Invalid store_id parameter")
+	}
+	if q.MinPrice, err = parseAmountParam(c.Query("min_price")); err != nil {
+		return q, errors.New("Invalid min_price parameter")
+	}
+	if q.MaxPrice, err = parseAmountParam(c.Query("max_price")); err != nil {
+		return q, errors.New("Invalid max_price parameter")
+	}
+	if unit := c.Query("recurrence_unit"); unit != "" {
+		q.RecurrenceUnit = &unit
+	}
+	if status := c.Query("subscription_status"); status != "" {
+		q.SubscriptionStatus = &status
+	}
+	if currency := c.Query("currency"); currency != "" {
+		if q.Currency, err = money.NormalizeCurrency(currency); err != nil {
+			return q, errors.New("Invalid currency parameter")
 		}
-		return c.JSON(products)
 	}
 
-	// Sin filtros, traer todos
-	products, err := h.repo.FindAll()
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch products",
-		})
+	dates := []struct {
+		param    string
+		endOfDay bool
+		target   **time.Time
+	}{
+		{"created_from", false, &q.CreatedFrom},
+		{"created_to", true, &q.CreatedTo},
+		{"price_date_from", false, &q.PriceDateFrom},
+		{"price_date_to", true, &q.PriceDateTo},
+		{"purchase_date_from", false, &q.PurchaseDateFrom},
+		{"purchase_date_to", true, &q.PurchaseDateTo},
+	}
+	for _, d := range dates {
+		if *d.target, err = parseDateParam(c.Query(d.param), d.endOfDay); err != nil {
+			return q, fmt.Errorf("Invalid %s parameter", d.param)
+		}
 	}
 
-	return c.JSON(products)
+	q.SortBy = c.Query("sort")
+	switch c.Query("order") {
+	case "", "desc":
+		q.SortDesc = true
+	case "asc":
+		q.SortDesc = false
+	default:
+		return q, errors.New("Invalid order parameter")
+	}
+
+	if q.Limit, err = parseLimitParam(c.Query("limit"), repository.DefaultPageLimit, repository.MaxPageLimit); err != nil {
+		return q, errors.New("Invalid limit parameter")
+	}
+	if offset := c.Query("offset"); offset != "" {
+		if q.Offset, err = strconv.Atoi(offset); err != nil || q.Offset < 0 {
+			return q, errors.New("Invalid offset parameter")
+		}
+	}
+	q.Cursor = c.Query("cursor")
+
+	return q, nil
 }
 
-// GetByID retrieves a single product by ID
+// GetByID retrieves a single product by ID (?adjust=inflation&to= like GetAll)
 func (h *ProductHandler) GetByID(c *fiber.Ctx) error {
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
@@ -97,29 +192,101 @@ func (h *ProductHandler) GetByID(c *fiber.Ctx) error {
 			"error": "Invalid product ID",
 		})
 	}
+	adjustTo, err := parseInflationTarget(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
 
-	product, err := h.repo.FindByID(uint(id))
+	product, err := h.repo.FindByID(middleware.UserID(c), uint(id))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Product not found",
 		})
 	}
 
+	if adjustTo != nil {
+		if err := h.service.AdjustForInflation(middleware.UserID(c), []*models.Product{product}, *adjustTo); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to adjust prices",
+			})
+		}
+	}
+
 	return c.JSON(product)
 }
 
+// GetStalePrices lists the pending products whose price is outdated: inflation
+// since their price date reached the configured threshold. Most outdated first.
+func (h *ProductHandler) GetStalePrices(c *fiber.Ctx) error {
+	products, err := h.service.FindStalePrices(middleware.UserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch stale prices",
+		})
+	}
+
+	return c.JSON(products)
+}
+
+// Search finds products by name, description or notes (accent-insensitive, typo-tolerant)
+func (h *ProductHandler) Search(c *fiber.Ctx) error {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Query parameter q is required",
+		})
+	}
+
+	limit, err := parseLimitParam(c.Query("limit"), defaultSearchLimit, maxSearchLimit)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid limit parameter",
+		})
+	}
+
+	products, err := h.repo.Search(middleware.UserID(c), query, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to search products",
+		})
+	}
+
+	return c.JSON(products)
+}
+
 // CreateProductRequest represents the request body for creating a product
 type CreateProductRequest struct {
-	Name           string  `json:"name" validate:"required,min=1,max=255"`
-	Description    string  `json:"description"`
-	BasePrice      float64 `json:"base_price" validate:"required,min=0"`
-	ShippingCost   float64 `json:"shipping_cost" validate:"min=0"`
-	Taxes          float64 `json:"taxes" validate:"min=0"`
-	SourceURL      string  `json:"source_url"`
-	CategoryID     uint    `json:"category_id" validate:"required"`
-	SubcategoryID  uint    `json:"subcategory_id" validate:"required"`
-	RecurrenceType *string `json:"recurrence_type"` // "monthly" o "yearly" o null
-	Notes          string  `json:"notes"`
+	Name               string       `json:"name" validate:"required,min=1,max=255"`
+	Description        string       `json:"description"`
+	BasePrice          money.Amount `json:"base_price" validate:"required,min=0"`
+	ShippingCost       money.Amount `json:"shipping_cost" validate:"min=0"`
+	Taxes              money.Amount `json:"taxes" validate:"min=0"`
+	Currency           string       `json:"currency"` // Código ISO 4217; vacío = la moneda por defecto
+	SourceURL          string       `json:"source_url"`
+	ImageURL           string       `json:"image_url"`
+	CategoryID         uint         `json:"category_id" validate:"required"`
+	SubcategoryID      uint         `json:"subcategory_id" validate:"required"`
+	RecurrenceInterval *int         `json:"recurrence_interval"` // Cada cuántas unidades (default 1)
+	RecurrenceUnit     *string      `json:"recurrence_unit"`     // "day", "week", "month", "year" o null
+	Notes              string       `json:"notes"`
+
+	// Sólo para suscripciones (recurrence_unit no null)
+	SubscriptionStatus *string `json:"subscription_status"` // "trial", "active" (default), "paused", "cancelled"
+	StartDate          string  `json:"start_date"`          // YYYY-MM-DD, default hoy
+	TrialEndsAt        string  `json:"trial_ends_at"`       // Obligatoria si el estado es "trial"
+
+	// Plan de cuotas, sólo para compras únicas
+	InstallmentCount     *int          `json:"installment_count"`
+	InstallmentRate      *float64      `json:"installment_rate"`       // TNA en %; sin rate ni total = sin interés
+	InstallmentTotal     *money.Amount `json:"installment_total"`      // Total financiado, en lugar de la tasa
+	FirstInstallmentDate string        `json:"first_installment_date"` // YYYY-MM-DD, default un mes después de la compra
+
+	TargetPrice       *money.Amount `json:"target_price"`        // Alerta cuando el total llega a este precio
+	TargetDropPercent *float64      `json:"target_drop_percent"` // Alerta cuando el precio baja este %
+
+	TaxProfileID *uint `json:"tax_profile_id"` // Con perfil (propio o de la tienda) taxes se calcula
 }
 
 // Create creates a new product
@@ -133,43 +300,139 @@ func (h *ProductHandler) Create(c *fiber.Ctx) error {
 
 	now := time.Now()
 	product := &models.Product{
-		Name:           req.Name,
-		Description:    req.Description,
-		BasePrice:      req.BasePrice,
-		ShippingCost:   req.ShippingCost,
-		Taxes:          req.Taxes,
-		SourceURL:      req.SourceURL,
-		PriceDate:      &now,
-		CategoryID:     req.CategoryID,
-		SubcategoryID:  req.SubcategoryID,
-		RecurrenceType: req.RecurrenceType,
-		Notes:          req.Notes,
-		IsPurchased:    false,
+		UserID:             middleware.UserID(c),
+		Name:               req.Name,
+		Description:        req.Description,
+		BasePrice:          req.BasePrice,
+		ShippingCost:       req.ShippingCost,
+		Taxes:              req.Taxes,
+		Currency:           req.Currency,
+		SourceURL:          req.SourceURL,
+		ImageURL:           req.ImageURL,
+		PriceDate:          &now,
+		CategoryID:         req.CategoryID,
+		SubcategoryID:      req.SubcategoryID,
+		RecurrenceInterval: req.RecurrenceInterval,
+		RecurrenceUnit:     req.RecurrenceUnit,
+		Notes:              req.Notes,
+		IsPurchased:        false,
+
+		TargetPrice:       req.TargetPrice,
+		TargetDropPercent: req.TargetDropPercent,
+
+		SubscriptionStatus: req.SubscriptionStatus,
+		TaxProfileID:       req.TaxProfileID,
 	}
-
-	// Usar el Service que tiene las validaciones de negocio
-	if err := h.service.CreateProduct(product); err != nil {
+	if err := setSubscriptionDates(product, req.StartDate, req.TrialEndsAt); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err := setInstallmentPlan(product, req.InstallmentCount, req.InstallmentRate, req.InstallmentTotal, req.FirstInstallmentDate); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
+	// Usar el Service que tiene las validaciones de negocio
+	if err := h.service.CreateProduct(product); err != nil {
+		return productWriteError(c, err, "Failed to create product")
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(product)
 }
 
 // UpdateProductRequest represents the request body for updating a product
 type UpdateProductRequest struct {
-	Name           string  `json:"name" validate:"required,min=1,max=255"`
-	Description    string  `json:"description"`
-	BasePrice      float64 `json:"base_price" validate:"required,min=0"`
-	ShippingCost   float64 `json:"shipping_cost" validate:"min=0"`
-	Taxes          float64 `json:"taxes" validate:"min=0"`
-	SourceURL      string  `json:"source_url"`
-	CategoryID     uint    `json:"category_id" validate:"required"`
-	SubcategoryID  uint    `json:"subcategory_id" validate:"required"`
-	RecurrenceType *string `json:"recurrence_type"`
-	IsPurchased    bool    `json:"is_purchased"`
-	Notes          string  `json:"notes"`
+	Name               string       `json:"name" validate:"required,min=1,max=255"`
+	Description        string       `json:"description"`
+	BasePrice          money.Amount `json:"base_price" validate:"required,min=0"`
+	ShippingCost       money.Amount `json:"shipping_cost" validate:"min=0"`
+	Taxes              money.Amount `json:"taxes" validate:"min=0"`
+	Currency           string       `json:"currency"` // Vacío = se mantiene la actual
+	SourceURL          string       `json:"source_url"`
+	ImageURL           string       `json:"image_url"`
+	CategoryID         uint         `json:"category_id" validate:"required"`
+	SubcategoryID      uint         `json:"subcategory_id" validate:"required"`
+	RecurrenceInterval *int         `json:"recurrence_interval"`
+	RecurrenceUnit     *string      `json:"recurrence_unit"`
+	IsPurchased        bool         `json:"is_purchased"`
+	Notes              string       `json:"notes"`
+
+	// Vacíos = se mantienen los actuales (se borran si deja de ser suscripción)
+	SubscriptionStatus *string `json:"subscription_status"`
+	StartDate          string  `json:"start_date"`
+	TrialEndsAt        string  `json:"trial_ends_at"`
+
+	// Sin installment_count se mantiene el plan actual; 0 lo quita
+	InstallmentCount     *int          `json:"installment_count"`
+	InstallmentRate      *float64      `json:"installment_rate"`
+	InstallmentTotal     *money.Amount `json:"installment_total"`
+	FirstInstallmentDate string        `json:"first_installment_date"`
+
+	TargetPrice       *money.Amount `json:"target_price"`
+	TargetDropPercent *float64      `json:"target_drop_percent"`
+
+	TaxProfileID *uint `json:"tax_profile_id"`
+}
+
+// setSubscriptionDates parses the subscription dates of a request (YYYY-MM-DD
+// or RFC3339). Empty values leave the product's dates as they are.
+func setSubscriptionDates(product *models.Product, startDate, trialEndsAt string) error {
+	start, err := parseDateParam(startDate, false)
+	if err != nil {
+		return errors.New("Invalid start_date")
+	}
+	trialEnd, err := parseDateParam(trialEndsAt, false)
+	if err != nil {
+		return errors.New("Invalid trial_ends_at")
+	}
+
+	if start != nil {
+		product.StartDate = start
+	}
+	if trialEnd != nil {
+		product.TrialEndsAt = trialEnd
+	}
+	return nil
+}
+
+// setInstallmentPlan applies the installment fields of a request. Without a
+// count the current plan is kept and a count of 0 removes it. A new count
+// replaces the whole plan; with neither rate nor total it is interest-free.
+func setInstallmentPlan(product *models.Product, count *int, rate *float64, total *money.Amount, firstDate string) error {
+	if count == nil {
+		if rate != nil || total != nil || firstDate != "" {
+			return errors.New("installment fields require installment_count")
+		}
+		return nil
+	}
+	if *count == 0 {
+		product.InstallmentCount, product.InstallmentRate, product.InstallmentTotal = nil, nil, nil
+		product.FirstInstallmentDate = nil
+		return nil
+	}
+
+	if rate != nil && total != nil {
+		return errors.New("send either installment_rate or installment_total, not both")
+	}
+	if rate == nil && total == nil {
+		interestFree := 0.0
+		rate = &interestFree
+	}
+
+	first, err := parseDateParam(firstDate, false)
+	if err != nil {
+		return errors.New("Invalid first_installment_date")
+	}
+
+	product.InstallmentCount = count
+	product.InstallmentRate = rate
+	product.InstallmentTotal = total
+	if first != nil {
+		product.FirstInstallmentDate = first
+	}
+	return nil
 }
 
 // Update updates an existing product
@@ -182,7 +445,7 @@ func (h *ProductHandler) Update(c *fiber.Ctx) error {
 	}
 
 	// Check if product exists
-	product, err := h.repo.FindByID(uint(id))
+	product, err := h.repo.FindByID(middleware.UserID(c), uint(id))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Product not found",
@@ -202,26 +465,127 @@ func (h *ProductHandler) Update(c *fiber.Ctx) error {
 	product.BasePrice = req.BasePrice
 	product.ShippingCost = req.ShippingCost
 	product.Taxes = req.Taxes
+	if req.Currency != "" {
+		product.Currency = req.Currency
+	}
 	product.SourceURL = req.SourceURL
+	product.ImageURL = req.ImageURL
 	product.CategoryID = req.CategoryID
 	product.SubcategoryID = req.SubcategoryID
-	product.RecurrenceType = req.RecurrenceType
+	product.RecurrenceInterval = req.RecurrenceInterval
+	product.RecurrenceUnit = req.RecurrenceUnit
 	product.IsPurchased = req.IsPurchased
 	product.Notes = req.Notes
+	product.TargetPrice = req.TargetPrice
+	product.TargetDropPercent = req.TargetDropPercent
+	product.TaxProfileID = req.TaxProfileID
+	if req.RecurrenceUnit == nil {
+		product.SubscriptionStatus, product.StartDate, product.TrialEndsAt = nil, nil, nil
+	} else if req.SubscriptionStatus != nil {
+		product.SubscriptionStatus = req.SubscriptionStatus
+	}
+	if err := setSubscriptionDates(product, req.StartDate, req.TrialEndsAt); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err := setInstallmentPlan(product, req.InstallmentCount, req.InstallmentRate, req.InstallmentTotal, req.FirstInstallmentDate); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// El Service valida y guarda; los subscribers de sus eventos fechan la compra
+	// y guardan el precio anterior en el histórico
+	if err := h.service.UpdateProduct(product); err != nil {
+		return productWriteError(c, err, "Failed to update product")
+	}
+
+	// Recargar para devolver category/subcategory actualizadas
+	if updated, err := h.repo.FindByID(product.UserID, product.ID); err == nil {
+		product = updated
+	}
+
+	return c.JSON(product)
+}
 
-	// Si se marca como comprado, guardar la fecha
-	if req.IsPurchased && product.PurchaseDate == nil {
-		now := time.Now()
-		product.PurchaseDate = &now
+// GetInstallments returns the installment schedule of a product
+// Para un producto pendiente sin primera fecha, el cronograma es una vista previa
+func (h *ProductHandler) GetInstallments(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid product ID",
+		})
 	}
 
-	if err := h.repo.Update(product); err != nil {
+	product, err := h.repo.FindByID(middleware.UserID(c), uint(id))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Product not found",
+		})
+	}
+	if !product.HasInstallments() {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Product has no installment plan",
+		})
+	}
+
+	// Sin fecha todavía (no se compró): se muestra como si se comprara hoy
+	if product.FirstInstallmentDate == nil {
+		first := models.FirstInstallmentDue(time.Now())
+		product.FirstInstallmentDate = &first
+	}
+
+	return c.JSON(fiber.Map{
+		"installment_count": product.InstallmentCount,
+		"installment_rate":  product.InstallmentRate,
+		"installment_total": product.InstallmentTotal,
+		"currency":          product.Currency,
+		"installments":      product.Installments(),
+	})
+}
+
+// GetPriceHistory returns the previous prices of a product
+// Optional filters: ?from=2026-01-01&to=2026-02-01 (dates or RFC3339 timestamps)
+func (h *ProductHandler) GetPriceHistory(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid product ID",
+		})
+	}
+
+	userID := middleware.UserID(c)
+	if _, err := h.repo.FindByID(userID, uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Product not found",
+		})
+	}
+
+	from, err := parseDateParam(c.Query("from"), false)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid from parameter",
+		})
+	}
+
+	to, err := parseDateParam(c.Query("to"), true)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid to parameter",
+		})
+	}
+
+	history, err := h.service.GetPriceHistory(userID, uint(id), from, to)
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update product",
+			"error": "Failed to fetch price history",
 		})
 	}
 
-	return c.JSON(product)
+	return c.JSON(history)
 }
 
 // Delete deletes a product by ID
@@ -233,7 +597,7 @@ func (h *ProductHandler) Delete(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.repo.Delete(uint(id)); err != nil {
+	if err := h.service.DeleteProduct(middleware.UserID(c), uint(id)); err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Product not found",
 		})
@@ -242,32 +606,76 @@ func (h *ProductHandler) Delete(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
-// GetStats returns statistics about products (totals, monthly cost, etc.)
+// GetStats returns statistics about products: totals, recurring cost, counts
+// and average price, overall and per category and subcategory, plus the
+// purchased spend per month.
+// Los totales se convierten a ?currency= (default: la moneda configurada) y el
+// gasto mensual cubre ?from= / ?to= (default: los últimos 12 meses). Con
+// ?adjust=inflation los precios se llevan al dinero del último mes del rango.
 func (h *ProductHandler) GetStats(c *fiber.Ctx) error {
-	totalPending, err := h.service.GetTotalPendingCost()
+	currency, err := h.service.ResolveCurrency(c.Query("currency"))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to calculate statistics",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid currency parameter",
 		})
 	}
 
-	monthlyCost, err := h.service.GetMonthlyRecurringCost()
+	from, to, err := parseStatsRange(c.Query("from"), c.Query("to"), time.Now())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to calculate statistics",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
 
-	yearlyCost, err := h.service.GetYearlyRecurringCost()
+	opts := services.StatsOptions{Currency: currency, From: from, To: to}
+	adjust, err := parseAdjustParam(c.Query("adjust"))
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to calculate statistics",
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
 		})
 	}
+	if adjust {
+		month := models.MonthOf(to.AddDate(0, 0, -1))
+		opts.AdjustTo = &month
+	}
 
-	return c.JSON(fiber.Map{
-		"total_pending_one_time": totalPending,
-		"monthly_recurring_cost": monthlyCost,
-		"yearly_recurring_cost":  yearlyCost,
+	stats, err := h.service.GetStats(middleware.UserID(c), opts)
+	if err != nil {
+		return statsError(c, err)
+	}
+
+	return c.JSON(stats)
+}
+
+// productWriteError reports a product that breaks a business rule as 400, a
+// missing exchange rate or price index as 422 and anything else (the database,
+// the transaction) as 500
+func productWriteError(c *fiber.Ctx, err error, message string) error {
+	var invalid *services.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrNoExchangeRate), errors.Is(err, services.ErrNoPriceIndex):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
+
+// statsError reports a missing exchange rate or price index as 422, so the user
+// knows which one to load
+func statsError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrNoExchangeRate) || errors.Is(err, services.ErrNoPriceIndex) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": "Failed to calculate statistics",
 	})
 }
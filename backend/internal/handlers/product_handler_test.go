@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestProductWriteError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{&services.ValidationError{Err: errors.New("category not found")}, fiber.StatusBadRequest},
+		{fmt.Errorf("%w from USD to ARS", services.ErrNoExchangeRate), fiber.StatusUnprocessableEntity},
+		{fmt.Errorf("%w for ARS", services.ErrNoPriceIndex), fiber.StatusUnprocessableEntity},
+		{errors.New("database is locked"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		app := fiber.New()
+		app.Put("/", func(c *fiber.Ctx) error {
+			return productWriteError(c, tt.err, "Failed to update product")
+		})
+		resp, err := app.Test(httptest.NewRequest("PUT", "/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("productWriteError(%v) = %d, want %d", tt.err, resp.StatusCode, tt.want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// QuickAddHandler handles the products added from a web page (bookmarklet)
+type QuickAddHandler struct {
+	service services.QuickAddService
+}
+
+// NewQuickAddHandler creates a new QuickAddHandler
+func NewQuickAddHandler(service services.QuickAddService) *QuickAddHandler {
+	return &QuickAddHandler{service: service}
+}
+
+// QuickAddRequest is what the bookmarklet posts: the page it's on and its HTML
+type QuickAddRequest struct {
+	URL           string `json:"url"`
+	HTML          string `json:"html"`
+	Create        bool   `json:"create"`         // true = se guarda sin confirmar
+	SubcategoryID uint   `json:"subcategory_id"` // En lugar de la sugerida
+}
+
+// QuickAdd extracts a product draft from the posted page. With create it's
+// saved right away (201); otherwise the draft is returned for confirmation.
+func (h *QuickAddHandler) QuickAdd(c *fiber.Ctx) error {
+	var req QuickAddRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	draft, err := h.service.Draft(middleware.UserID(c), req.URL, req.HTML)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if !req.Create {
+		return c.JSON(draft)
+	}
+
+	if err := h.service.Create(draft, req.SubcategoryID); err != nil {
+		// Lo que falta se completa a mano: se devuelve el borrador para confirmarlo
+		if errors.Is(err, services.ErrIncompleteDraft) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": err.Error(),
+				"draft": draft,
+			})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(draft)
+}
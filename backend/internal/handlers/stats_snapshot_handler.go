@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StatsSnapshotHandler handles HTTP requests for the daily stats snapshots
+type StatsSnapshotHandler struct {
+	service services.StatsSnapshotService
+}
+
+// NewStatsSnapshotHandler creates a new StatsSnapshotHandler
+func NewStatsSnapshotHandler(service services.StatsSnapshotService) *StatsSnapshotHandler {
+	return &StatsSnapshotHandler{service: service}
+}
+
+// GetAll lists the user's snapshots, oldest first.
+// ?from= / ?to= aceptan fechas o meses (to=2026-10 incluye todo octubre).
+func (h *StatsSnapshotHandler) GetAll(c *fiber.Ctx) error {
+	from, err := parseRangeParam(c.Query("from"), false)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid from parameter",
+		})
+	}
+
+	to, err := parseRangeParam(c.Query("to"), true)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid to parameter",
+		})
+	}
+
+	snapshots, err := h.service.List(middleware.UserID(c), from, to)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch stats history",
+		})
+	}
+
+	return c.JSON(snapshots)
+}
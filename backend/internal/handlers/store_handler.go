@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// StoreHandler handles HTTP requests for stores
+type StoreHandler struct {
+	repo    repository.StoreRepository
+	service services.StoreService
+}
+
+// NewStoreHandler creates a new StoreHandler
+func NewStoreHandler(repo repository.StoreRepository, service services.StoreService) *StoreHandler {
+	return &StoreHandler{repo: repo, service: service}
+}
+
+// GetAll lists the stores sorted by name
+func (h *StoreHandler) GetAll(c *fiber.Ctx) error {
+	stores, err := h.service.List(middleware.UserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch stores",
+		})
+	}
+
+	return c.JSON(stores)
+}
+
+// GetByID retrieves a single store by ID
+func (h *StoreHandler) GetByID(c *fiber.Ctx) error {
+	store, ok := h.findStore(c)
+	if !ok {
+		return nil
+	}
+
+	return c.JSON(store)
+}
+
+// StoreRequest represents the request body for creating or updating a store
+type StoreRequest struct {
+	Name                  string        `json:"name" validate:"max=100"`    // Vacío = el dominio
+	Domain                string        `json:"domain" validate:"required"` // "mercadolibre.com.ar" o una URL de la tienda
+	DefaultShippingCost   money.Amount  `json:"default_shipping_cost" validate:"gte=0"`
+	FreeShippingThreshold *money.Amount `json:"free_shipping_threshold"` // null = no tiene envío gratis
+	Currency              string        `json:"currency"`                // Vacía = la moneda por defecto
+	TaxProfileID          *uint         `json:"tax_profile_id"`          // Impuestos de sus productos sin perfil propio
+}
+
+// applyTo copies the request into a store
+func (r *StoreRequest) applyTo(store *models.Store) {
+	store.Name = r.Name
+	store.Domain = r.Domain
+	store.DefaultShippingCost = r.DefaultShippingCost
+	store.FreeShippingThreshold = r.FreeShippingThreshold
+	store.Currency = r.Currency
+	store.TaxProfileID = r.TaxProfileID
+}
+
+// Create creates a store and links to it the products whose source URL is
+// from its domain
+func (h *StoreHandler) Create(c *fiber.Ctx) error {
+	var req StoreRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	store := &models.Store{UserID: middleware.UserID(c)}
+	req.applyTo(store)
+	if err := h.service.CreateStore(store); err != nil {
+		return storeError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(store)
+}
+
+// Update replaces the data of a store. Changing the domain links the products again.
+func (h *StoreHandler) Update(c *fiber.Ctx) error {
+	store, ok := h.findStore(c)
+	if !ok {
+		return nil
+	}
+
+	var req StoreRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	req.applyTo(store)
+	if err := h.service.UpdateStore(store); err != nil {
+		return storeError(c, err)
+	}
+
+	return c.JSON(store)
+}
+
+// Delete deletes a store by ID. Its products are unlinked.
+func (h *StoreHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid store ID",
+		})
+	}
+
+	if err := h.service.DeleteStore(middleware.UserID(c), uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Store not found",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetStats returns, per store, the pending and purchased one-time products and
+// how much is missing to reach its free-shipping threshold if they are bought
+// together. Los montos se convierten a ?currency= (default: la moneda configurada).
+func (h *StoreHandler) GetStats(c *fiber.Ctx) error {
+	currency := c.Query("currency")
+	if currency != "" {
+		var err error
+		if currency, err = money.NormalizeCurrency(currency); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid currency parameter",
+			})
+		}
+	}
+
+	stats, err := h.service.GetStats(middleware.UserID(c), currency)
+	if err != nil {
+		return statsError(c, err)
+	}
+
+	return c.JSON(stats)
+}
+
+// findStore loads the store in the :id param. When it can't, it writes the
+// error response and returns false.
+func (h *StoreHandler) findStore(c *fiber.Ctx) (*models.Store, bool) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid store ID",
+		})
+		return nil, false
+	}
+
+	store, err := h.repo.FindByID(middleware.UserID(c), uint(id))
+	if err != nil {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Store not found",
+		})
+		return nil, false
+	}
+	return store, true
+}
+
+// storeError reports a domain already taken as 409 and validation errors as 400
+func storeError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrDuplicateStore) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
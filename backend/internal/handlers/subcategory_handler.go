@@ -3,6 +3,7 @@ package handlers
 import (
 	"strconv"
 
+	"github.com/buylist-manager/backend/internal/middleware"
 	"github.com/buylist-manager/backend/internal/models"
 	"github.com/buylist-manager/backend/internal/repository"
 	"github.com/gofiber/fiber/v2"
@@ -35,7 +36,7 @@ func (h *SubcategoryHandler) GetAll(c *fiber.Ctx) error {
 			})
 		}
 		
-		subcategories, err := h.repo.FindByCategoryID(uint(categoryID))
+		subcategories, err := h.repo.FindByCategoryID(middleware.UserID(c), uint(categoryID))
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to fetch subcategories",
@@ -45,7 +46,7 @@ func (h *SubcategoryHandler) GetAll(c *fiber.Ctx) error {
 	}
 	
 	// Sin filtro, traer todas
-	subcategories, err := h.repo.FindAll()
+	subcategories, err := h.repo.FindAll(middleware.UserID(c))
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch subcategories",
@@ -64,7 +65,7 @@ func (h *SubcategoryHandler) GetByID(c *fiber.Ctx) error {
 		})
 	}
 
-	subcategory, err := h.repo.FindByID(uint(id))
+	subcategory, err := h.repo.FindByID(middleware.UserID(c), uint(id))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Subcategory not found",
@@ -90,7 +91,7 @@ func (h *SubcategoryHandler) Create(c *fiber.Ctx) error {
 	}
 
 	// Validar que la categoría existe (como en Laravel con exists:categories,id)
-	_, err := h.categoryRepo.FindByID(req.CategoryID)
+	_, err := h.categoryRepo.FindByID(middleware.UserID(c), req.CategoryID)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Category not found",
@@ -98,6 +99,7 @@ func (h *SubcategoryHandler) Create(c *fiber.Ctx) error {
 	}
 
 	subcategory := &models.Subcategory{
+		UserID:     middleware.UserID(c),
 		CategoryID: req.CategoryID,
 		Name:       req.Name,
 	}
@@ -127,7 +129,7 @@ func (h *SubcategoryHandler) Update(c *fiber.Ctx) error {
 	}
 
 	// Check if subcategory exists
-	subcategory, err := h.repo.FindByID(uint(id))
+	subcategory, err := h.repo.FindByID(middleware.UserID(c), uint(id))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Subcategory not found",
@@ -142,16 +144,25 @@ func (h *SubcategoryHandler) Update(c *fiber.Ctx) error {
 	}
 
 	// Validar que la nueva categoría existe
-	_, err = h.categoryRepo.FindByID(req.CategoryID)
+	category, err := h.categoryRepo.FindByID(middleware.UserID(c), req.CategoryID)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Category not found",
 		})
 	}
 
+	// Los productos se mueven con la subcategoría: una compra única no puede
+	// quedar en una categoría recurrente ni al revés
+	if subcategory.Category != nil && category.Type != subcategory.Category.Type {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot move a subcategory to a category of another type",
+		})
+	}
+
 	// Update fields
 	subcategory.CategoryID = req.CategoryID
 	subcategory.Name = req.Name
+	subcategory.Category = nil // La precargada es la categoría anterior
 
 	if err := h.repo.Update(subcategory); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -171,7 +182,7 @@ func (h *SubcategoryHandler) Delete(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := h.repo.Delete(uint(id)); err != nil {
+	if err := h.repo.Delete(middleware.UserID(c), uint(id)); err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Subcategory not found",
 		})
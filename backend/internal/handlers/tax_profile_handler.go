@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TaxProfileHandler handles HTTP requests for tax profiles
+type TaxProfileHandler struct {
+	repo    repository.TaxProfileRepository
+	service services.TaxProfileService
+}
+
+// NewTaxProfileHandler creates a new TaxProfileHandler
+func NewTaxProfileHandler(repo repository.TaxProfileRepository, service services.TaxProfileService) *TaxProfileHandler {
+	return &TaxProfileHandler{repo: repo, service: service}
+}
+
+// GetAll lists the tax profiles with their rules, sorted by name
+func (h *TaxProfileHandler) GetAll(c *fiber.Ctx) error {
+	profiles, err := h.service.List(middleware.UserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch tax profiles",
+		})
+	}
+
+	return c.JSON(profiles)
+}
+
+// GetByID retrieves a single tax profile by ID
+func (h *TaxProfileHandler) GetByID(c *fiber.Ctx) error {
+	profile, ok := h.findProfile(c)
+	if !ok {
+		return nil
+	}
+
+	return c.JSON(profile)
+}
+
+// TaxRuleRequest is one rule of a tax profile request
+type TaxRuleRequest struct {
+	Name      string        `json:"name" validate:"required,max=100"`
+	Kind      string        `json:"kind" validate:"required,oneof=percentage fixed"`
+	Rate      float64       `json:"rate"`      // En %, para "percentage"
+	Amount    money.Amount  `json:"amount"`    // Para "fixed"
+	Base      string        `json:"base"`      // "price" (default), "price_shipping" o "accumulated"
+	Franchise *money.Amount `json:"franchise"` // Monto exento de la base
+	Currency  string        `json:"currency"`  // La de amount y franchise; vacía = la del producto
+}
+
+// TaxProfileRequest represents the request body for creating or updating a
+// tax profile. The rules are applied in the order they are sent.
+type TaxProfileRequest struct {
+	Name  string           `json:"name" validate:"required,max=100"`
+	Rules []TaxRuleRequest `json:"rules" validate:"required,min=1"`
+}
+
+// applyTo copies the request into a profile, replacing its rules
+func (r *TaxProfileRequest) applyTo(profile *models.TaxProfile) {
+	profile.Name = r.Name
+	profile.Rules = make([]models.TaxRule, 0, len(r.Rules))
+	for _, rule := range r.Rules {
+		profile.Rules = append(profile.Rules, models.TaxRule{
+			Name:      rule.Name,
+			Kind:      rule.Kind,
+			Rate:      rule.Rate,
+			Amount:    rule.Amount,
+			Base:      rule.Base,
+			Franchise: rule.Franchise,
+			Currency:  rule.Currency,
+		})
+	}
+}
+
+// Create creates a tax profile
+func (h *TaxProfileHandler) Create(c *fiber.Ctx) error {
+	var req TaxProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	profile := &models.TaxProfile{UserID: middleware.UserID(c)}
+	req.applyTo(profile)
+	if err := h.service.CreateProfile(profile); err != nil {
+		return taxProfileError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(profile)
+}
+
+// Update replaces the name and rules of a tax profile. The taxes of the
+// pending products that use it are recomputed.
+func (h *TaxProfileHandler) Update(c *fiber.Ctx) error {
+	profile, ok := h.findProfile(c)
+	if !ok {
+		return nil
+	}
+
+	var req TaxProfileRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	req.applyTo(profile)
+	if err := h.service.UpdateProfile(profile); err != nil {
+		return taxProfileError(c, err)
+	}
+
+	return c.JSON(profile)
+}
+
+// Delete deletes a tax profile by ID. Its products and stores are unlinked.
+func (h *TaxProfileHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid tax profile ID",
+		})
+	}
+
+	if err := h.service.DeleteProfile(middleware.UserID(c), uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tax profile not found",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// findProfile loads the tax profile in the :id param. When it can't, it
+// writes the error response and returns false.
+func (h *TaxProfileHandler) findProfile(c *fiber.Ctx) (*models.TaxProfile, bool) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid tax profile ID",
+		})
+		return nil, false
+	}
+
+	profile, err := h.repo.FindByID(middleware.UserID(c), uint(id))
+	if err != nil {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Tax profile not found",
+		})
+		return nil, false
+	}
+	return profile, true
+}
+
+// taxProfileError reports a name already taken as 409 and validation errors as 400
+func taxProfileError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrDuplicateTaxProfile) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/buylist-manager/backend/internal/middleware"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/repository"
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Tamaño de página del log de entregas
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+// WebhookHandler handles HTTP requests for webhooks and their deliveries
+type WebhookHandler struct {
+	repo    repository.WebhookRepository
+	service services.WebhookService
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(repo repository.WebhookRepository, service services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{repo: repo, service: service}
+}
+
+// WebhookRequest represents the request body for creating or updating a webhook
+type WebhookRequest struct {
+	URL    string   `json:"url" validate:"required"`
+	Secret string   `json:"secret"` // Clave del HMAC; al actualizar, vacío = la misma
+	Events []string `json:"events" validate:"required"`
+	Active *bool    `json:"active"` // Default true
+}
+
+// applyTo copies the request into a webhook
+func (r *WebhookRequest) applyTo(webhook *models.Webhook) {
+	webhook.URL = r.URL
+	if r.Secret != "" {
+		webhook.Secret = r.Secret
+	}
+	webhook.Events = r.Events
+	if r.Active != nil {
+		webhook.Active = *r.Active
+	}
+}
+
+// GetAll lists the webhooks. The secrets are never returned.
+func (h *WebhookHandler) GetAll(c *fiber.Ctx) error {
+	webhooks, err := h.service.List(middleware.UserID(c))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch webhooks",
+		})
+	}
+
+	return c.JSON(webhooks)
+}
+
+// GetByID retrieves a single webhook by ID
+func (h *WebhookHandler) GetByID(c *fiber.Ctx) error {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return nil
+	}
+
+	return c.JSON(webhook)
+}
+
+// Create registers a webhook for some events
+func (h *WebhookHandler) Create(c *fiber.Ctx) error {
+	var req WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	webhook := &models.Webhook{UserID: middleware.UserID(c), Active: true}
+	req.applyTo(webhook)
+	if err := h.service.CreateWebhook(webhook); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(webhook)
+}
+
+// Update replaces the URL, events and active flag of a webhook, and its secret if given
+func (h *WebhookHandler) Update(c *fiber.Ctx) error {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return nil
+	}
+
+	var req WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	req.applyTo(webhook)
+	if err := h.service.UpdateWebhook(webhook); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(webhook)
+}
+
+// Delete deletes a webhook by ID, with its delivery log
+func (h *WebhookHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid webhook ID",
+		})
+	}
+
+	if err := h.service.DeleteWebhook(middleware.UserID(c), uint(id)); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Webhook not found",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetDeliveries returns the latest deliveries of a webhook, newest first.
+// Filtros opcionales: ?status=pending|success|failed y ?limit= (default 50, máximo 200).
+func (h *WebhookHandler) GetDeliveries(c *fiber.Ctx) error {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return nil
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", models.DeliveryStatusPending, models.DeliveryStatusSuccess, models.DeliveryStatusFailed:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid status parameter",
+		})
+	}
+	limit, err := parseLimitParam(c.Query("limit"), defaultDeliveryLimit, maxDeliveryLimit)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid limit parameter",
+		})
+	}
+
+	deliveries, err := h.service.GetDeliveries(webhook.UserID, webhook.ID, status, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch deliveries",
+		})
+	}
+
+	return c.JSON(deliveries)
+}
+
+// findWebhook loads the webhook in the :id param. When it can't, it writes the
+// error response and returns false.
+func (h *WebhookHandler) findWebhook(c *fiber.Ctx) (*models.Webhook, bool) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid webhook ID",
+		})
+		return nil, false
+	}
+
+	webhook, err := h.repo.FindByID(middleware.UserID(c), uint(id))
+	if err != nil {
+		c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Webhook not found",
+		})
+		return nil, false
+	}
+	return webhook, true
+}
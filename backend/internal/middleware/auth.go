@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/buylist-manager/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// userIDKey is the fiber.Ctx Locals key holding the authenticated user ID
+const userIDKey = "userID"
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer <token>" header
+// and stores the authenticated user ID for the handlers
+func RequireAuth(authService services.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Missing bearer token",
+			})
+		}
+
+		userID, err := authService.ParseAccessToken(token)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid or expired token",
+			})
+		}
+
+		c.Locals(userIDKey, userID)
+		return c.Next()
+	}
+}
+
+// RequireAPIToken rejects requests without an "Authorization: Bearer <token>"
+// header holding a valid API token of the scope, and stores its owner for the
+// handlers. Access tokens aren't accepted.
+func RequireAPIToken(tokens services.APITokenService, scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Missing bearer token",
+			})
+		}
+
+		userID, err := tokens.Authenticate(token, scope)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid or revoked API token",
+			})
+		}
+
+		c.Locals(userIDKey, userID)
+		return c.Next()
+	}
+}
+
+// RequireAdmin rejects users without the admin flag, which only an operator
+// sets (cmd/admin): registering with some email is never enough. It goes
+// after RequireAuth.
+func RequireAdmin(authService services.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, err := authService.GetUser(UserID(c))
+		if err != nil || !user.IsAdmin {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Admin access required",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// UserID returns the authenticated user ID set by RequireAuth (0 if none)
+func UserID(c *fiber.Ctx) uint {
+	userID, _ := c.Locals(userIDKey).(uint)
+	return userID
+}
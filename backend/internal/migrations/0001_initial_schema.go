@@ -0,0 +1,84 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Snapshot of the MVP schema. Migrations keep their own copies of the structs so
+// later changes to internal/models don't change what an old migration creates.
+
+type category0001 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"size:100;not null"`
+	Type      string `gorm:"size:20;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	Subcategories []subcategory0001 `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE"`
+}
+
+func (category0001) TableName() string { return "categories" }
+
+type subcategory0001 struct {
+	ID         uint   `gorm:"primaryKey"`
+	CategoryID uint   `gorm:"not null"`
+	Name       string `gorm:"size:100;not null"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+
+	Category *category0001 `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE"`
+}
+
+func (subcategory0001) TableName() string { return "subcategories" }
+
+type product0001 struct {
+	ID             uint    `gorm:"primaryKey"`
+	Name           string  `gorm:"size:255;not null"`
+	Description    string  `gorm:"type:text"`
+	BasePrice      float64 `gorm:"type:decimal(10,2);not null"`
+	ShippingCost   float64 `gorm:"type:decimal(10,2);default:0"`
+	Taxes          float64 `gorm:"type:decimal(10,2);default:0"`
+	TotalPrice     float64 `gorm:"type:decimal(10,2)"`
+	SourceURL      string  `gorm:"size:500"`
+	PriceDate      *time.Time
+	CategoryID     uint    `gorm:"not null"`
+	SubcategoryID  uint    `gorm:"not null"`
+	RecurrenceType *string `gorm:"size:20"`
+	IsPurchased    bool    `gorm:"default:false"`
+	PurchaseDate   *time.Time
+	Notes          string `gorm:"type:text"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+
+	Category    *category0001    `gorm:"foreignKey:CategoryID"`
+	Subcategory *subcategory0001 `gorm:"foreignKey:SubcategoryID"`
+}
+
+func (product0001) TableName() string { return "products" }
+
+func init() {
+	register(&Migration{
+		Version: 1,
+		Name:    "initial_schema",
+		// Las tablas pueden existir si la base se creó con el AutoMigrate anterior
+		Up: func(tx *gorm.DB) error {
+			for _, table := range []interface{}{&category0001{}, &subcategory0001{}, &product0001{}} {
+				if tx.Migrator().HasTable(table) {
+					continue
+				}
+				if err := tx.Migrator().CreateTable(table); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("products", "subcategories", "categories")
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type priceHistory0002 struct {
+	ID           uint      `gorm:"primaryKey"`
+	ProductID    uint      `gorm:"not null;index:idx_price_history_product,priority:1"`
+	BasePrice    float64   `gorm:"type:decimal(10,2);not null"`
+	ShippingCost float64   `gorm:"type:decimal(10,2);default:0"`
+	Taxes        float64   `gorm:"type:decimal(10,2);default:0"`
+	TotalPrice   float64   `gorm:"type:decimal(10,2)"`
+	SourceURL    string    `gorm:"size:500"`
+	RecordedAt   time.Time `gorm:"not null;index:idx_price_history_product,priority:2,sort:desc"`
+	CreatedAt    time.Time
+
+	Product *product0001 `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+}
+
+func (priceHistory0002) TableName() string { return "price_history" }
+
+func init() {
+	register(&Migration{
+		Version: 2,
+		Name:    "price_history",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasTable(&priceHistory0002{}) {
+				return nil
+			}
+			return tx.Migrator().CreateTable(&priceHistory0002{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("price_history")
+		},
+	})
+}
@@ -0,0 +1,64 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type product0003 struct {
+	TargetPrice       *float64 `gorm:"type:decimal(10,2)"`
+	TargetDropPercent *float64 `gorm:"type:decimal(5,2)"`
+}
+
+func (product0003) TableName() string { return "products" }
+
+type alert0003 struct {
+	ID             uint     `gorm:"primaryKey"`
+	ProductID      uint     `gorm:"not null;index"`
+	Type           string   `gorm:"size:20;not null"`
+	PreviousPrice  float64  `gorm:"type:decimal(10,2)"`
+	CurrentPrice   float64  `gorm:"type:decimal(10,2)"`
+	TargetPrice    *float64 `gorm:"type:decimal(10,2)"`
+	DropPercent    float64  `gorm:"type:decimal(5,2)"`
+	Message        string   `gorm:"size:500"`
+	Acknowledged   bool     `gorm:"default:false;index"`
+	AcknowledgedAt *time.Time
+	NotifiedAt     *time.Time
+	CreatedAt      time.Time
+
+	Product *product0001 `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+}
+
+func (alert0003) TableName() string { return "alerts" }
+
+func init() {
+	register(&Migration{
+		Version: 3,
+		Name:    "alerts",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"TargetPrice", "TargetDropPercent"} {
+				if tx.Migrator().HasColumn(&product0003{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&product0003{}, column); err != nil {
+					return err
+				}
+			}
+
+			if tx.Migrator().HasTable(&alert0003{}) {
+				return nil
+			}
+			return tx.Migrator().CreateTable(&alert0003{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable("alerts"); err != nil {
+				return err
+			}
+			if err := dropColumn(tx, "products", "target_drop_percent"); err != nil {
+				return err
+			}
+			return dropColumn(tx, "products", "target_price")
+		},
+	})
+}
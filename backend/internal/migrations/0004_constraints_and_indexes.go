@@ -0,0 +1,57 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// productIndexes0004 are the indexes recommended in docs/DATABASE.md
+var productIndexes0004 = []struct{ name, columns string }{
+	{"idx_products_category", "category_id"},
+	{"idx_products_subcategory", "subcategory_id"},
+	{"idx_products_purchased", "is_purchased"},
+	{"idx_products_price_date", "price_date"},
+}
+
+func init() {
+	register(&Migration{
+		Version: 4,
+		Name:    "constraints_and_indexes",
+		Up: func(tx *gorm.DB) error {
+			// Backfill: normalizar valores cargados a mano antes de agregar los CHECK
+			if err := tx.Exec("UPDATE categories SET type = LOWER(TRIM(type))").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("UPDATE products SET recurrence_type = LOWER(TRIM(recurrence_type)) WHERE recurrence_type IS NOT NULL").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("UPDATE products SET recurrence_type = NULL WHERE recurrence_type = ''").Error; err != nil {
+				return err
+			}
+
+			if err := addEnumCheck(tx, "categories", "chk_categories_type", "type",
+				[]string{"one_time", "recurring"}, false); err != nil {
+				return err
+			}
+			if err := addEnumCheck(tx, "products", "chk_products_recurrence_type", "recurrence_type",
+				[]string{"monthly", "yearly"}, true); err != nil {
+				return err
+			}
+
+			for _, idx := range productIndexes0004 {
+				if err := createIndex(tx, idx.name, "products", idx.columns); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, idx := range productIndexes0004 {
+				if err := dropIndex(tx, idx.name); err != nil {
+					return err
+				}
+			}
+			if err := dropEnumCheck(tx, "products", "chk_products_recurrence_type"); err != nil {
+				return err
+			}
+			return dropEnumCheck(tx, "categories", "chk_categories_type")
+		},
+	})
+}
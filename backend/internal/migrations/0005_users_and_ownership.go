@@ -0,0 +1,68 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type user0005 struct {
+	ID           uint   `gorm:"primaryKey"`
+	Email        string `gorm:"size:255;not null;uniqueIndex"`
+	Name         string `gorm:"size:100"`
+	PasswordHash string `gorm:"size:255;not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (user0005) TableName() string { return "users" }
+
+type refreshToken0005 struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+
+	User *user0005 `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (refreshToken0005) TableName() string { return "refresh_tokens" }
+
+// ownedTables0005 get a user_id column. Existing rows keep user_id = 0 until the
+// first registered user claims them (see AuthService.Register).
+var ownedTables0005 = []string{"categories", "subcategories", "products", "alerts"}
+
+func init() {
+	register(&Migration{
+		Version: 5,
+		Name:    "users_and_ownership",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&user0005{}, &refreshToken0005{}); err != nil {
+				return err
+			}
+
+			for _, table := range ownedTables0005 {
+				if err := tx.Exec("ALTER TABLE " + table + " ADD COLUMN user_id bigint NOT NULL DEFAULT 0").Error; err != nil {
+					return err
+				}
+				if err := createIndex(tx, "idx_"+table+"_user_id", table, "user_id"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range ownedTables0005 {
+				if err := dropIndex(tx, "idx_"+table+"_user_id"); err != nil {
+					return err
+				}
+				if err := dropColumn(tx, table, "user_id"); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable("refresh_tokens", "users")
+		},
+	})
+}
@@ -0,0 +1,58 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// Búsqueda de productos en PostgreSQL:
+//   - f_unaccent: unaccent() no es IMMUTABLE, así que no se puede usar en columnas
+//     generadas ni índices; el wrapper con diccionario explícito sí.
+//   - search_vector: tsvector con config 'simple' (sin stemming, los datos mezclan
+//     español e inglés) y pesos A/B/C para name/description/notes.
+//   - search_text: el mismo texto sin acentos en minúsculas, con índice trigram
+//     para tolerar errores de tipeo.
+//
+// SQLite no tiene estas extensiones; ProductRepository.Search resuelve en Go.
+var productSearchUp0006 = []string{
+	"CREATE EXTENSION IF NOT EXISTS unaccent",
+	"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+	`CREATE OR REPLACE FUNCTION f_unaccent(text) RETURNS text
+		LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+		AS $$ SELECT public.unaccent('public.unaccent', $1) $$`,
+	`ALTER TABLE products ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('simple', f_unaccent(coalesce(name, ''))), 'A') ||
+		setweight(to_tsvector('simple', f_unaccent(coalesce(description, ''))), 'B') ||
+		setweight(to_tsvector('simple', f_unaccent(coalesce(notes, ''))), 'C')
+	) STORED`,
+	`ALTER TABLE products ADD COLUMN search_text text GENERATED ALWAYS AS (
+		lower(f_unaccent(coalesce(name, '') || ' ' || coalesce(description, '') || ' ' || coalesce(notes, '')))
+	) STORED`,
+	"CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector)",
+	"CREATE INDEX IF NOT EXISTS idx_products_search_text ON products USING GIN (search_text gin_trgm_ops)",
+}
+
+// Las extensiones se dejan instaladas: pueden estar en uso por otros objetos
+var productSearchDown0006 = []string{
+	"DROP INDEX IF EXISTS idx_products_search_text",
+	"DROP INDEX IF EXISTS idx_products_search_vector",
+	"ALTER TABLE products DROP COLUMN IF EXISTS search_text",
+	"ALTER TABLE products DROP COLUMN IF EXISTS search_vector",
+	"DROP FUNCTION IF EXISTS f_unaccent(text)",
+}
+
+func init() {
+	register(&Migration{
+		Version: 6,
+		Name:    "product_search",
+		Up: func(tx *gorm.DB) error {
+			if isSQLite(tx) {
+				return nil
+			}
+			return execAll(tx, productSearchUp0006)
+		},
+		Down: func(tx *gorm.DB) error {
+			if isSQLite(tx) {
+				return nil
+			}
+			return execAll(tx, productSearchDown0006)
+		},
+	})
+}
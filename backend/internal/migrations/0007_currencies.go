@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type exchangeRate0007 struct {
+	ID           uint      `gorm:"primaryKey"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_exchange_rates_pair_date,priority:1"`
+	FromCurrency string    `gorm:"size:3;not null;uniqueIndex:idx_exchange_rates_pair_date,priority:2"`
+	ToCurrency   string    `gorm:"size:3;not null;uniqueIndex:idx_exchange_rates_pair_date,priority:3"`
+	Date         time.Time `gorm:"type:date;not null;uniqueIndex:idx_exchange_rates_pair_date,priority:4"`
+	Rate         float64   `gorm:"type:decimal(18,6);not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	User *user0005 `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (exchangeRate0007) TableName() string { return "exchange_rates" }
+
+// currencyTables0007 get a currency column. Existing prices were all entered in
+// pesos, so they default to ARS.
+var currencyTables0007 = []string{"products", "price_history"}
+
+func init() {
+	register(&Migration{
+		Version: 7,
+		Name:    "currencies",
+		Up: func(tx *gorm.DB) error {
+			for _, table := range currencyTables0007 {
+				if err := tx.Exec("ALTER TABLE " + table + " ADD COLUMN currency varchar(3) NOT NULL DEFAULT 'ARS'").Error; err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().CreateTable(&exchangeRate0007{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable("exchange_rates"); err != nil {
+				return err
+			}
+			for _, table := range currencyTables0007 {
+				if err := dropColumn(tx, table, "currency"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
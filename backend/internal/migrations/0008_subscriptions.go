@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type product0008 struct {
+	SubscriptionStatus *string    `gorm:"size:20"`
+	StartDate          *time.Time `gorm:"type:date"`
+	NextBillingDate    *time.Time `gorm:"type:date"`
+	TrialEndsAt        *time.Time `gorm:"type:date"`
+}
+
+func (product0008) TableName() string { return "products" }
+
+var subscriptionColumns0008 = []string{"SubscriptionStatus", "StartDate", "NextBillingDate", "TrialEndsAt"}
+
+func init() {
+	register(&Migration{
+		Version: 8,
+		Name:    "subscriptions",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range subscriptionColumns0008 {
+				if tx.Migrator().HasColumn(&product0008{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&product0008{}, column); err != nil {
+					return err
+				}
+			}
+
+			// Las suscripciones existentes quedan activas desde que se compraron o cargaron.
+			// next_billing_date lo completa la renovación de suscripciones al iniciar la API.
+			startDate := "CAST(COALESCE(purchase_date, created_at) AS date)"
+			if isSQLite(tx) {
+				startDate = "substr(COALESCE(purchase_date, created_at), 1, 10) || ' 00:00:00+00:00'"
+			}
+			err := tx.Exec("UPDATE products SET subscription_status = 'active', start_date = " + startDate +
+				" WHERE recurrence_type IS NOT NULL").Error
+			if err != nil {
+				return err
+			}
+
+			if err := addEnumCheck(tx, "products", "chk_products_subscription_status", "subscription_status",
+				[]string{"trial", "active", "paused", "cancelled"}, true); err != nil {
+				return err
+			}
+			return createIndex(tx, "idx_products_next_billing_date", "products", "next_billing_date")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := dropIndex(tx, "idx_products_next_billing_date"); err != nil {
+				return err
+			}
+			if err := dropEnumCheck(tx, "products", "chk_products_subscription_status"); err != nil {
+				return err
+			}
+			for _, column := range []string{"trial_ends_at", "next_billing_date", "start_date", "subscription_status"} {
+				if err := dropColumn(tx, "products", column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,92 @@
+package migrations
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type product0009 struct {
+	RecurrenceInterval *int
+	RecurrenceUnit     *string `gorm:"size:10"`
+}
+
+func (product0009) TableName() string { return "products" }
+
+type productRecurrenceType0009 struct {
+	RecurrenceType *string `gorm:"size:20"`
+}
+
+func (productRecurrenceType0009) TableName() string { return "products" }
+
+func init() {
+	register(&Migration{
+		Version: 9,
+		Name:    "recurrence",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"RecurrenceInterval", "RecurrenceUnit"} {
+				if tx.Migrator().HasColumn(&product0009{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&product0009{}, column); err != nil {
+					return err
+				}
+			}
+
+			// monthly -> cada 1 month, yearly -> cada 1 year
+			err := tx.Exec("UPDATE products SET recurrence_interval = 1, recurrence_unit = " +
+				"CASE recurrence_type WHEN 'yearly' THEN 'year' ELSE 'month' END " +
+				"WHERE recurrence_type IS NOT NULL").Error
+			if err != nil {
+				return err
+			}
+
+			// SQLite no puede borrar una columna que usa un trigger
+			if err := dropEnumCheck(tx, "products", "chk_products_recurrence_type"); err != nil {
+				return err
+			}
+			if err := dropColumn(tx, "products", "recurrence_type"); err != nil {
+				return err
+			}
+			return addEnumCheck(tx, "products", "chk_products_recurrence_unit", "recurrence_unit",
+				[]string{"day", "week", "month", "year"}, true)
+		},
+		Down: func(tx *gorm.DB) error {
+			// Sólo "cada 1 mes" y "cada 1 año" tienen equivalente en recurrence_type
+			var custom int64
+			err := tx.Table("products").
+				Where("recurrence_unit IS NOT NULL AND NOT (recurrence_interval = 1 AND recurrence_unit IN ('month', 'year'))").
+				Count(&custom).Error
+			if err != nil {
+				return err
+			}
+			if custom > 0 {
+				return errors.New("can't revert: some products have a recurrence other than monthly or yearly")
+			}
+
+			if err := dropEnumCheck(tx, "products", "chk_products_recurrence_unit"); err != nil {
+				return err
+			}
+			if !tx.Migrator().HasColumn(&productRecurrenceType0009{}, "RecurrenceType") {
+				if err := tx.Migrator().AddColumn(&productRecurrenceType0009{}, "RecurrenceType"); err != nil {
+					return err
+				}
+			}
+			err = tx.Exec("UPDATE products SET recurrence_type = " +
+				"CASE recurrence_unit WHEN 'year' THEN 'yearly' ELSE 'monthly' END " +
+				"WHERE recurrence_unit IS NOT NULL").Error
+			if err != nil {
+				return err
+			}
+			if err := addEnumCheck(tx, "products", "chk_products_recurrence_type", "recurrence_type",
+				[]string{"monthly", "yearly"}, true); err != nil {
+				return err
+			}
+
+			if err := dropColumn(tx, "products", "recurrence_unit"); err != nil {
+				return err
+			}
+			return dropColumn(tx, "products", "recurrence_interval")
+		},
+	})
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// budgetTables0010 get an optional budget: amount, period and currency
+var budgetTables0010 = []string{"categories", "subcategories"}
+
+func init() {
+	register(&Migration{
+		Version: 10,
+		Name:    "budgets",
+		Up: func(tx *gorm.DB) error {
+			for _, table := range budgetTables0010 {
+				err := execAll(tx, []string{
+					"ALTER TABLE " + table + " ADD COLUMN budget_amount decimal(10,2)",
+					"ALTER TABLE " + table + " ADD COLUMN budget_period varchar(10)",
+					"ALTER TABLE " + table + " ADD COLUMN budget_currency varchar(3)",
+				})
+				if err != nil {
+					return err
+				}
+				if err := addEnumCheck(tx, table, "chk_"+table+"_budget_period", "budget_period",
+					[]string{"month", "year"}, true); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, table := range budgetTables0010 {
+				if err := dropEnumCheck(tx, table, "chk_"+table+"_budget_period"); err != nil {
+					return err
+				}
+				for _, column := range []string{"budget_currency", "budget_period", "budget_amount"} {
+					if err := dropColumn(tx, table, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	})
+}
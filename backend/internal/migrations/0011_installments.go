@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type product0011 struct {
+	InstallmentCount     *int
+	InstallmentRate      *float64   `gorm:"type:decimal(7,4)"`
+	InstallmentTotal     *float64   `gorm:"type:decimal(10,2)"`
+	FirstInstallmentDate *time.Time `gorm:"type:date"`
+	LastInstallmentDate  *time.Time `gorm:"type:date"`
+}
+
+func (product0011) TableName() string { return "products" }
+
+var installmentColumns0011 = []string{
+	"InstallmentCount", "InstallmentRate", "InstallmentTotal", "FirstInstallmentDate", "LastInstallmentDate",
+}
+
+func init() {
+	register(&Migration{
+		Version: 11,
+		Name:    "installments",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range installmentColumns0011 {
+				if tx.Migrator().HasColumn(&product0011{}, column) {
+					continue
+				}
+				if err := tx.Migrator().AddColumn(&product0011{}, column); err != nil {
+					return err
+				}
+			}
+			// Las cuotas comprometidas se buscan por la fecha de la última
+			return createIndex(tx, "idx_products_last_installment_date", "products", "last_installment_date")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := dropIndex(tx, "idx_products_last_installment_date"); err != nil {
+				return err
+			}
+			for _, column := range []string{
+				"last_installment_date", "first_installment_date", "installment_total", "installment_rate", "installment_count",
+			} {
+				if err := dropColumn(tx, "products", column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type priceIndex0012 struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_price_indexes_currency_month,priority:1"`
+	Currency  string    `gorm:"size:3;not null;uniqueIndex:idx_price_indexes_currency_month,priority:2"`
+	Month     time.Time `gorm:"type:date;not null;uniqueIndex:idx_price_indexes_currency_month,priority:3"`
+	Value     float64   `gorm:"type:decimal(18,6);not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User *user0005 `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (priceIndex0012) TableName() string { return "price_indexes" }
+
+func init() {
+	register(&Migration{
+		Version: 12,
+		Name:    "price_indexes",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&priceIndex0012{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("price_indexes")
+		},
+	})
+}
@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type offer0013 struct {
+	ID           uint      `gorm:"primaryKey"`
+	UserID       uint      `gorm:"not null;index"`
+	ProductID    uint      `gorm:"not null;index"`
+	Store        string    `gorm:"size:100;not null"`
+	URL          string    `gorm:"size:500"`
+	BasePrice    float64   `gorm:"type:decimal(10,2);not null"`
+	ShippingCost float64   `gorm:"type:decimal(10,2);default:0"`
+	Taxes        float64   `gorm:"type:decimal(10,2);default:0"`
+	TotalPrice   float64   `gorm:"type:decimal(10,2)"`
+	Currency     string    `gorm:"size:3;not null;default:'ARS'"`
+	ObservedAt   time.Time `gorm:"not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+
+	User    *user0005    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Product *product0001 `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+}
+
+func (offer0013) TableName() string { return "offers" }
+
+// product0013 gets the offer chosen by hand. No FK: the service clears it
+// when the offer is deleted.
+type product0013 struct {
+	ChosenOfferID *uint
+}
+
+func (product0013) TableName() string { return "products" }
+
+func init() {
+	register(&Migration{
+		Version: 13,
+		Name:    "offers",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&offer0013{}); err != nil {
+				return err
+			}
+			return tx.Migrator().AddColumn(&product0013{}, "ChosenOfferID")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := dropColumn(tx, "products", "chosen_offer_id"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable("offers")
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type store0014 struct {
+	ID                    uint     `gorm:"primaryKey"`
+	UserID                uint     `gorm:"not null;uniqueIndex:idx_stores_user_domain,priority:1"`
+	Name                  string   `gorm:"size:100;not null"`
+	Domain                string   `gorm:"size:255;not null;uniqueIndex:idx_stores_user_domain,priority:2"`
+	DefaultShippingCost   float64  `gorm:"type:decimal(10,2);default:0"`
+	FreeShippingThreshold *float64 `gorm:"type:decimal(10,2)"`
+	Currency              string   `gorm:"size:3;not null;default:'ARS'"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+
+	User *user0005 `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (store0014) TableName() string { return "stores" }
+
+// product0014 gets the store matched by its source URL. No FK: the service
+// unlinks the products when the store is deleted.
+type product0014 struct {
+	StoreID *uint
+}
+
+func (product0014) TableName() string { return "products" }
+
+func init() {
+	register(&Migration{
+		Version: 14,
+		Name:    "stores",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&store0014{}); err != nil {
+				return err
+			}
+			if err := tx.Migrator().AddColumn(&product0014{}, "StoreID"); err != nil {
+				return err
+			}
+			return createIndex(tx, "idx_products_store_id", "products", "store_id")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := dropIndex(tx, "idx_products_store_id"); err != nil {
+				return err
+			}
+			if err := dropColumn(tx, "products", "store_id"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable("stores")
+		},
+	})
+}
@@ -0,0 +1,80 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type taxProfile0015 struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_tax_profiles_user_name,priority:1"`
+	Name      string `gorm:"size:100;not null;uniqueIndex:idx_tax_profiles_user_name,priority:2"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User *user0005 `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (taxProfile0015) TableName() string { return "tax_profiles" }
+
+type taxRule0015 struct {
+	ID           uint     `gorm:"primaryKey"`
+	TaxProfileID uint     `gorm:"not null;index"`
+	Position     int      `gorm:"not null"`
+	Name         string   `gorm:"size:100;not null"`
+	Kind         string   `gorm:"size:20;not null"`
+	Rate         float64  `gorm:"type:decimal(7,4)"`
+	Amount       float64  `gorm:"type:decimal(10,2)"`
+	Base         string   `gorm:"size:20;not null"`
+	Franchise    *float64 `gorm:"type:decimal(10,2)"`
+	Currency     string   `gorm:"size:3"`
+
+	TaxProfile *taxProfile0015 `gorm:"foreignKey:TaxProfileID;constraint:OnDelete:CASCADE"`
+}
+
+func (taxRule0015) TableName() string { return "tax_rules" }
+
+// product0015 gets its tax profile and the breakdown of its computed taxes.
+// No FK: the service unlinks the products when the profile is deleted.
+type product0015 struct {
+	TaxProfileID *uint
+	TaxBreakdown *string `gorm:"type:text"`
+}
+
+func (product0015) TableName() string { return "products" }
+
+type store0015 struct {
+	TaxProfileID *uint
+}
+
+func (store0015) TableName() string { return "stores" }
+
+func init() {
+	register(&Migration{
+		Version: 15,
+		Name:    "tax_profiles",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&taxProfile0015{}, &taxRule0015{}); err != nil {
+				return err
+			}
+			for _, column := range []string{"TaxProfileID", "TaxBreakdown"} {
+				if err := tx.Migrator().AddColumn(&product0015{}, column); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().AddColumn(&store0015{}, "TaxProfileID")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := dropColumn(tx, "stores", "tax_profile_id"); err != nil {
+				return err
+			}
+			for _, column := range []string{"tax_breakdown", "tax_profile_id"} {
+				if err := dropColumn(tx, "products", column); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable("tax_rules", "tax_profiles")
+		},
+	})
+}
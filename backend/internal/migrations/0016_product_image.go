@@ -0,0 +1,23 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// product0016 gets the image of the page it was added from
+type product0016 struct {
+	ImageURL string `gorm:"size:500"`
+}
+
+func (product0016) TableName() string { return "products" }
+
+func init() {
+	register(&Migration{
+		Version: 16,
+		Name:    "product_image",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&product0016{}, "ImageURL")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumn(tx, "products", "image_url")
+		},
+	})
+}
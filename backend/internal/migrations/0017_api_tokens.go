@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type apiToken0017 struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null;index"`
+	Name       string `gorm:"size:100;not null"`
+	Scope      string `gorm:"size:20;not null"`
+	TokenHash  string `gorm:"size:64;not null;uniqueIndex"`
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+
+	User *user0005 `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (apiToken0017) TableName() string { return "api_tokens" }
+
+func init() {
+	register(&Migration{
+		Version: 17,
+		Name:    "api_tokens",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&apiToken0017{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("api_tokens")
+		},
+	})
+}
@@ -0,0 +1,38 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// product0018 keeps what the store's API said the last time it was asked
+type product0018 struct {
+	Available      *bool
+	PriceCheckedAt *time.Time
+}
+
+func (product0018) TableName() string { return "products" }
+
+func init() {
+	register(&Migration{
+		Version: 18,
+		Name:    "price_refresh",
+		Up: func(tx *gorm.DB) error {
+			for _, column := range []string{"Available", "PriceCheckedAt"} {
+				if err := tx.Migrator().AddColumn(&product0018{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"price_checked_at", "available"} {
+				if err := dropColumn(tx, "products", column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type scheduledJob0019 struct {
+	ID             uint   `gorm:"primaryKey"`
+	Name           string `gorm:"size:50;not null;uniqueIndex"`
+	Schedule       string `gorm:"size:100;not null"`
+	Enabled        bool   `gorm:"not null;default:true"`
+	NextRunAt      *time.Time
+	RunningSince   *time.Time
+	LastStartedAt  *time.Time
+	LastFinishedAt *time.Time
+	LastStatus     string `gorm:"size:20"`
+	LastResult     string `gorm:"type:text"`
+	LastDurationMs int64
+	RunCount       int `gorm:"not null;default:0"`
+	FailureCount   int `gorm:"not null;default:0"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (scheduledJob0019) TableName() string { return "scheduled_jobs" }
+
+func init() {
+	register(&Migration{
+		Version: 19,
+		Name:    "scheduled_jobs",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&scheduledJob0019{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("scheduled_jobs")
+		},
+	})
+}
@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type webhook0020 struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	URL       string `gorm:"size:500;not null"`
+	Secret    string `gorm:"size:255;not null"`
+	Events    string `gorm:"type:text;not null"`
+	Active    bool   `gorm:"not null;default:true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	User *user0005 `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (webhook0020) TableName() string { return "webhooks" }
+
+type webhookDelivery0020 struct {
+	ID             uint       `gorm:"primaryKey"`
+	WebhookID      uint       `gorm:"not null;index"`
+	UserID         uint       `gorm:"not null;index"`
+	Event          string     `gorm:"size:50;not null"`
+	Payload        string     `gorm:"type:text;not null"`
+	Status         string     `gorm:"size:20;not null;default:'pending'"`
+	Attempts       int        `gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time `gorm:"index"`
+	LastAttemptAt  *time.Time
+	ResponseStatus int
+	LastError      string `gorm:"size:500"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+
+	Webhook *webhook0020 `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE"`
+}
+
+func (webhookDelivery0020) TableName() string { return "webhook_deliveries" }
+
+func init() {
+	register(&Migration{
+		Version: 20,
+		Name:    "webhooks",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().CreateTable(&webhook0020{}); err != nil {
+				return err
+			}
+			return tx.Migrator().CreateTable(&webhookDelivery0020{})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable("webhook_deliveries"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable("webhooks")
+		},
+	})
+}
@@ -0,0 +1,23 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// user0021 gets the admin flag, which only cmd/admin sets
+type user0021 struct {
+	IsAdmin bool `gorm:"not null;default:false"`
+}
+
+func (user0021) TableName() string { return "users" }
+
+func init() {
+	register(&Migration{
+		Version: 21,
+		Name:    "user_admin",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&user0021{}, "IsAdmin")
+		},
+		Down: func(tx *gorm.DB) error {
+			return dropColumn(tx, "users", "is_admin")
+		},
+	})
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type statsSnapshot0022 struct {
+	ID                    uint      `gorm:"primaryKey"`
+	UserID                uint      `gorm:"not null;uniqueIndex:idx_stats_snapshots_user_date,priority:1"`
+	Date                  time.Time `gorm:"type:date;not null;uniqueIndex:idx_stats_snapshots_user_date,priority:2"`
+	Currency              string    `gorm:"size:3;not null"`
+	PendingCount          int64     `gorm:"not null;default:0"`
+	PurchasedCount        int64     `gorm:"not null;default:0"`
+	PendingTotal          float64   `gorm:"type:decimal(14,2);not null;default:0"`
+	PurchasedTotal        float64   `gorm:"type:decimal(14,2);not null;default:0"`
+	TotalPendingOneTime   float64   `gorm:"type:decimal(14,2);not null;default:0"`
+	MonthlyRecurringCost  float64   `gorm:"type:decimal(14,2);not null;default:0"`
+	InstallmentsRemaining float64   `gorm:"type:decimal(14,2);not null;default:0"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+
+	User *user0005 `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (statsSnapshot0022) TableName() string { return "stats_snapshots" }
+
+func init() {
+	register(&Migration{
+		Version: 22,
+		Name:    "stats_snapshots",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().CreateTable(&statsSnapshot0022{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("stats_snapshots")
+		},
+	})
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+func init() {
+	register(&Migration{
+		Version: 23,
+		Name:    "product_category_from_subcategory",
+		Up: func(tx *gorm.DB) error {
+			// Si la categoría de la subcategoría es de otro tipo, el producto quedaría
+			// con una recurrencia que su categoría no admite: se corrige a mano
+			var conflicting []uint
+			err := tx.Table("products").
+				Joins("JOIN subcategories ON subcategories.id = products.subcategory_id").
+				Joins("JOIN categories AS product_category ON product_category.id = products.category_id").
+				Joins("JOIN categories AS subcategory_category ON subcategory_category.id = subcategories.category_id").
+				Where("products.category_id <> subcategories.category_id AND product_category.type <> subcategory_category.type").
+				Order("products.id").
+				Pluck("products.id", &conflicting).Error
+			if err != nil {
+				return err
+			}
+			if len(conflicting) > 0 {
+				return fmt.Errorf("products %v are in a subcategory of a category of another type: "+
+					"move them to a subcategory of their own category and migrate again", conflicting)
+			}
+
+			// Productos cargados con una subcategoría de otra categoría: manda la subcategoría
+			return tx.Exec(`UPDATE products SET category_id = (
+				SELECT subcategories.category_id FROM subcategories WHERE subcategories.id = products.subcategory_id
+			) WHERE category_id <> (
+				SELECT subcategories.category_id FROM subcategories WHERE subcategories.id = products.subcategory_id
+			)`).Error
+		},
+		Down: func(tx *gorm.DB) error {
+			// Irreversible: sólo corrige datos (el schema no cambia) y no se guarda
+			// cuál era la categoría equivocada, así que revertirla no hace nada
+			return nil
+		},
+	})
+}
@@ -0,0 +1,99 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// isSQLite reports whether the migration runs against SQLite
+func isSQLite(tx *gorm.DB) bool {
+	return tx.Dialector.Name() == "sqlite"
+}
+
+// createIndex creates an index if it doesn't exist (same syntax on PostgreSQL and SQLite)
+func createIndex(tx *gorm.DB, name, table, columns string) error {
+	return tx.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", name, table, columns)).Error
+}
+
+// dropIndex drops an index if it exists
+func dropIndex(tx *gorm.DB, name string) error {
+	return tx.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", name)).Error
+}
+
+// dropColumn drops a column with a plain ALTER TABLE.
+// SQLite supports it since 3.35; GORM's SQLite migrator instead recreates the
+// table, which silently loses its indexes.
+func dropColumn(tx *gorm.DB, table, column string) error {
+	if !tx.Migrator().HasColumn(table, column) {
+		return nil
+	}
+	return tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, column)).Error
+}
+
+// addEnumCheck restricts a column to a set of values.
+// PostgreSQL gets a real CHECK constraint. SQLite can't add constraints to an
+// existing table, so the check is emulated with BEFORE INSERT/UPDATE triggers.
+func addEnumCheck(tx *gorm.DB, table, name, column string, values []string, nullable bool) error {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	list := strings.Join(quoted, ", ")
+
+	if !isSQLite(tx) {
+		condition := fmt.Sprintf("%s IN (%s)", column, list)
+		if nullable {
+			condition = fmt.Sprintf("%s IS NULL OR %s", column, condition)
+		}
+		return tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s)", table, name, condition)).Error
+	}
+
+	violation := fmt.Sprintf("NEW.%s NOT IN (%s)", column, list)
+	if nullable {
+		violation = fmt.Sprintf("NEW.%s IS NOT NULL AND %s", column, violation)
+	} else {
+		violation = fmt.Sprintf("NEW.%s IS NULL OR %s", column, violation)
+	}
+	message := fmt.Sprintf("CHECK constraint failed: %s", name)
+
+	for _, event := range []string{"INSERT", "UPDATE OF " + column} {
+		suffix := "insert"
+		if event != "INSERT" {
+			suffix = "update"
+		}
+		err := tx.Exec(fmt.Sprintf(
+			"CREATE TRIGGER IF NOT EXISTS %s_%s BEFORE %s ON %s WHEN %s BEGIN SELECT RAISE(ABORT, '%s'); END",
+			name, suffix, event, table, violation, message,
+		)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropEnumCheck removes a check created with addEnumCheck
+func dropEnumCheck(tx *gorm.DB, table, name string) error {
+	if !isSQLite(tx) {
+		return tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", table, name)).Error
+	}
+
+	for _, suffix := range []string{"insert", "update"} {
+		if err := tx.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s_%s", name, suffix)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execAll runs raw statements in order, stopping at the first error
+func execAll(tx *gorm.DB, statements []string) error {
+	for _, stmt := range statements {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
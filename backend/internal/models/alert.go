@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// Alert types
+const (
+	AlertTypeTargetPrice = "target_price" // El precio total llegó al precio objetivo
+	AlertTypePriceDrop   = "price_drop"   // El precio bajó al menos el porcentaje configurado
+)
+
+// Alert represents a triggered price alert for a product
+type Alert struct {
+	ID             uint          `gorm:"primaryKey" json:"id"`
+	UserID         uint          `gorm:"not null;default:0;index" json:"-"` // Owner
+	ProductID      uint          `gorm:"not null;index" json:"product_id"`
+	Type           string        `gorm:"size:20;not null" json:"type"` // "target_price" or "price_drop"
+	PreviousPrice  money.Amount  `gorm:"type:decimal(10,2)" json:"previous_price"`
+	CurrentPrice   money.Amount  `gorm:"type:decimal(10,2)" json:"current_price"`
+	TargetPrice    *money.Amount `gorm:"type:decimal(10,2)" json:"target_price"`
+	DropPercent    float64       `gorm:"type:decimal(5,2)" json:"drop_percent"`
+	Message        string        `gorm:"size:500" json:"message"`
+	Acknowledged   bool          `gorm:"default:false;index" json:"acknowledged"`
+	AcknowledgedAt *time.Time    `json:"acknowledged_at"`
+	NotifiedAt     *time.Time    `json:"notified_at"` // Cuándo se entregó por el notifier
+	CreatedAt      time.Time     `json:"created_at"`
+
+	// Relationships
+	Product *Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"product,omitempty"`
+	User    *User    `gorm:"foreignKey:UserID" json:"-"` // A quién se le entrega
+}
+
+// TableName specifies the table name for GORM
+func (Alert) TableName() string {
+	return "alerts"
+}
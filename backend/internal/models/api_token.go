@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// API token scopes: what a token can be used for
+const (
+	APITokenScopeQuickAdd = "quick_add" // Sólo POST /quick-add (bookmarklet)
+)
+
+// APITokenPrefix starts every API token, so it's recognizable in a leak
+const APITokenPrefix = "blq_"
+
+// APIToken is a long-lived token limited to one scope, for clients that
+// can't refresh an access token, such as the bookmarklet. Only the SHA-256
+// hash of the token is stored.
+type APIToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"-"` // Owner
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Scope      string     `gorm:"size:20;not null" json:"scope"`
+	TokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// Budget periods
+const (
+	BudgetMonth = "month"
+	BudgetYear  = "year"
+)
+
+// Budget is a spending limit set on a category or subcategory. All fields are
+// nil when there is no budget.
+type Budget struct {
+	BudgetAmount   *money.Amount `gorm:"type:decimal(10,2)" json:"budget_amount"`
+	BudgetPeriod   *string       `gorm:"size:10" json:"budget_period"` // "month" o "year"
+	BudgetCurrency *string       `gorm:"size:3" json:"budget_currency"`
+}
+
+// HasBudget reports whether a budget is set
+func (b *Budget) HasBudget() bool {
+	return b.BudgetAmount != nil
+}
+
+// IsValidBudgetPeriod checks if the budget period is valid
+func IsValidBudgetPeriod(period string) bool {
+	return period == BudgetMonth || period == BudgetYear
+}
@@ -9,12 +9,15 @@ import (
 // Category represents a main category (one-time purchase or recurring subscription)
 type Category struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
+	UserID    uint           `gorm:"not null;default:0;index" json:"-"` // Owner
 	Name      string         `gorm:"size:100;not null" json:"name"`
 	Type      string         `gorm:"size:20;not null" json:"type"` // "one_time" or "recurring"
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete
 
+	Budget `gorm:"embedded"` // Presupuesto opcional (budget_amount, budget_period, budget_currency)
+
 	// Relationships
 	Subcategories []Subcategory `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE" json:"subcategories,omitempty"`
 }
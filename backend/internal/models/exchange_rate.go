@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// ExchangeRate is the value of one unit of FromCurrency in ToCurrency on a date
+// (1 USD = 1050.50 ARS -> From "USD", To "ARS", Rate 1050.5)
+type ExchangeRate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_exchange_rates_pair_date,priority:1" json:"-"` // Owner
+	FromCurrency string    `gorm:"size:3;not null;uniqueIndex:idx_exchange_rates_pair_date,priority:2" json:"from_currency"`
+	ToCurrency   string    `gorm:"size:3;not null;uniqueIndex:idx_exchange_rates_pair_date,priority:3" json:"to_currency"`
+	Date         time.Time `gorm:"type:date;not null;uniqueIndex:idx_exchange_rates_pair_date,priority:4" json:"date"`
+	Rate         float64   `gorm:"type:decimal(18,6);not null" json:"rate"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
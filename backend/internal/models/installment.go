@@ -0,0 +1,90 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// MaxInstallments caps the number of installments of a plan
+const MaxInstallments = 72
+
+// Installment is one payment of an installment plan ("cuota")
+type Installment struct {
+	Number  int          `json:"number"` // Desde 1
+	DueDate time.Time    `json:"due_date"`
+	Amount  money.Amount `json:"amount"`
+}
+
+// HasInstallments reports whether the product is paid in installments
+func (p *Product) HasInstallments() bool {
+	return p.InstallmentCount != nil
+}
+
+// Installments returns the payment schedule: one installment per month from
+// the first due date, clamped to the end of shorter months like billing dates.
+// The financed total is split evenly and the leftover cents go to the first
+// installments, so the schedule always adds up to InstallmentTotal.
+func (p *Product) Installments() []Installment {
+	if !p.HasInstallments() || p.InstallmentTotal == nil || p.FirstInstallmentDate == nil {
+		return nil
+	}
+
+	count := int64(*p.InstallmentCount)
+	total := p.InstallmentTotal.Cents()
+	base, leftover := total/count, total%count
+
+	first := DateOnly(*p.FirstInstallmentDate)
+	schedule := make([]Installment, count)
+	for i := range schedule {
+		cents := base
+		if int64(i) < leftover {
+			cents++
+		}
+		schedule[i] = Installment{
+			Number:  i + 1,
+			DueDate: addMonthsClamped(first, i),
+			Amount:  money.FromCents(cents),
+		}
+	}
+	return schedule
+}
+
+// FinancedTotal returns what a purchase costs in count monthly installments at
+// a nominal annual rate (TNA, in percent) with the French system: every
+// installment is principal * i / (1 - (1+i)^-count), with i = rate / 12.
+// A rate of 0 means interest-free ("cuotas sin interés").
+func FinancedTotal(principal money.Amount, annualRate float64, count int) (money.Amount, error) {
+	if count < 1 || count > MaxInstallments {
+		return money.Zero, errors.New("installment count must be between 1 and 72")
+	}
+	rate, ok := new(big.Rat).SetString(strconv.FormatFloat(annualRate, 'f', -1, 64))
+	if !ok || rate.Sign() < 0 {
+		return money.Zero, errors.New("installment rate cannot be negative")
+	}
+	if rate.Sign() == 0 {
+		return principal, nil
+	}
+
+	// i = TNA / 100 / 12 y factor = (1+i)^n, exactos
+	i := rate.Quo(rate, big.NewRat(1200, 1))
+	growth := new(big.Rat).Add(big.NewRat(1, 1), i)
+	factor := big.NewRat(1, 1)
+	for k := 0; k < count; k++ {
+		factor.Mul(factor, growth)
+	}
+
+	installment := new(big.Rat).Mul(principal.Rat(), i)
+	installment.Mul(installment, factor)
+	installment.Quo(installment, factor.Sub(factor, big.NewRat(1, 1)))
+	return money.FromRat(installment).Mul(int64(count)), nil
+}
+
+// FirstInstallmentDue returns the default first due date of a plan started on
+// a day: the same day of the next month
+func FirstInstallmentDue(start time.Time) time.Time {
+	return addMonthsClamped(DateOnly(start), 1)
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+func TestFinancedTotal(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal string
+		rate      float64
+		count     int
+		want      string
+	}{
+		{"interest-free", "100000.00", 0, 12, "100000.00"},
+		{"60% TNA in 12", "100000.00", 60, 12, "135390.48"}, // Cuotas de 11282.54
+		{"single installment", "1000.00", 12, 1, "1010.00"},
+		{"fractional rate", "250000.50", 85.5, 18, "451401.66"},
+		{"tiny rate rounds the installment", "1500.00", 0.01, 3, "1500.03"},
+		{"max installments", "999.99", 120, 72, "7207.20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FinancedTotal(money.MustParse(tt.principal), tt.rate, tt.count)
+			if err != nil {
+				t.Fatalf("FinancedTotal: %v", err)
+			}
+			if want := money.MustParse(tt.want); got != want {
+				t.Errorf("FinancedTotal(%s, %v, %d) = %s, want %s", tt.principal, tt.rate, tt.count, got, want)
+			}
+		})
+	}
+}
+
+func TestFinancedTotalErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rate  float64
+		count int
+	}{
+		{"no installments", 10, 0},
+		{"too many installments", 10, MaxInstallments + 1},
+		{"negative rate", -1, 12},
+		{"NaN rate", math.NaN(), 12},
+	}
+
+	for _, tt := range tests {
+		if _, err := FinancedTotal(money.MustParse("1000.00"), tt.rate, tt.count); err == nil {
+			t.Errorf("%s: FinancedTotal = nil error, want an error", tt.name)
+		}
+	}
+}
+
+func TestInstallments(t *testing.T) {
+	count := 3
+	total := money.MustParse("100.00")
+	first := time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)
+	p := &Product{InstallmentCount: &count, InstallmentTotal: &total, FirstInstallmentDate: &first}
+
+	want := []Installment{
+		{1, time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC), money.MustParse("33.34")},
+		{2, time.Date(2026, time.February, 28, 0, 0, 0, 0, time.UTC), money.MustParse("33.33")},
+		{3, time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC), money.MustParse("33.33")},
+	}
+	got := p.Installments()
+	if len(got) != len(want) {
+		t.Fatalf("Installments() returned %d installments, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Number != want[i].Number || !got[i].DueDate.Equal(want[i].DueDate) || got[i].Amount != want[i].Amount {
+			t.Errorf("installment %d = %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+}
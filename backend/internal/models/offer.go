@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+	"gorm.io/gorm"
+)
+
+// Offer is the price of a product at one store, as observed on a date
+type Offer struct {
+	ID           uint         `gorm:"primaryKey" json:"id"`
+	UserID       uint         `gorm:"not null;index" json:"-"` // Owner
+	ProductID    uint         `gorm:"not null;index" json:"product_id"`
+	Store        string       `gorm:"size:100;not null" json:"store"` // "MercadoLibre", "Amazon", ...
+	URL          string       `gorm:"size:500" json:"url"`
+	BasePrice    money.Amount `gorm:"type:decimal(10,2);not null" json:"base_price"`
+	ShippingCost money.Amount `gorm:"type:decimal(10,2);default:0" json:"shipping_cost"`
+	Taxes        money.Amount `gorm:"type:decimal(10,2);default:0" json:"taxes"`
+	TotalPrice   money.Amount `gorm:"type:decimal(10,2)" json:"total_price"` // Calculated field
+	Currency     string       `gorm:"size:3;not null;default:'ARS'" json:"currency"`
+	ObservedAt   time.Time    `gorm:"not null" json:"observed_at"` // Cuándo se vio ese precio
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+
+	// Relationships
+	Product *Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (Offer) TableName() string {
+	return "offers"
+}
+
+// BeforeSave is a GORM hook that calculates TotalPrice before saving
+func (o *Offer) BeforeSave(tx *gorm.DB) error {
+	o.TotalPrice = o.CalculateTotal()
+	return nil
+}
+
+// CalculateTotal returns base price + shipping + taxes
+func (o *Offer) CalculateTotal() money.Amount {
+	return money.Sum(o.BasePrice, o.ShippingCost, o.Taxes)
+}
+
+// ApplyTo copies the offer's price and URL to its product. The product keeps
+// its currency: convert takes each amount of the offer to it. An offer without
+// URL keeps the product's one, and with it its store.
+func (o *Offer) ApplyTo(p *Product, convert func(amount money.Amount) (money.Amount, error)) error {
+	prices := []money.Amount{o.BasePrice, o.ShippingCost, o.Taxes}
+	for i, amount := range prices {
+		converted, err := convert(amount)
+		if err != nil {
+			return err
+		}
+		prices[i] = converted
+	}
+
+	p.BasePrice, p.ShippingCost, p.Taxes = prices[0], prices[1], prices[2]
+	if o.URL != "" {
+		p.SourceURL = o.URL
+	}
+	return nil
+}
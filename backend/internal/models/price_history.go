@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// PriceHistory stores a snapshot of a product price before it was changed
+type PriceHistory struct {
+	ID           uint         `gorm:"primaryKey" json:"id"`
+	ProductID    uint         `gorm:"not null;index:idx_price_history_product,priority:1" json:"product_id"`
+	BasePrice    money.Amount `gorm:"type:decimal(10,2);not null" json:"base_price"`
+	ShippingCost money.Amount `gorm:"type:decimal(10,2);default:0" json:"shipping_cost"`
+	Taxes        money.Amount `gorm:"type:decimal(10,2);default:0" json:"taxes"`
+	TotalPrice   money.Amount `gorm:"type:decimal(10,2)" json:"total_price"`
+	Currency     string       `gorm:"size:3;not null;default:'ARS'" json:"currency"`
+	SourceURL    string       `gorm:"size:500" json:"source_url"`
+	RecordedAt   time.Time    `gorm:"not null;index:idx_price_history_product,priority:2,sort:desc" json:"recorded_at"` // Cuándo se registró ese precio
+	CreatedAt    time.Time    `json:"created_at"`
+
+	// Relationships
+	Product *Product `gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (PriceHistory) TableName() string {
+	return "price_history"
+}
+
+// NewPriceHistoryFromProduct builds a snapshot of the current price of a product.
+// RecordedAt is the date the price was originally registered (PriceDate), so the
+// history reflects when each price was actually observed.
+func NewPriceHistoryFromProduct(p *Product) *PriceHistory {
+	recordedAt := time.Now()
+	if p.PriceDate != nil {
+		recordedAt = *p.PriceDate
+	}
+
+	return &PriceHistory{
+		ProductID:    p.ID,
+		BasePrice:    p.BasePrice,
+		ShippingCost: p.ShippingCost,
+		Taxes:        p.Taxes,
+		TotalPrice:   p.CalculateTotal(),
+		Currency:     p.Currency,
+		SourceURL:    p.SourceURL,
+		RecordedAt:   recordedAt,
+	}
+}
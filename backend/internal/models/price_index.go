@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// PriceIndex is the value of a consumer price index (CPI) in a month for the
+// prices of one currency. Only the ratio between two months matters, so any
+// base works (INDEC publishes Dic 2016 = 100).
+type PriceIndex struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_price_indexes_currency_month,priority:1" json:"-"` // Owner
+	Currency  string    `gorm:"size:3;not null;uniqueIndex:idx_price_indexes_currency_month,priority:2" json:"currency"`
+	Month     time.Time `gorm:"type:date;not null;uniqueIndex:idx_price_indexes_currency_month,priority:3" json:"month"` // Primer día del mes
+	Value     float64   `gorm:"type:decimal(18,6);not null" json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (PriceIndex) TableName() string {
+	return "price_indexes"
+}
+
+// PriceAdjustment is a product's price restated in the money of another month
+// with the price index of its currency. It isn't stored.
+type PriceAdjustment struct {
+	To               string       `json:"to"`                // "2026-10"
+	Factor           float64      `json:"factor"`            // Índice de to / índice del mes del precio
+	InflationPercent float64      `json:"inflation_percent"` // (factor - 1) * 100
+	BasePrice        money.Amount `json:"base_price"`
+	TotalPrice       money.Amount `json:"total_price"`
+	Stale            bool         `json:"stale"` // Pendiente y la inflación desde price_date supera el umbral: conviene volver a chequear el precio
+}
+
+// MonthOf returns the first day of t's month, like DateOnly does with days
+func MonthOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -3,29 +3,68 @@ package models
 import (
 	"time"
 
+	"github.com/buylist-manager/backend/internal/money"
 	"gorm.io/gorm"
 )
 
 // Product represents an item to buy or a subscription
 type Product struct {
-	ID             uint           `gorm:"primaryKey" json:"id"`
-	Name           string         `gorm:"size:255;not null" json:"name"`
-	Description    string         `gorm:"type:text" json:"description"`
-	BasePrice      float64        `gorm:"type:decimal(10,2);not null" json:"base_price"`
-	ShippingCost   float64        `gorm:"type:decimal(10,2);default:0" json:"shipping_cost"`
-	Taxes          float64        `gorm:"type:decimal(10,2);default:0" json:"taxes"`
-	TotalPrice     float64        `gorm:"type:decimal(10,2)" json:"total_price"` // Calculated field
-	SourceURL      string         `gorm:"size:500" json:"source_url"`
-	PriceDate      *time.Time     `json:"price_date"`
-	CategoryID     uint           `gorm:"not null" json:"category_id"`
-	SubcategoryID  uint           `gorm:"not null" json:"subcategory_id"`
-	RecurrenceType *string        `gorm:"size:20" json:"recurrence_type"` // null, "monthly", "yearly"
-	IsPurchased    bool           `gorm:"default:false" json:"is_purchased"`
-	PurchaseDate   *time.Time     `json:"purchase_date"`
-	Notes          string         `gorm:"type:text" json:"notes"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete
+	ID                 uint           `gorm:"primaryKey" json:"id"`
+	UserID             uint           `gorm:"not null;default:0;index" json:"-"` // Owner
+	Name               string         `gorm:"size:255;not null" json:"name"`
+	Description        string         `gorm:"type:text" json:"description"`
+	BasePrice          money.Amount   `gorm:"type:decimal(10,2);not null" json:"base_price"`
+	ShippingCost       money.Amount   `gorm:"type:decimal(10,2);default:0" json:"shipping_cost"`
+	Taxes              money.Amount   `gorm:"type:decimal(10,2);default:0" json:"taxes"`
+	TotalPrice         money.Amount   `gorm:"type:decimal(10,2)" json:"total_price"` // Calculated field
+	Currency           string         `gorm:"size:3;not null;default:'ARS'" json:"currency"`
+	SourceURL          string         `gorm:"size:500" json:"source_url"`
+	ImageURL           string         `gorm:"size:500" json:"image_url"`
+	PriceDate          *time.Time     `json:"price_date"`
+	CategoryID         uint           `gorm:"not null" json:"category_id"`
+	SubcategoryID      uint           `gorm:"not null" json:"subcategory_id"`
+	RecurrenceInterval *int           `json:"recurrence_interval"`            // Cada cuántas unidades se cobra
+	RecurrenceUnit     *string        `gorm:"size:10" json:"recurrence_unit"` // null, "day", "week", "month", "year"
+	IsPurchased        bool           `gorm:"default:false" json:"is_purchased"`
+	PurchaseDate       *time.Time     `json:"purchase_date"`
+	Notes              string         `gorm:"type:text" json:"notes"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete
+
+	// Alertas de precio (opcionales)
+	TargetPrice       *money.Amount `gorm:"type:decimal(10,2)" json:"target_price"`       // Alertar cuando total_price <= target_price
+	TargetDropPercent *float64      `gorm:"type:decimal(5,2)" json:"target_drop_percent"` // Alertar cuando el precio baja este % o más
+
+	// Suscripción (sólo productos con recurrence_unit)
+	SubscriptionStatus *string    `gorm:"size:20" json:"subscription_status"` // "trial", "active", "paused", "cancelled"
+	StartDate          *time.Time `gorm:"type:date" json:"start_date"`
+	TrialEndsAt        *time.Time `gorm:"type:date" json:"trial_ends_at"`
+	NextBillingDate    *time.Time `gorm:"type:date" json:"next_billing_date"` // Calculada a partir de start_date o trial_ends_at
+
+	// Plan de cuotas (opcional, sólo compras únicas)
+	InstallmentCount     *int          `json:"installment_count"`
+	InstallmentRate      *float64      `gorm:"type:decimal(7,4)" json:"installment_rate"`   // TNA en %; 0 = sin interés, nil = se cargó el total
+	InstallmentTotal     *money.Amount `gorm:"type:decimal(10,2)" json:"installment_total"` // Total financiado
+	FirstInstallmentDate *time.Time    `gorm:"type:date" json:"first_installment_date"`
+	LastInstallmentDate  *time.Time    `gorm:"type:date" json:"last_installment_date"` // Calculada
+
+	// Oferta elegida a mano; sin ella el precio es el de la oferta más barata
+	ChosenOfferID *uint `json:"chosen_offer_id"`
+
+	// Tienda cuyo dominio coincide con el host de source_url; la asigna el servicio
+	StoreID *uint `gorm:"index" json:"store_id"`
+
+	// Perfil de impuestos (o el de la tienda): con él, taxes se calcula y se guarda el detalle
+	TaxProfileID *uint         `json:"tax_profile_id"`
+	TaxBreakdown *TaxBreakdown `gorm:"type:text" json:"tax_breakdown"`
+
+	// Última consulta del precio a la API de la tienda (refresh-price)
+	Available      *bool      `json:"available"` // nil = nunca se consultó
+	PriceCheckedAt *time.Time `json:"price_checked_at"`
+
+	// Precio actualizado por inflación (?adjust=inflation); no se guarda
+	Inflation *PriceAdjustment `gorm:"-" json:"inflation,omitempty"`
 
 	// Relationships
 	Category    *Category    `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
@@ -39,14 +78,28 @@ func (Product) TableName() string {
 
 // BeforeSave is a GORM hook that calculates TotalPrice before saving
 func (p *Product) BeforeSave(tx *gorm.DB) error {
-	p.TotalPrice = p.BasePrice + p.ShippingCost + p.Taxes
+	p.TotalPrice = p.CalculateTotal()
 	return nil
 }
 
-// IsValidRecurrenceType checks if the recurrence type is valid
-func (p *Product) IsValidRecurrenceType() bool {
-	if p.RecurrenceType == nil {
-		return true // null is valid
+// CalculateTotal returns base price + shipping + taxes
+func (p *Product) CalculateTotal() money.Amount {
+	return money.Sum(p.BasePrice, p.ShippingCost, p.Taxes)
+}
+
+// IsValidRecurrence checks if the recurrence interval and unit are valid
+func (p *Product) IsValidRecurrence() bool {
+	if p.RecurrenceUnit == nil {
+		return p.RecurrenceInterval == nil // null is valid
 	}
-	return *p.RecurrenceType == "monthly" || *p.RecurrenceType == "yearly"
+	return p.Recurrence().Validate() == nil
+}
+
+// HasSamePrice reports whether two products have identical price components
+// in the same currency
+func (p *Product) HasSamePrice(other *Product) bool {
+	return p.Currency == other.Currency &&
+		p.BasePrice == other.BasePrice &&
+		p.ShippingCost == other.ShippingCost &&
+		p.Taxes == other.Taxes
 }
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+// Recurrence units
+const (
+	RecurrenceDay   = "day"
+	RecurrenceWeek  = "week"
+	RecurrenceMonth = "month"
+	RecurrenceYear  = "year"
+)
+
+// MaxRecurrenceInterval caps the interval so a typo doesn't create a period of centuries
+const MaxRecurrenceInterval = 1000
+
+// Recurrence is a billing period: every Interval units ("every 3 months")
+type Recurrence struct {
+	Interval int
+	Unit     string
+}
+
+// Monthly and Yearly are the periods of the original monthly and yearly subscriptions
+var (
+	Monthly = Recurrence{Interval: 1, Unit: RecurrenceMonth}
+	Yearly  = Recurrence{Interval: 1, Unit: RecurrenceYear}
+)
+
+// Validate checks the interval and the unit
+func (r Recurrence) Validate() error {
+	switch r.Unit {
+	case RecurrenceDay, RecurrenceWeek, RecurrenceMonth, RecurrenceYear:
+	default:
+		return errors.New("recurrence unit must be 'day', 'week', 'month' or 'year'")
+	}
+	if r.Interval < 1 || r.Interval > MaxRecurrenceInterval {
+		return errors.New("recurrence interval must be between 1 and 1000")
+	}
+	return nil
+}
+
+// PerYear returns how many periods fit in a year, exactly (every 2 weeks ->
+// 365/14). A year counts as 365 days, 52 1/7 weeks or 12 months.
+func (r Recurrence) PerYear() *big.Rat {
+	n := int64(r.Interval)
+	switch r.Unit {
+	case RecurrenceDay:
+		return big.NewRat(365, n)
+	case RecurrenceWeek:
+		return big.NewRat(365, 7*n)
+	case RecurrenceMonth:
+		return big.NewRat(12, n)
+	default:
+		return big.NewRat(1, n)
+	}
+}
+
+// AddTo returns the date that is the given number of periods after t. Months
+// and years keep the day of month when it exists and use the last day of the
+// month otherwise (Jan 31 + 1 month = Feb 28).
+func (r Recurrence) AddTo(t time.Time, periods int) time.Time {
+	n := r.Interval * periods
+	switch r.Unit {
+	case RecurrenceDay:
+		return t.AddDate(0, 0, n)
+	case RecurrenceWeek:
+		return t.AddDate(0, 0, 7*n)
+	case RecurrenceMonth:
+		return addMonthsClamped(t, n)
+	default:
+		return addMonthsClamped(t, 12*n)
+	}
+}
+
+// Recurrence returns the product's billing period, or nil for one-time purchases
+func (p *Product) Recurrence() *Recurrence {
+	if p.RecurrenceUnit == nil {
+		return nil
+	}
+	r := Recurrence{Interval: 1, Unit: *p.RecurrenceUnit}
+	if p.RecurrenceInterval != nil {
+		r.Interval = *p.RecurrenceInterval
+	}
+	return &r
+}
+
+// SetRecurrence sets the product's billing period; nil makes it a one-time purchase
+func (p *Product) SetRecurrence(r *Recurrence) {
+	if r == nil {
+		p.RecurrenceInterval, p.RecurrenceUnit = nil, nil
+		return
+	}
+	interval, unit := r.Interval, r.Unit
+	p.RecurrenceInterval, p.RecurrenceUnit = &interval, &unit
+}
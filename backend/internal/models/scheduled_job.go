@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Job run statuses
+const (
+	JobStatusSuccess = "success"
+	JobStatusError   = "error"
+)
+
+// ScheduledJob is the persisted state of a background job: its schedule, if
+// it's enabled and how its last run went
+type ScheduledJob struct {
+	ID             uint       `gorm:"primaryKey" json:"-"`
+	Name           string     `gorm:"size:50;not null;uniqueIndex" json:"name"`
+	Schedule       string     `gorm:"size:100;not null" json:"schedule"` // Cron de 5 campos, "@daily" o "@every 1h"
+	Enabled        bool       `gorm:"not null;default:true" json:"enabled"`
+	NextRunAt      *time.Time `json:"next_run_at"`
+	RunningSince   *time.Time `json:"running_since"` // Mientras corre ninguna instancia la vuelve a largar
+	LastStartedAt  *time.Time `json:"last_started_at"`
+	LastFinishedAt *time.Time `json:"last_finished_at"`
+	LastStatus     string     `gorm:"size:20" json:"last_status"`   // "success", "error" o vacío si nunca corrió
+	LastResult     string     `gorm:"type:text" json:"last_result"` // Resumen de la corrida o el error
+	LastDurationMs int64      `json:"last_duration_ms"`
+	RunCount       int        `gorm:"not null;default:0" json:"run_count"`
+	FailureCount   int        `gorm:"not null;default:0" json:"failure_count"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ScheduledJob) TableName() string {
+	return "scheduled_jobs"
+}
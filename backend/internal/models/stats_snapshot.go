@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// StatsSnapshot are the headline numbers of a user's stats on a day, taken by
+// the snapshot_stats job, so their evolution can be charted later. Amounts are
+// in the currency the snapshot was taken in.
+type StatsSnapshot struct {
+	ID                    uint         `gorm:"primaryKey" json:"id"`
+	UserID                uint         `gorm:"not null;uniqueIndex:idx_stats_snapshots_user_date,priority:1" json:"-"` // Owner
+	Date                  time.Time    `gorm:"type:date;not null;uniqueIndex:idx_stats_snapshots_user_date,priority:2" json:"date"`
+	Currency              string       `gorm:"size:3;not null" json:"currency"`
+	PendingCount          int64        `gorm:"not null;default:0" json:"pending_count"`
+	PurchasedCount        int64        `gorm:"not null;default:0" json:"purchased_count"`
+	PendingTotal          money.Amount `gorm:"type:decimal(14,2);not null;default:0" json:"pending_total"` // Sumas: más lugar que un precio
+	PurchasedTotal        money.Amount `gorm:"type:decimal(14,2);not null;default:0" json:"purchased_total"`
+	TotalPendingOneTime   money.Amount `gorm:"type:decimal(14,2);not null;default:0" json:"total_pending_one_time"`
+	MonthlyRecurringCost  money.Amount `gorm:"type:decimal(14,2);not null;default:0" json:"monthly_recurring_cost"`
+	InstallmentsRemaining money.Amount `gorm:"type:decimal(14,2);not null;default:0" json:"installments_remaining"`
+	CreatedAt             time.Time    `json:"created_at"`
+	UpdatedAt             time.Time    `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (StatsSnapshot) TableName() string {
+	return "stats_snapshots"
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// Store is a retailer products are bought from. Products link to it by the
+// host of their source URL.
+type Store struct {
+	ID                    uint          `gorm:"primaryKey" json:"id"`
+	UserID                uint          `gorm:"not null;uniqueIndex:idx_stores_user_domain,priority:1" json:"-"` // Owner
+	Name                  string        `gorm:"size:100;not null" json:"name"`
+	Domain                string        `gorm:"size:255;not null;uniqueIndex:idx_stores_user_domain,priority:2" json:"domain"` // "mercadolibre.com.ar", incluye subdominios
+	DefaultShippingCost   money.Amount  `gorm:"type:decimal(10,2);default:0" json:"default_shipping_cost"`                     // Costo de un envío
+	FreeShippingThreshold *money.Amount `gorm:"type:decimal(10,2)" json:"free_shipping_threshold"`                             // Envío gratis desde este monto; nil = nunca
+	Currency              string        `gorm:"size:3;not null;default:'ARS'" json:"currency"`                                 // La del costo de envío y el umbral
+	TaxProfileID          *uint         `json:"tax_profile_id"`                                                                // Impuestos de sus productos sin perfil propio
+	CreatedAt             time.Time     `json:"created_at"`
+	UpdatedAt             time.Time     `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Store) TableName() string {
+	return "stores"
+}
+
+// Matches reports whether a host (already normalized) is the store's domain or
+// one of its subdomains
+func (s *Store) Matches(host string) bool {
+	return host == s.Domain || strings.HasSuffix(host, "."+s.Domain)
+}
+
+// NormalizeDomain returns the host of a URL or bare domain in lower case,
+// without port, trailing dot or "www.". It returns "" if there is no host.
+func NormalizeDomain(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	return strings.TrimPrefix(host, "www.")
+}
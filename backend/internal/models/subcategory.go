@@ -9,12 +9,15 @@ import (
 // Subcategory represents a sub-category within a main category
 type Subcategory struct {
 	ID         uint           `gorm:"primaryKey" json:"id"`
+	UserID     uint           `gorm:"not null;default:0;index" json:"-"` // Owner
 	CategoryID uint           `gorm:"not null" json:"category_id"`
 	Name       string         `gorm:"size:100;not null" json:"name"`
 	CreatedAt  time.Time      `json:"created_at"`
 	UpdatedAt  time.Time      `json:"updated_at"`
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"` // Soft delete
 
+	Budget `gorm:"embedded"` // Presupuesto opcional (budget_amount, budget_period, budget_currency)
+
 	// Relationships
 	Category *Category `gorm:"foreignKey:CategoryID;constraint:OnDelete:CASCADE" json:"category,omitempty"`
 	Products []Product `gorm:"foreignKey:SubcategoryID" json:"products,omitempty"`
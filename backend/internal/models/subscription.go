@@ -0,0 +1,105 @@
+package models
+
+import "time"
+
+// Subscription statuses
+const (
+	SubscriptionTrial     = "trial"
+	SubscriptionActive    = "active"
+	SubscriptionPaused    = "paused"
+	SubscriptionCancelled = "cancelled"
+)
+
+// IsValidSubscriptionStatus checks if the status is one of the subscription statuses
+func IsValidSubscriptionStatus(status string) bool {
+	switch status {
+	case SubscriptionTrial, SubscriptionActive, SubscriptionPaused, SubscriptionCancelled:
+		return true
+	}
+	return false
+}
+
+// IsSubscription reports whether the product is billed periodically
+func (p *Product) IsSubscription() bool {
+	return p.RecurrenceUnit != nil
+}
+
+// IsActiveSubscription reports whether the subscription is currently being paid
+func (p *Product) IsActiveSubscription() bool {
+	return p.IsSubscription() && p.SubscriptionStatus != nil && *p.SubscriptionStatus == SubscriptionActive
+}
+
+// RollForward brings the subscription up to date as of now: a trial whose end
+// date arrived becomes active, and the next billing date of an active
+// subscription moves to the first billing day on or after today. Paused and
+// cancelled subscriptions have no next billing date. Reports whether anything changed.
+func (p *Product) RollForward(now time.Time) bool {
+	if !p.IsSubscription() || p.SubscriptionStatus == nil {
+		return false
+	}
+
+	today := DateOnly(now)
+	status := *p.SubscriptionStatus
+	if status == SubscriptionTrial && p.TrialEndsAt != nil && !p.TrialEndsAt.After(today) {
+		status = SubscriptionActive
+	}
+
+	var next *time.Time
+	switch status {
+	case SubscriptionTrial:
+		next = p.TrialEndsAt
+	case SubscriptionActive:
+		// Los cobros se cuentan desde el fin de la prueba o, si no hubo, desde el alta
+		anchor := p.StartDate
+		if p.TrialEndsAt != nil {
+			anchor = p.TrialEndsAt
+		}
+		if anchor != nil {
+			date := nextBillingDate(*anchor, today, *p.Recurrence())
+			next = &date
+		}
+	}
+
+	changed := status != *p.SubscriptionStatus || !sameDate(next, p.NextBillingDate)
+	p.SubscriptionStatus = &status
+	p.NextBillingDate = next
+	return changed
+}
+
+// nextBillingDate returns the first date anchor + k periods (k >= 0) that
+// isn't before today. Every date is computed from the anchor, so a subscription
+// started on the 31st bills on the last day of shorter months and goes back to
+// the 31st afterwards.
+func nextBillingDate(anchor, today time.Time, r Recurrence) time.Time {
+	anchor = DateOnly(anchor)
+	date := anchor
+	for k := 1; date.Before(today); k++ {
+		date = r.AddTo(anchor, k)
+	}
+	return date
+}
+
+// addMonthsClamped adds months to a date, keeping the day of month when it
+// exists and using the last day of the month otherwise (Jan 31 + 1 = Feb 28)
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// DateOnly returns the calendar day of t as UTC midnight, which is how date
+// columns are stored, so comparisons don't depend on the server's time zone
+func DateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// Tax rule kinds
+const (
+	TaxRulePercentage = "percentage"
+	TaxRuleFixed      = "fixed"
+)
+
+// Bases a tax rule is applied on
+const (
+	TaxBasePrice         = "price"          // base_price
+	TaxBasePriceShipping = "price_shipping" // base_price + shipping_cost
+	TaxBaseAccumulated   = "accumulated"    // base_price + shipping_cost + los impuestos de las reglas anteriores
+)
+
+// TaxProfile is a named set of tax rules ("Compra en USD", "Courier") applied
+// in order to compute a product's taxes
+type TaxProfile struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_tax_profiles_user_name,priority:1" json:"-"` // Owner
+	Name      string    `gorm:"size:100;not null;uniqueIndex:idx_tax_profiles_user_name,priority:2" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Rules []TaxRule `gorm:"foreignKey:TaxProfileID;constraint:OnDelete:CASCADE" json:"rules"`
+}
+
+// TableName specifies the table name for GORM
+func (TaxProfile) TableName() string {
+	return "tax_profiles"
+}
+
+// TaxRule is one tax of a profile: a percentage of a base or a fixed amount.
+// With a franchise, a percentage only applies to the part of the base above it
+// and a fixed amount only when the base exceeds it.
+type TaxRule struct {
+	ID           uint          `gorm:"primaryKey" json:"id"`
+	TaxProfileID uint          `gorm:"not null;index" json:"-"`
+	Position     int           `gorm:"not null" json:"position"` // Orden de aplicación
+	Name         string        `gorm:"size:100;not null" json:"name"`
+	Kind         string        `gorm:"size:20;not null" json:"kind"`        // "percentage" o "fixed"
+	Rate         float64       `gorm:"type:decimal(7,4)" json:"rate"`       // En %, sólo "percentage"
+	Amount       money.Amount  `gorm:"type:decimal(10,2)" json:"amount"`    // Sólo "fixed"
+	Base         string        `gorm:"size:20;not null" json:"base"`        // "price", "price_shipping" o "accumulated"
+	Franchise    *money.Amount `gorm:"type:decimal(10,2)" json:"franchise"` // Monto exento de la base
+	Currency     string        `gorm:"size:3" json:"currency"`              // La de amount y franchise; vacía = la del producto
+}
+
+// TableName specifies the table name for GORM
+func (TaxRule) TableName() string {
+	return "tax_rules"
+}
+
+// IsValidTaxRuleKind checks if the rule kind is valid
+func IsValidTaxRuleKind(kind string) bool {
+	return kind == TaxRulePercentage || kind == TaxRuleFixed
+}
+
+// IsValidTaxBase checks if the rule base is valid
+func IsValidTaxBase(base string) bool {
+	return base == TaxBasePrice || base == TaxBasePriceShipping || base == TaxBaseAccumulated
+}
+
+// TaxLine is the amount one rule added to a product's taxes
+type TaxLine struct {
+	Name    string       `json:"name"`
+	Kind    string       `json:"kind"`
+	Rate    float64      `json:"rate,omitempty"`
+	Taxable money.Amount `json:"taxable"` // Base sobre la que se aplicó, ya descontada la franquicia
+	Amount  money.Amount `json:"amount"`
+}
+
+// TaxBreakdown is how a product's taxes were computed, stored as JSON so it
+// still shows what was charged after the profile changes
+type TaxBreakdown struct {
+	ProfileID uint      `json:"profile_id"`
+	Profile   string    `json:"profile"`
+	Lines     []TaxLine `json:"lines"`
+}
+
+// Value stores the breakdown as JSON
+func (b TaxBreakdown) Value() (driver.Value, error) {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan reads the breakdown from its JSON column
+func (b *TaxBreakdown) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*b = TaxBreakdown{}
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), b)
+	case []byte:
+		return json.Unmarshal(v, b)
+	default:
+		return fmt.Errorf("cannot scan %T into TaxBreakdown", src)
+	}
+}
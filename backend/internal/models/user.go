@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// User represents an account that owns its own categories, subcategories and products
+type User struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Email        string    `gorm:"size:255;not null;uniqueIndex" json:"email"`
+	Name         string    `gorm:"size:100" json:"name"`
+	PasswordHash string    `gorm:"size:255;not null" json:"-"`             // bcrypt, nunca se devuelve
+	IsAdmin      bool      `gorm:"not null;default:false" json:"is_admin"` // Sólo lo cambia cmd/admin
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (User) TableName() string {
+	return "users"
+}
+
+// RefreshToken is a long-lived token used to obtain new access tokens.
+// Only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsValid reports whether the token can still be used
+func (t *RefreshToken) IsValid(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Domain events, published by the services; the webhooks subscribe to them by name
+const (
+	EventProductCreated   = "product.created"
+	EventProductUpdated   = "product.updated"
+	EventProductPurchased = "product.purchased" // is_purchased pasó a true
+	EventProductDeleted   = "product.deleted"
+	EventPriceChanged     = "price.changed"   // Cambió algún componente del precio
+	EventAlertTriggered   = "alert.triggered" // Lo publica el notifier de webhooks, no el bus
+)
+
+// WebhookEvents lists the events a webhook can subscribe to
+var WebhookEvents = []string{
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductPurchased,
+	EventProductDeleted,
+	EventPriceChanged,
+	EventAlertTriggered,
+}
+
+// Delivery statuses
+const (
+	DeliveryStatusPending = "pending" // Esperando el próximo intento
+	DeliveryStatusSuccess = "success"
+	DeliveryStatusFailed  = "failed" // Se agotaron los intentos
+)
+
+// EventList is a list of event types, stored comma separated
+type EventList []string
+
+// Value stores the list comma separated
+func (l EventList) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+// Scan reads the list from its comma separated column
+func (l *EventList) Scan(src interface{}) error {
+	var value string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into EventList", src)
+	}
+
+	*l = nil
+	for _, event := range strings.Split(value, ",") {
+		if event != "" {
+			*l = append(*l, event)
+		}
+	}
+	return nil
+}
+
+// Webhook is a URL that receives the user's events, signed with its secret
+type Webhook struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"-"` // Owner
+	URL       string    `gorm:"size:500;not null" json:"url"`
+	Secret    string    `gorm:"size:255;not null" json:"-"` // Clave del HMAC; no se devuelve nunca
+	Events    EventList `gorm:"type:text;not null" json:"events"`
+	Active    bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribed tells whether the webhook receives the event
+func (w *Webhook) Subscribed(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent, or to be sent, to a webhook
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	WebhookID      uint       `gorm:"not null;index" json:"webhook_id"`
+	UserID         uint       `gorm:"not null;index" json:"-"` // Owner
+	Event          string     `gorm:"size:50;not null" json:"event"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"` // El JSON firmado que se envía
+	Status         string     `gorm:"size:20;not null;default:'pending'" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  *time.Time `gorm:"index" json:"next_attempt_at"` // nil cuando ya no se reintenta
+	LastAttemptAt  *time.Time `json:"last_attempt_at"`
+	ResponseStatus int        `json:"response_status"` // 0 = sin respuesta
+	LastError      string     `gorm:"size:500" json:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// Relationships
+	Webhook *Webhook `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
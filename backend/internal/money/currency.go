@@ -0,0 +1,21 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NormalizeCurrency validates an ISO 4217 currency code and returns it in
+// upper case ("usd" -> "USD")
+func NormalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", fmt.Errorf("invalid currency code %q", code)
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("invalid currency code %q", code)
+		}
+	}
+	return code, nil
+}
@@ -0,0 +1,298 @@
+// Package money provides an exact decimal amount with two decimals, matching
+// the DECIMAL(10,2) columns. Amounts are stored as integer cents, so sums and
+// multiplications never drift; division and percentages round half away from
+// zero ("redondeo comercial": 0.125 -> 0.13, -0.125 -> -0.13).
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Amount is an amount of money in cents
+type Amount int64
+
+// Zero is the zero amount
+const Zero Amount = 0
+
+// MaxColumn is the largest amount a DECIMAL(10,2) column holds (99999999.99),
+// the type prices are stored in. -MaxColumn is the smallest.
+const MaxColumn Amount = 9999999999
+
+// ErrOutOfRange is returned for an amount that doesn't fit where it goes
+var ErrOutOfRange = errors.New("amount out of range")
+
+// decimalPattern is a plain decimal number: no fractions ("1/3"), hex ("0x10"),
+// exponents nor thousands separators
+var decimalPattern = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+// FromCents builds an amount from integer cents
+func FromCents(cents int64) Amount {
+	return Amount(cents)
+}
+
+// FromFloat converts a float to an amount, rounding to cents. It goes through
+// the shortest decimal representation of f, so 93.49 stays 93.49 instead of
+// picking up binary noise.
+func FromFloat(f float64) Amount {
+	a, err := Parse(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Zero // Inf o NaN
+	}
+	return a
+}
+
+// Parse reads a decimal string ("1234.5", "-0.99", "12") exactly: an
+// optional sign, digits and an optional decimal point with more digits.
+// More than two decimals are rounded half away from zero.
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Zero, errors.New("empty amount")
+	}
+	if !decimalPattern.MatchString(s) {
+		return Zero, fmt.Errorf("invalid amount %q", s)
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Zero, fmt.Errorf("invalid amount %q", s)
+	}
+	cents := roundRat(r.Mul(r, big.NewRat(100, 1)))
+	if !cents.IsInt64() {
+		return Zero, fmt.Errorf("%w: %q", ErrOutOfRange, s)
+	}
+	return Amount(cents.Int64()), nil
+}
+
+// ParseInput is Parse limited to what a DECIMAL(10,2) column holds, for the
+// amounts that come from the API, imports and stores. Sums read from the
+// database can be larger, so Scan uses Parse.
+func ParseInput(s string) (Amount, error) {
+	a, err := Parse(s)
+	if err != nil {
+		return Zero, err
+	}
+	if a > MaxColumn || a < -MaxColumn {
+		return Zero, fmt.Errorf("%w: %q (at most %s)", ErrOutOfRange, strings.TrimSpace(s), MaxColumn)
+	}
+	return a, nil
+}
+
+// MustParse is like Parse but panics on error. Only for constants.
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// FromRat converts an exact value in currency units to an amount, rounding
+// half away from zero. A value beyond the int64 range is clamped to it.
+func FromRat(r *big.Rat) Amount {
+	return clamp(roundRat(new(big.Rat).Mul(r, big.NewRat(100, 1))))
+}
+
+// Cents returns the amount in integer cents
+func (a Amount) Cents() int64 {
+	return int64(a)
+}
+
+// Float64 returns the amount as a float, for ratios and display only
+func (a Amount) Float64() float64 {
+	return float64(a) / 100
+}
+
+// Rat returns the exact value of the amount in currency units (12.30 -> 123/10)
+func (a Amount) Rat() *big.Rat {
+	return big.NewRat(int64(a), 100)
+}
+
+// String formats the amount with exactly two decimals ("-12.30")
+func (a Amount) String() string {
+	sign := ""
+	cents := int64(a)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+	return a + b
+}
+
+// Sub returns a - b
+func (a Amount) Sub(b Amount) Amount {
+	return a - b
+}
+
+// Mul returns a * n
+func (a Amount) Mul(n int64) Amount {
+	return a * Amount(n)
+}
+
+// Div returns a / n rounded half away from zero. It panics if n is zero.
+func (a Amount) Div(n int64) Amount {
+	return a.MulRat(big.NewRat(1, n))
+}
+
+// MulRat returns a * r rounded half away from zero, clamped to the int64 range
+func (a Amount) MulRat(r *big.Rat) Amount {
+	product := new(big.Rat).Mul(big.NewRat(int64(a), 1), r)
+	return clamp(roundRat(product))
+}
+
+// Percent returns pct percent of a ("21" -> 21%), rounded half away from zero.
+// pct is read from its decimal representation, so 10.5 is exactly 10.5%.
+func (a Amount) Percent(pct float64) Amount {
+	r, ok := ratFromFloat(pct)
+	if !ok {
+		return Zero
+	}
+	return a.MulRat(r.Quo(r, big.NewRat(100, 1)))
+}
+
+// Convert returns a multiplied by an exchange rate, rounded half away from zero.
+// Like Percent, rate is read from its decimal representation.
+func (a Amount) Convert(rate float64) Amount {
+	r, ok := ratFromFloat(rate)
+	if !ok {
+		return Zero
+	}
+	return a.MulRat(r)
+}
+
+// ConvertInverse divides a by an exchange rate (the rate of the opposite
+// direction), rounded half away from zero
+func (a Amount) ConvertInverse(rate float64) Amount {
+	r, ok := ratFromFloat(rate)
+	if !ok || r.Sign() == 0 {
+		return Zero
+	}
+	return a.MulRat(r.Inv(r))
+}
+
+// IsZero reports whether the amount is zero
+func (a Amount) IsZero() bool {
+	return a == 0
+}
+
+// IsPositive reports whether the amount is above zero
+func (a Amount) IsPositive() bool {
+	return a > 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (a Amount) IsNegative() bool {
+	return a < 0
+}
+
+// Sum adds up amounts
+func Sum(amounts ...Amount) Amount {
+	var total Amount
+	for _, a := range amounts {
+		total += a
+	}
+	return total
+}
+
+// roundRat rounds a rational to the nearest integer, half away from zero
+func roundRat(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q
+}
+
+// clamp converts cents to an amount, saturating at the int64 limits instead
+// of wrapping around
+func clamp(cents *big.Int) Amount {
+	switch {
+	case cents.IsInt64():
+		return Amount(cents.Int64())
+	case cents.Sign() > 0:
+		return Amount(math.MaxInt64)
+	default:
+		return Amount(math.MinInt64)
+	}
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimals (93.49)
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string, read exactly with
+// ParseInput
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	parsed, err := ParseInput(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Value stores the amount as a decimal string, which PostgreSQL reads exactly
+// into NUMERIC and SQLite converts through the column's numeric affinity
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan reads NUMERIC (string/[]byte from PostgreSQL) and REAL/INTEGER (SQLite)
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = Zero
+	case int64:
+		*a = Amount(v * 100)
+	case float64:
+		*a = FromFloat(v)
+	case []byte:
+		return a.scanString(string(v))
+	case string:
+		return a.scanString(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	return nil
+}
+
+func (a *Amount) scanString(s string) error {
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// ratFromFloat reads a float through its shortest decimal representation (10.5 -> 21/2)
+func ratFromFloat(f float64) (*big.Rat, bool) {
+	return new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+}
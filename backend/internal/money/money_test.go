@@ -0,0 +1,268 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Amount
+	}{
+		{"1234.5", 123450},
+		{"-0.99", -99},
+		{"12", 1200},
+		{" 3.10 ", 310},
+		{"0.124", 12},
+		// Redondeo comercial: la mitad se aleja del cero
+		{"0.125", 13},
+		{"-0.125", -13},
+		{"0.135", 14},
+		{"0.1249999", 12},
+		{"+5", 500},
+		{"7.", 700},
+		{".5", 50},
+		{"99999999999.99", 9999999999999}, // Parse no se limita a la columna
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %d cents, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, value := range []string{
+		"", "  ", "abc", "1,5", "1e30", "1e2", "1/3", "0x10", "0b1", "1_000", ".", "-", "1.2.3", "Inf", "NaN",
+		"92233720368547758.08", // Más de int64 centavos
+	} {
+		if got, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) = %v, want an error", value, got)
+		}
+	}
+}
+
+func TestParseInput(t *testing.T) {
+	for value, want := range map[string]Amount{"99999999.99": MaxColumn, "-99999999.99": -MaxColumn, "0.5": 50} {
+		if got, err := ParseInput(value); err != nil || got != want {
+			t.Errorf("ParseInput(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"100000000.00", "-100000000", "1/3"} {
+		if got, err := ParseInput(value); err == nil {
+			t.Errorf("ParseInput(%q) = %v, want an error", value, got)
+		}
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  Amount
+	}{
+		{93.49, 9349},
+		{0.1 + 0.2, 30}, // 0.30000000000000004
+		{1.005, 101},    // Se lee "1.005", no 1.00499999...
+		{-2.675, -268},
+		{0, 0},
+		{math.NaN(), 0},
+		{math.Inf(1), 0},
+	}
+
+	for _, tt := range tests {
+		if got := FromFloat(tt.value); got != tt.want {
+			t.Errorf("FromFloat(%v) = %d cents, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+	}{
+		{1230, "12.30"},
+		{-1230, "-12.30"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{0, "0.00"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Amount
+		want Amount
+	}{
+		{"div exact", MustParse("10.00").Div(4), 250},
+		{"div rounds down", MustParse("1.00").Div(3), 33},
+		{"div rounds up", MustParse("2.00").Div(3), 67},
+		{"div half", FromCents(5).Div(2), 3},
+		{"div negative half", FromCents(-5).Div(2), -3},
+		{"mul rat", MustParse("100.00").MulRat(big.NewRat(2, 3)), 6667},
+		{"mul rat negative", MustParse("-100.00").MulRat(big.NewRat(2, 3)), -6667},
+		{"from rat half", FromRat(big.NewRat(1, 8)), 13},
+		{"from rat negative half", FromRat(big.NewRat(-1, 8)), -13},
+		{"mul rat clamps", FromCents(math.MaxInt64 / 2).MulRat(big.NewRat(3, 1)), math.MaxInt64},
+		{"mul rat clamps negative", FromCents(math.MaxInt64 / 2).MulRat(big.NewRat(-3, 1)), math.MinInt64},
+		{"from rat clamps", FromRat(new(big.Rat).SetInt64(math.MaxInt64)), math.MaxInt64},
+		{"convert", MustParse("100.00").Convert(1050.5), 10505000},
+		{"convert inverse", MustParse("105050.00").ConvertInverse(1050.5), 10000},
+		{"convert inverse rounds", MustParse("1.00").ConvertInverse(3), 33},
+		{"convert inverse zero rate", MustParse("1.00").ConvertInverse(0), 0},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %d cents, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		amount string
+		pct    float64
+		want   string
+	}{
+		{"1000.00", 21, "210.00"},
+		{"100.00", 10.5, "10.50"}, // 10.5 exacto, no su aproximación binaria
+		{"0.50", 21, "0.11"},      // 0.105
+		{"-0.50", 21, "-0.11"},
+		{"0.01", 12.5, "0.00"}, // 0.00125
+		{"80.00", 2.5, "2.00"},
+		{"33.33", 100, "33.33"},
+		{"100.00", 0, "0.00"},
+		{"100.00", math.NaN(), "0.00"},
+	}
+
+	for _, tt := range tests {
+		got := MustParse(tt.amount).Percent(tt.pct)
+		if want := MustParse(tt.want); got != want {
+			t.Errorf("%s.Percent(%v) = %s, want %s", tt.amount, tt.pct, got, want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type item struct {
+		Price Amount  `json:"price"`
+		Cost  *Amount `json:"cost"`
+	}
+
+	data, err := json.Marshal(item{Price: 9349})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"price":93.49,"cost":null}`; got != want {
+		t.Errorf("Marshal = %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		body string
+		want Amount
+	}{
+		{`{"price": 93.49}`, 9349},
+		{`{"price": "93.49"}`, 9349},
+		{`{"price": 0.125}`, 13},
+		{`{"price": 12}`, 1200},
+		{`{"price": -1.5}`, -150},
+		{`{"price": 99999999.99}`, MaxColumn},
+	}
+	for _, tt := range tests {
+		var got item
+		if err := json.Unmarshal([]byte(tt.body), &got); err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.body, err)
+			continue
+		}
+		if got.Price != tt.want {
+			t.Errorf("Unmarshal(%s) = %d cents, want %d", tt.body, got.Price, tt.want)
+		}
+	}
+
+	// null deja el valor como estaba
+	got := item{Price: 100}
+	if err := json.Unmarshal([]byte(`{"price": null}`), &got); err != nil || got.Price != 100 {
+		t.Errorf("Unmarshal(null) = %d, %v; want 100 unchanged", got.Price, err)
+	}
+
+	for _, body := range []string{
+		`{"price": "abc"}`, `{"price": true}`, `{"price": "1e30"}`, `{"price": 1e2}`,
+		`{"price": "1/3"}`, `{"price": "0x10"}`, `{"price": 100000000}`,
+	} {
+		var got item
+		if err := json.Unmarshal([]byte(body), &got); err == nil {
+			t.Errorf("Unmarshal(%s) = %d, want an error", body, got.Price)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  interface{}
+		want Amount
+	}{
+		{"nil", nil, 0},
+		{"integer (SQLite)", int64(12), 1200},
+		{"real (SQLite)", 93.49, 9349},
+		{"numeric (PostgreSQL)", []byte("1234.56"), 123456},
+		{"string", "0.10", 10},
+		{"negative", []byte("-7.05"), -705},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Amount(999)
+			if err := got.Scan(tt.src); err != nil {
+				t.Fatalf("Scan(%v): %v", tt.src, err)
+			}
+			if got != tt.want {
+				t.Errorf("Scan(%v) = %d cents, want %d", tt.src, got, tt.want)
+			}
+		})
+	}
+
+	var a Amount
+	if err := a.Scan(true); err == nil {
+		t.Error("Scan(bool) = nil error, want an error")
+	}
+	if err := a.Scan("x"); err == nil {
+		t.Error("Scan(\"x\") = nil error, want an error")
+	}
+}
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+	}{
+		{1230, "12.30"},
+		{-5, "-0.05"},
+		{0, "0.00"},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.amount.Value()
+		if err != nil || got != tt.want {
+			t.Errorf("Amount(%d).Value() = %v, %v; want %q", tt.amount, got, err, tt.want)
+		}
+	}
+}
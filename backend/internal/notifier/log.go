@@ -0,0 +1,22 @@
+package notifier
+
+import (
+	"context"
+	"log"
+
+	"github.com/buylist-manager/backend/internal/models"
+)
+
+// logNotifier writes alerts to the application log
+type logNotifier struct{}
+
+// NewLogNotifier creates a notifier that only logs alerts
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+// Notify logs the alert message
+func (n *logNotifier) Notify(ctx context.Context, alert *models.Alert) error {
+	log.Printf("🔔 %s: %s", subject(alert), alert.Message)
+	return nil
+}
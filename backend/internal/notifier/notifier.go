@@ -0,0 +1,90 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/buylist-manager/backend/internal/config"
+	"github.com/buylist-manager/backend/internal/models"
+)
+
+// Notifier delivers triggered price alerts to the user that owns them. The
+// alert comes with its Product and its User.
+type Notifier interface {
+	Notify(ctx context.Context, alert *models.Alert) error
+}
+
+// WebhookPublisher stores an event for the user's webhooks subscribed to it
+// and sends it. The webhooks service implements it.
+type WebhookPublisher interface {
+	Publish(userID uint, event string, data interface{}) error
+	DeliverPending()
+}
+
+// New builds the notifier configured in ALERT_NOTIFIERS.
+// Several notifiers can be combined with commas, e.g. "log,webhook".
+// The deployment has several users, so every notifier but log delivers to the
+// alert's owner: never to an address set for the whole deployment.
+func New(cfg *config.Config, webhooks WebhookPublisher) (Notifier, error) {
+	var notifiers multiNotifier
+
+	for _, name := range strings.Split(cfg.AlertNotifiers, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+			continue
+		case "log":
+			notifiers = append(notifiers, NewLogNotifier())
+		case "webhook":
+			notifiers = append(notifiers, NewWebhookNotifier(webhooks))
+		case "smtp":
+			if cfg.SMTPHost == "" || cfg.SMTPFrom == "" {
+				return nil, errors.New("smtp notifier requires SMTP_HOST and SMTP_FROM")
+			}
+			notifiers = append(notifiers, NewSMTPNotifier(
+				cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPFrom,
+			))
+		default:
+			return nil, fmt.Errorf("unknown alert notifier: %s", name)
+		}
+	}
+
+	if len(notifiers) == 1 {
+		return notifiers[0], nil
+	}
+	return notifiers, nil
+}
+
+// multiNotifier fans out an alert to several notifiers
+type multiNotifier []Notifier
+
+// Notify delivers the alert through every notifier and joins their errors
+func (m multiNotifier) Notify(ctx context.Context, alert *models.Alert) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, alert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// subject returns a short human readable title for an alert. The product name
+// is the user's: line breaks are replaced so it stays a single line.
+func subject(alert *models.Alert) string {
+	if alert.Product != nil {
+		return fmt.Sprintf("[BuyList] Price alert: %s", singleLine(alert.Product.Name))
+	}
+	return fmt.Sprintf("[BuyList] Price alert for product #%d", alert.ProductID)
+}
+
+// singleLine replaces the line breaks of s with spaces
+func singleLine(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' {
+			return ' '
+		}
+		return r
+	}, s)
+}
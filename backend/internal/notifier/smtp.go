@@ -0,0 +1,61 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+
+	"github.com/buylist-manager/backend/internal/models"
+)
+
+// smtpNotifier sends alerts by email to their owner's account address
+type smtpNotifier struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPNotifier creates a notifier that emails every alert to its owner
+func NewSMTPNotifier(host, port, username, password, from string) Notifier {
+	return &smtpNotifier{
+		addr:     net.JoinHostPort(host, port),
+		host:     host,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+// Notify sends a plain text email with the alert message
+func (n *smtpNotifier) Notify(ctx context.Context, alert *models.Alert) error {
+	if alert.User == nil || alert.User.Email == "" {
+		return fmt.Errorf("alert %d has no owner to email", alert.ID)
+	}
+	to := alert.User.Email
+
+	var auth smtp.Auth
+	if n.username != "" {
+		auth = smtp.PlainAuth("", n.username, n.password, n.host)
+	}
+
+	msg := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		singleLine(n.from), singleLine(to), headerValue(subject(alert)), alert.Message,
+	)
+
+	if err := smtp.SendMail(n.addr, auth, n.from, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("smtp send failed: %w", err)
+	}
+	return nil
+}
+
+// headerValue makes a text safe to write as the value of a message header:
+// without line breaks, which would start a new header, and Q-encoded if it
+// isn't plain ASCII. The addresses only need singleLine.
+func headerValue(s string) string {
+	return mime.QEncoding.Encode("UTF-8", singleLine(s))
+}
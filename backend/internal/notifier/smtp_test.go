@@ -0,0 +1,24 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/models"
+)
+
+func TestSubjectHeaderHasNoLineBreaks(t *testing.T) {
+	alert := &models.Alert{Product: &models.Product{Name: "Teclado\r\nBcc: todos@example.com"}}
+
+	for _, header := range []string{subject(alert), headerValue(subject(alert))} {
+		if strings.ContainsAny(header, "\r\n") {
+			t.Errorf("header %q has a line break", header)
+		}
+	}
+	if got := headerValue("Teclado mecánico"); !strings.HasPrefix(got, "=?UTF-8?q?") {
+		t.Errorf("headerValue(non-ASCII) = %q, want it Q-encoded", got)
+	}
+	if got := headerValue("Teclado"); got != "Teclado" {
+		t.Errorf("headerValue(ASCII) = %q, want it unchanged", got)
+	}
+}
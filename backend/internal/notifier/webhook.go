@@ -0,0 +1,37 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/buylist-manager/backend/internal/models"
+)
+
+// webhookNotifier sends alerts to the webhooks their owner registered for
+// alert.triggered, with the same signature and retries as the other events
+type webhookNotifier struct {
+	webhooks WebhookPublisher
+}
+
+// NewWebhookNotifier creates a notifier that publishes every alert to its
+// owner's webhooks
+func NewWebhookNotifier(webhooks WebhookPublisher) Notifier {
+	return &webhookNotifier{webhooks: webhooks}
+}
+
+// Notify stores a delivery for each subscribed webhook and starts sending
+// them. A failed send is retried by the webhooks, not reported here.
+func (n *webhookNotifier) Notify(ctx context.Context, alert *models.Alert) error {
+	if err := n.webhooks.Publish(alert.UserID, models.EventAlertTriggered, webhookPayload(alert)); err != nil {
+		return err
+	}
+	n.webhooks.DeliverPending()
+	return nil
+}
+
+// webhookPayload is the data of the alert.triggered event
+func webhookPayload(alert *models.Alert) map[string]interface{} {
+	return map[string]interface{}{
+		"subject": subject(alert),
+		"alert":   alert,
+	}
+}
@@ -0,0 +1,45 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/models"
+)
+
+// fakePublisher records what the notifier publishes
+type fakePublisher struct {
+	userIDs   []uint
+	events    []string
+	delivered int
+}
+
+func (p *fakePublisher) Publish(userID uint, event string, data interface{}) error {
+	p.userIDs = append(p.userIDs, userID)
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *fakePublisher) DeliverPending() { p.delivered++ }
+
+func TestWebhookNotifierPublishesToTheOwner(t *testing.T) {
+	webhooks := &fakePublisher{}
+	alert := &models.Alert{ID: 1, UserID: 7, Product: &models.Product{Name: "Teclado"}}
+
+	if err := NewWebhookNotifier(webhooks).Notify(context.Background(), alert); err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks.userIDs) != 1 || webhooks.userIDs[0] != 7 || webhooks.events[0] != models.EventAlertTriggered {
+		t.Errorf("published to users %v events %v, want user 7 alert.triggered", webhooks.userIDs, webhooks.events)
+	}
+	if webhooks.delivered != 1 {
+		t.Errorf("DeliverPending called %d times, want 1", webhooks.delivered)
+	}
+}
+
+func TestSMTPNotifierNeedsTheOwner(t *testing.T) {
+	n := NewSMTPNotifier("localhost", "25", "", "", "alerts@example.com")
+	if err := n.Notify(context.Background(), &models.Alert{ID: 1, UserID: 7}); err == nil {
+		t.Error("Notify without the owner succeeded, want an error")
+	}
+}
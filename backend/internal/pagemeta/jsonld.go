@@ -0,0 +1,142 @@
+package pagemeta
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// jsonLDProduct returns the fields of the first schema.org Product found in
+// the page's JSON-LD blocks. Blocks that aren't valid JSON are skipped.
+func jsonLDProduct(blocks []string) fields {
+	for _, block := range blocks {
+		block = strings.TrimSpace(block)
+		block = strings.TrimSuffix(strings.TrimPrefix(block, "<![CDATA["), "]]>")
+
+		decoder := json.NewDecoder(strings.NewReader(block))
+		decoder.UseNumber() // Los precios quedan como vienen, sin pasar por float
+		var data interface{}
+		if err := decoder.Decode(&data); err != nil {
+			continue
+		}
+		if product := findProduct(data); product != nil {
+			return productFields(product)
+		}
+	}
+	return fields{}
+}
+
+// findProduct walks a JSON-LD document (a node, a list or an @graph) looking
+// for a Product node
+func findProduct(data interface{}) map[string]interface{} {
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if product := findProduct(item); product != nil {
+				return product
+			}
+		}
+	case map[string]interface{}:
+		if hasType(v, "Product", "ProductGroup", "IndividualProduct") {
+			return v
+		}
+		for _, key := range []string{"@graph", "mainEntity", "itemOffered"} {
+			if product := findProduct(v[key]); product != nil {
+				return product
+			}
+		}
+	}
+	return nil
+}
+
+// hasType reports whether the node's @type (a string or a list) is one of types
+func hasType(node map[string]interface{}, types ...string) bool {
+	var values []interface{}
+	switch t := node["@type"].(type) {
+	case string:
+		values = []interface{}{t}
+	case []interface{}:
+		values = t
+	}
+	for _, value := range values {
+		name, _ := value.(string)
+		name = name[strings.LastIndex(name, "/")+1:] // "http://schema.org/Product"
+		for _, want := range types {
+			if strings.EqualFold(name, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// productFields reads a Product node. The price is the one of its first offer
+// that has one.
+func productFields(product map[string]interface{}) fields {
+	f := fields{
+		name:        text(product["name"]),
+		description: text(product["description"]),
+		image:       image(product["image"]),
+	}
+	f.price, f.currency = offerPrice(product["offers"])
+	return f
+}
+
+// offerPrice returns the price and currency of an Offer, an AggregateOffer
+// (its lowPrice) or the first priced offer of a list
+func offerPrice(data interface{}) (string, string) {
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if price, currency := offerPrice(item); price != "" {
+				return price, currency
+			}
+		}
+	case map[string]interface{}:
+		currency := text(v["priceCurrency"])
+		for _, key := range []string{"price", "lowPrice"} {
+			if price := text(v[key]); price != "" {
+				return price, currency
+			}
+		}
+		if price, specCurrency := offerPrice(v["priceSpecification"]); price != "" {
+			if specCurrency != "" {
+				currency = specCurrency
+			}
+			return price, currency
+		}
+		return offerPrice(v["offers"])
+	}
+	return "", ""
+}
+
+// image returns the URL of an image given as a string, an ImageObject or a list
+func image(data interface{}) string {
+	switch v := data.(type) {
+	case string:
+		return v
+	case []interface{}:
+		for _, item := range v {
+			if u := image(item); u != "" {
+				return u
+			}
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"url", "contentUrl"} {
+			if u := text(v[key]); u != "" {
+				return u
+			}
+		}
+	}
+	return ""
+}
+
+// text returns a string or number value as text
+func text(data interface{}) string {
+	switch v := data.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
@@ -0,0 +1,253 @@
+// Package pagemeta extracts product data from the HTML of a web page:
+// schema.org Product JSON-LD, OpenGraph and common meta tags. It never fetches
+// anything, so the HTML a bookmarklet posts and a page saved to disk give the
+// same result.
+package pagemeta
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// Where a field was found, from the most to the least reliable
+const (
+	SourceJSONLD    = "json-ld"
+	SourceOpenGraph = "opengraph"
+	SourceMicrodata = "microdata"
+	SourceMeta      = "meta"
+	SourceTitle     = "title"
+)
+
+// Metadata is what could be extracted from a page. Empty fields weren't found.
+type Metadata struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Price       *money.Amount `json:"price"`
+	Currency    string        `json:"currency"` // Tal como lo declara la página, en mayúsculas
+	Image       string        `json:"image"`    // URL absoluta
+	URL         string        `json:"url"`      // Canónica, si la página la declara
+	SiteName    string        `json:"site_name"`
+
+	// Campo -> fuente de la que salió ("json-ld", "opengraph", ...)
+	Sources map[string]string `json:"sources"`
+}
+
+// fields is one source's candidate values; price is still unparsed
+type fields struct {
+	name, description, price, currency, image string
+}
+
+var (
+	scriptPattern   = regexp.MustCompile(`(?is)<script\b([^>]*)>(.*?)</script\s*>`)
+	stylePattern    = regexp.MustCompile(`(?is)<style\b[^>]*>.*?</style\s*>|<!--.*?-->`)
+	tagPattern      = regexp.MustCompile(`(?is)<([a-z][a-z0-9]*)\b((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+	attrPattern     = regexp.MustCompile(`(?is)([a-z_:.-]+)(?:\s*=\s*("[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?`)
+	titlePattern    = regexp.MustCompile(`(?is)<title\b[^>]*>(.*?)</title\s*>`)
+	itemTextPattern = regexp.MustCompile(`(?is)<[a-z][a-z0-9]*\s[^>]*?\bitemprop\s*=\s*["']?(name|description|price)\b[^>]*>([^<]*)`)
+	markupPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// maxDescription caps the description, which some pages fill with the whole product sheet
+const maxDescription = 2000
+
+// Extract reads the product data of a page. pageURL resolves relative image
+// URLs; it can be empty.
+func Extract(pageURL, page string) *Metadata {
+	base, _ := url.Parse(pageURL)
+
+	// Los scripts y estilos se sacan antes de mirar los tags: pueden contener "<meta"
+	var ldBlocks []string
+	page = scriptPattern.ReplaceAllStringFunc(page, func(script string) string {
+		m := scriptPattern.FindStringSubmatch(script)
+		if strings.EqualFold(attributes(m[1])["type"], "application/ld+json") {
+			ldBlocks = append(ldBlocks, m[2])
+		}
+		return ""
+	})
+	page = stylePattern.ReplaceAllString(page, "")
+
+	meta := &Metadata{Sources: make(map[string]string)}
+	var og, micro, other, title fields
+	readTags(page, meta, &og, &micro, &other)
+	if m := titlePattern.FindStringSubmatch(page); m != nil {
+		title.name = m[1]
+	}
+
+	layers := []struct {
+		source string
+		fields fields
+	}{
+		{SourceJSONLD, jsonLDProduct(ldBlocks)},
+		{SourceOpenGraph, og},
+		{SourceMicrodata, micro},
+		{SourceMeta, other},
+		{SourceTitle, title},
+	}
+	for _, layer := range layers {
+		f := layer.fields
+		if meta.Name == "" {
+			meta.Name = set(meta, "name", layer.source, cleanText(f.name))
+		}
+		if meta.Description == "" {
+			meta.Description = set(meta, "description", layer.source, truncate(cleanText(f.description), maxDescription))
+		}
+		if meta.Price == nil {
+			if price, ok := parsePrice(cleanText(f.price)); ok {
+				meta.Price = &price
+				meta.Sources["price"] = layer.source
+			}
+		}
+		if meta.Currency == "" {
+			meta.Currency = set(meta, "currency", layer.source, strings.ToUpper(cleanText(f.currency)))
+		}
+		if meta.Image == "" {
+			meta.Image = set(meta, "image", layer.source, resolve(base, cleanText(f.image)))
+		}
+	}
+	meta.URL = resolve(base, meta.URL)
+	return meta
+}
+
+// set records the source of a field that was found and returns its value
+func set(meta *Metadata, field, source, value string) string {
+	if value != "" {
+		meta.Sources[field] = source
+	}
+	return value
+}
+
+// readTags collects the OpenGraph, microdata and plain meta values of the page
+func readTags(page string, meta *Metadata, og, micro, other *fields) {
+	for _, m := range tagPattern.FindAllStringSubmatch(page, -1) {
+		tag := strings.ToLower(m[1])
+		attrs := attributes(m[2])
+		content := attrs["content"]
+
+		if prop := attrs["itemprop"]; prop != "" {
+			value := content
+			if value == "" && (tag == "img" || tag == "link") {
+				value = attrs["src"] + attrs["href"]
+			}
+			switch prop {
+			case "name":
+				first(&micro.name, value)
+			case "description":
+				first(&micro.description, value)
+			case "price", "lowPrice":
+				first(&micro.price, value)
+			case "priceCurrency":
+				first(&micro.currency, value)
+			case "image":
+				first(&micro.image, value)
+			}
+		}
+
+		switch tag {
+		case "link":
+			if strings.EqualFold(attrs["rel"], "canonical") {
+				first(&meta.URL, attrs["href"])
+			}
+		case "meta":
+			key := strings.ToLower(attrs["property"])
+			if key == "" {
+				key = strings.ToLower(attrs["name"])
+			}
+			switch key {
+			case "og:title":
+				first(&og.name, content)
+			case "og:description":
+				first(&og.description, content)
+			case "og:image", "og:image:url", "og:image:secure_url":
+				first(&og.image, content)
+			case "product:price:amount", "og:price:amount", "product:sale_price:amount":
+				first(&og.price, content)
+			case "product:price:currency", "og:price:currency", "product:sale_price:currency":
+				first(&og.currency, content)
+			case "og:url":
+				first(&meta.URL, content)
+			case "og:site_name":
+				first(&meta.SiteName, cleanText(content))
+			case "twitter:title":
+				first(&other.name, content)
+			case "description", "twitter:description":
+				first(&other.description, content)
+			case "twitter:image", "twitter:image:src":
+				first(&other.image, content)
+			}
+		}
+	}
+
+	// Sin content, el valor de microdata es el texto del tag
+	for _, m := range itemTextPattern.FindAllStringSubmatch(page, -1) {
+		switch m[1] {
+		case "name":
+			first(&micro.name, m[2])
+		case "description":
+			first(&micro.description, m[2])
+		case "price":
+			first(&micro.price, m[2])
+		}
+	}
+}
+
+// first keeps the first non-blank value
+func first(dst *string, value string) {
+	if *dst == "" && strings.TrimSpace(value) != "" {
+		*dst = value
+	}
+}
+
+// attributes parses the attributes of a tag. Names are lowercased and values
+// unquoted, but still escaped.
+func attributes(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range attrPattern.FindAllStringSubmatch(s, -1) {
+		name := strings.ToLower(m[1])
+		if _, ok := attrs[name]; ok {
+			continue
+		}
+		value := m[2]
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+		attrs[name] = strings.TrimSpace(value)
+	}
+	return attrs
+}
+
+// cleanText unescapes HTML entities, drops any markup and collapses whitespace
+func cleanText(s string) string {
+	s = html.UnescapeString(markupPattern.ReplaceAllString(html.UnescapeString(s), " "))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncate cuts s to at most max characters
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:max-1])) + "…"
+}
+
+// resolve makes a URL absolute against the page URL. Only http(s) URLs are kept.
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,157 @@
+package pagemeta
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+func TestExtractFixtures(t *testing.T) {
+	tests := []struct {
+		file     string
+		pageURL  string
+		want     Metadata
+		price    string
+		wantFrom map[string]string
+	}{
+		{
+			file:    "jsonld.html",
+			pageURL: "https://tienda.example.com/p/1",
+			want: Metadata{
+				Name:        "Auriculares Inalámbricos Sony WH-1000XM5 & Estuche",
+				Description: "Cancelación de ruido líder. 30 horas de batería.",
+				Currency:    "ARS",
+				Image:       "https://tienda.example.com/img/sony-1.jpg",
+				URL:         "https://tienda.example.com/p/sony-wh-1000xm5",
+			},
+			price: "489999.90",
+			wantFrom: map[string]string{
+				"name": SourceJSONLD, "description": SourceJSONLD, "price": SourceJSONLD,
+				"currency": SourceJSONLD, "image": SourceJSONLD,
+			},
+		},
+		{
+			file:    "opengraph.html",
+			pageURL: "https://www.keychron.example/products/k2",
+			want: Metadata{
+				Name:        "Keychron K2 Wireless Mechanical Keyboard",
+				Description: "A 75% layout Bluetooth mechanical keyboard for Mac and Windows.",
+				Currency:    "USD",
+				Image:       "https://cdn.example.com/k2.png",
+				URL:         "https://www.keychron.example/products/k2",
+				SiteName:    "Keychron",
+			},
+			price: "89.00",
+			wantFrom: map[string]string{
+				"name": SourceOpenGraph, "description": SourceOpenGraph, "price": SourceOpenGraph,
+				"currency": SourceOpenGraph, "image": SourceOpenGraph,
+			},
+		},
+		{
+			file:    "microdata.html",
+			pageURL: "",
+			want: Metadata{
+				Name:        "Cafetera Italiana Bialetti Moka Express 6 Tazas",
+				Description: "Cafetera moka de aluminio, 6 tazas.",
+				Currency:    "ARS",
+				Image:       "https://cdn.example.com/bialetti.jpg",
+			},
+			price: "45999.50",
+			wantFrom: map[string]string{
+				"name": SourceMicrodata, "description": SourceMeta, "price": SourceMicrodata,
+				"currency": SourceMicrodata, "image": SourceMeta,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			page, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := Extract(tt.pageURL, string(page))
+
+			if got.Name != tt.want.Name {
+				t.Errorf("Name = %q, want %q", got.Name, tt.want.Name)
+			}
+			if got.Description != tt.want.Description {
+				t.Errorf("Description = %q, want %q", got.Description, tt.want.Description)
+			}
+			if got.Price == nil || *got.Price != money.MustParse(tt.price) {
+				t.Errorf("Price = %v, want %s", got.Price, tt.price)
+			}
+			if got.Currency != tt.want.Currency {
+				t.Errorf("Currency = %q, want %q", got.Currency, tt.want.Currency)
+			}
+			if got.Image != tt.want.Image {
+				t.Errorf("Image = %q, want %q", got.Image, tt.want.Image)
+			}
+			if got.URL != tt.want.URL {
+				t.Errorf("URL = %q, want %q", got.URL, tt.want.URL)
+			}
+			if got.SiteName != tt.want.SiteName {
+				t.Errorf("SiteName = %q, want %q", got.SiteName, tt.want.SiteName)
+			}
+			for field, source := range tt.wantFrom {
+				if got.Sources[field] != source {
+					t.Errorf("Sources[%q] = %q, want %q", field, got.Sources[field], source)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractNegativeJSONLDPrice(t *testing.T) {
+	page := `<script type="application/ld+json">
+	{"@type": "Product", "name": "Gift card", "offers": {"price": -10, "priceCurrency": "USD"}}
+	</script>`
+
+	got := Extract("", page)
+	if got.Price != nil {
+		t.Errorf("Price = %v, want not found", *got.Price)
+	}
+	if _, ok := got.Sources["price"]; ok {
+		t.Errorf("Sources[price] = %q, want none", got.Sources["price"])
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string // "" = no encontrado
+	}{
+		{"1234.56", "1234.56"},
+		{"$ 1.234,56", "1234.56"},
+		{"1,234.56", "1234.56"},
+		{"1.299.999", "1299999"},
+		{"1.299", "1299"},
+		{"12,5", "12.50"},
+		{"US$ 89.00", "89.00"},
+		{"0", ""},
+		{"0,00", ""},
+		{"", ""},
+		{"gratis", ""},
+		{"-10", ""},
+		{"$ -10,50", ""},
+		{"-$10", ""},
+		{"−10", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parsePrice(tt.value)
+			if tt.want == "" {
+				if ok {
+					t.Errorf("parsePrice(%q) = %v, want not found", tt.value, got)
+				}
+				return
+			}
+			if !ok || got != money.MustParse(tt.want) {
+				t.Errorf("parsePrice(%q) = %v, %v; want %s", tt.value, got, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package pagemeta
+
+import (
+	"strings"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// parsePrice reads a price as pages write it: "1234.56", "$ 1.234,56",
+// "1,234.56" or "1.299.999". The last separator is the decimal one, unless it
+// repeats or is followed by exactly three digits: then it separates thousands.
+// Zero and negative prices ("-10", "$ -10", a negative JSON-LD number) count
+// as not found.
+func parsePrice(value string) (money.Amount, bool) {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case (r >= '0' && r <= '9') || r == '.' || r == ',':
+			b.WriteRune(r)
+		case (r == '-' || r == '−') && b.Len() == 0:
+			// Un signo antes del número: se descarta antes de perderlo al limpiar
+			return money.Zero, false
+		}
+	}
+	number := strings.Trim(b.String(), ".,")
+	if number == "" {
+		return money.Zero, false
+	}
+
+	last := strings.LastIndexAny(number, ".,")
+	if last >= 0 {
+		sep := number[last : last+1]
+		decimals := len(number) - last - 1
+		integer := strings.NewReplacer(".", "", ",", "").Replace(number[:last])
+		if strings.Count(number, sep) > 1 || decimals == 3 {
+			number = integer + number[last+1:]
+		} else {
+			number = integer + "." + number[last+1:]
+		}
+	}
+
+	amount, err := money.ParseInput(number)
+	if err != nil || !amount.IsPositive() {
+		return money.Zero, false
+	}
+	return amount, true
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// mercadoLibreDomains are the sites of MercadoLibre in each country
+var mercadoLibreDomains = []string{
+	"mercadolibre.com.ar", "mercadolibre.com.mx", "mercadolivre.com.br",
+	"mercadolibre.cl", "mercadolibre.com.co", "mercadolibre.com.uy",
+	"mercadolibre.com.pe", "mercadolibre.com.ve", "mercadolibre.com.ec",
+}
+
+var (
+	// El id de una publicación: sitio (MLA, MLB, ...) y número, con o sin guión
+	mercadoLibreItemPattern = regexp.MustCompile(`(?i)\b(M[A-Z]{2})-?(\d{6,})`)
+	// Las páginas de catálogo (/p/MLA...) traen la publicación en item_id o wid
+	mercadoLibreQueryPattern = regexp.MustCompile(`(?i)\b(?:item_id|wid)[:=](M[A-Z]{2})-?(\d{6,})`)
+)
+
+// mercadoLibreProvider prices MercadoLibre listings with the items API
+type mercadoLibreProvider struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewMercadoLibreProvider creates a provider that queries the items API at
+// baseURL. The access token is optional.
+func NewMercadoLibreProvider(baseURL, token string) PriceProvider {
+	return &mercadoLibreProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name identifies the provider in the quotes
+func (p *mercadoLibreProvider) Name() string {
+	return "mercadolibre"
+}
+
+// mercadoLibreItem is the part of GET /items/:id the provider uses
+type mercadoLibreItem struct {
+	ID                string      `json:"id"`
+	Title             string      `json:"title"`
+	Price             json.Number `json:"price"`
+	CurrencyID        string      `json:"currency_id"`
+	AvailableQuantity int         `json:"available_quantity"`
+	Status            string      `json:"status"` // "active", "paused", "closed", ...
+	Shipping          struct {
+		FreeShipping bool `json:"free_shipping"`
+	} `json:"shipping"`
+}
+
+// Quote fetches the listing of the source URL. The shipping cost is only
+// known when it's free: otherwise it depends on the buyer's zip code.
+func (p *mercadoLibreProvider) Quote(ctx context.Context, sourceURL string) (*Quote, error) {
+	id, err := mercadoLibreItemID(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/items/"+id, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("mercadolibre request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("mercadolibre returned status %d", resp.StatusCode)
+	}
+
+	var item mercadoLibreItem
+	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
+		return nil, fmt.Errorf("invalid mercadolibre response: %w", err)
+	}
+	price, err := money.ParseInput(item.Price.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid mercadolibre price %q", item.Price)
+	}
+
+	quote := &Quote{
+		Provider:  p.Name(),
+		ItemID:    item.ID,
+		Title:     item.Title,
+		Price:     price,
+		Currency:  item.CurrencyID,
+		Available: item.Status == "active" && item.AvailableQuantity > 0,
+	}
+	if item.Shipping.FreeShipping {
+		free := money.Zero
+		quote.ShippingCost = &free
+	}
+	return quote, nil
+}
+
+// mercadoLibreItemID finds the listing id ("MLA1234567890") in a product URL
+func mercadoLibreItemID(sourceURL string) (string, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return "", ErrUnsupportedURL
+	}
+
+	query, _ := url.QueryUnescape(u.RawQuery)
+	if m := mercadoLibreQueryPattern.FindStringSubmatch(query); m != nil {
+		return strings.ToUpper(m[1]) + m[2], nil
+	}
+	// Un producto de catálogo no es una publicación: no tiene un único precio
+	if strings.Contains(u.Path, "/p/") {
+		return "", fmt.Errorf("%w: catalog pages have no single price, use the listing URL", ErrUnsupportedURL)
+	}
+	if m := mercadoLibreItemPattern.FindStringSubmatch(u.Path); m != nil {
+		return strings.ToUpper(m[1]) + m[2], nil
+	}
+	return "", ErrUnsupportedURL
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+func TestMercadoLibreItemID(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+		err  error
+	}{
+		{"https://articulo.mercadolibre.com.ar/MLA-1234567890-teclado-mecanico-_JM", "MLA1234567890", nil},
+		{"https://articulo.mercadolibre.com.ar/mla-1234567890-teclado", "MLA1234567890", nil},
+		{"https://produto.mercadolivre.com.br/MLB-987654321-fone-_JM#position=1", "MLB987654321", nil},
+		{"https://articulo.mercadolibre.com.mx/MLM-123456789-teclado-_JM?searchVariation=1", "MLM123456789", nil},
+		{"https://www.mercadolibre.com.ar/teclado-mecanico/p/MLA19876543?item_id=MLA1234567890", "MLA1234567890", nil},
+		{"https://www.mercadolibre.com.ar/teclado-mecanico/p/MLA19876543?pdp_filters=item_id%3AMLA1234567890", "MLA1234567890", nil},
+		{"https://www.mercadolibre.com.ar/teclado-mecanico/p/MLA19876543?wid=MLA1234567890&sid=search", "MLA1234567890", nil},
+		{"https://www.mercadolibre.com.ar/teclado-mecanico/p/MLA19876543", "", ErrUnsupportedURL},
+		{"https://listado.mercadolibre.com.ar/teclado-mecanico", "", ErrUnsupportedURL},
+		{"https://articulo.mercadolibre.com.ar/MLA-123-corto", "", ErrUnsupportedURL},
+	}
+
+	for _, tt := range tests {
+		got, err := mercadoLibreItemID(tt.url)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("mercadoLibreItemID(%q) = %q, %v; want %v", tt.url, got, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("mercadoLibreItemID(%q) = %q, %v; want %q", tt.url, got, err, tt.want)
+		}
+	}
+}
+
+// newMercadoLibreServer stands in for the items API, serving the JSON of each
+// item id and 404 for the rest
+func newMercadoLibreServer(t *testing.T, items map[string]string) PriceProvider {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want the token", got)
+		}
+		body, ok := items[r.URL.Path]
+		if !ok {
+			http.Error(w, `{"message": "Item not found", "status": 404}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return NewMercadoLibreProvider(server.URL+"/", "secret")
+}
+
+func TestMercadoLibreQuote(t *testing.T) {
+	p := newMercadoLibreServer(t, map[string]string{
+		"/items/MLA1111111111": `{"id": "MLA1111111111", "title": "Teclado mecánico", "price": 85000.5,
+			"currency_id": "ARS", "available_quantity": 3, "status": "active", "shipping": {"free_shipping": false}}`,
+		"/items/MLA2222222222": `{"id": "MLA2222222222", "title": "Mouse", "price": 20000,
+			"currency_id": "ARS", "available_quantity": 1, "status": "active", "shipping": {"free_shipping": true}}`,
+		"/items/MLA3333333333": `{"id": "MLA3333333333", "title": "Monitor", "price": 300000,
+			"currency_id": "ARS", "available_quantity": 0, "status": "active"}`,
+		"/items/MLA4444444444": `{"id": "MLA4444444444", "title": "Auriculares", "price": 50000,
+			"currency_id": "ARS", "available_quantity": 5, "status": "paused"}`,
+	})
+	ctx := context.Background()
+
+	quote, err := p.Quote(ctx, "https://articulo.mercadolibre.com.ar/MLA-1111111111-teclado-_JM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Provider != "mercadolibre" || quote.ItemID != "MLA1111111111" || quote.Title != "Teclado mecánico" ||
+		quote.Price != money.MustParse("85000.50") || quote.Currency != "ARS" || !quote.Available {
+		t.Errorf("quote = %+v, want MLA1111111111 at ARS 85000.50, available", quote)
+	}
+	if quote.ShippingCost != nil {
+		t.Errorf("shipping = %s, want unknown (nil) when it isn't free", quote.ShippingCost)
+	}
+
+	quote, err = p.Quote(ctx, "https://articulo.mercadolibre.com.ar/MLA-2222222222-mouse-_JM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.ShippingCost == nil || !quote.ShippingCost.IsZero() {
+		t.Errorf("shipping = %v, want 0.00 for free shipping", quote.ShippingCost)
+	}
+
+	for _, url := range []string{
+		"https://articulo.mercadolibre.com.ar/MLA-3333333333-monitor-_JM",     // Sin stock
+		"https://articulo.mercadolibre.com.ar/MLA-4444444444-auriculares-_JM", // Pausada
+	} {
+		quote, err := p.Quote(ctx, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if quote.Available {
+			t.Errorf("Quote(%s).Available = true, want false", url)
+		}
+	}
+
+	if _, err := p.Quote(ctx, "https://articulo.mercadolibre.com.ar/MLA-9999999999-borrada-_JM"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Quote of a missing item = %v, want ErrNotFound", err)
+	}
+}
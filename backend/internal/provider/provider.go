@@ -0,0 +1,99 @@
+// Package provider looks up the current price of a product in the store it
+// comes from, through the store's official API.
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/buylist-manager/backend/internal/config"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+var (
+	// ErrNoProvider is returned when no provider handles the URL's host
+	ErrNoProvider = errors.New("no price provider for this URL")
+	// ErrUnsupportedURL is returned when the host has a provider but the URL
+	// doesn't point to something it can price (a search, a catalog page, ...)
+	ErrUnsupportedURL = errors.New("URL is not a product the provider can price")
+	// ErrNotFound is returned when the store doesn't know the product
+	ErrNotFound = errors.New("product not found at the provider")
+)
+
+// Quote is the current price of a product as its store reports it
+type Quote struct {
+	Provider     string        `json:"provider"`
+	ItemID       string        `json:"item_id"` // El id del producto en la tienda
+	Title        string        `json:"title"`
+	Price        money.Amount  `json:"price"`
+	ShippingCost *money.Amount `json:"shipping_cost"` // nil = la tienda no lo informa
+	Currency     string        `json:"currency"`
+	Available    bool          `json:"available"`
+}
+
+// PriceProvider fetches the current price of the product at a source URL
+type PriceProvider interface {
+	Name() string
+	Quote(ctx context.Context, sourceURL string) (*Quote, error)
+}
+
+// Registry picks the provider of a source URL by its host
+type Registry struct {
+	domains   []string
+	providers map[string]PriceProvider
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]PriceProvider)}
+}
+
+// New builds the registry with every provider, using the API URLs in the
+// config (so they can point to a local stand-in server)
+func New(cfg *config.Config) *Registry {
+	registry := NewRegistry()
+	registry.Register(
+		NewMercadoLibreProvider(cfg.MercadoLibreAPIURL, cfg.MercadoLibreToken),
+		mercadoLibreDomains...,
+	)
+	return registry
+}
+
+// Register makes p the provider of the given domains and their subdomains
+func (r *Registry) Register(p PriceProvider, domains ...string) {
+	for _, domain := range domains {
+		domain = models.NormalizeDomain(domain)
+		if _, ok := r.providers[domain]; !ok {
+			r.domains = append(r.domains, domain)
+		}
+		r.providers[domain] = p
+	}
+}
+
+// For returns the provider of a source URL. When several domains match, the
+// most specific one wins.
+func (r *Registry) For(sourceURL string) (PriceProvider, error) {
+	host := models.NormalizeDomain(sourceURL)
+	best := ""
+	for _, domain := range r.domains {
+		if (host == domain || strings.HasSuffix(host, "."+domain)) && len(domain) > len(best) {
+			best = domain
+		}
+	}
+	if best == "" {
+		return nil, ErrNoProvider
+	}
+	return r.providers[best], nil
+}
+
+// Quote looks up the current price of the product at a source URL with the
+// provider of its host
+func (r *Registry) Quote(ctx context.Context, sourceURL string) (*Quote, error) {
+	p, err := r.For(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	return p.Quote(ctx, sourceURL)
+}
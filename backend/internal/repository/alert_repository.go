@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// AlertRepository defines the interface for alert data operations
+type AlertRepository interface {
+	Create(alert *models.Alert) error
+	FindByID(userID, id uint) (*models.Alert, error)
+	FindAll(userID uint, acknowledged *bool) ([]*models.Alert, error)
+	Acknowledge(userID, id uint) error
+	MarkNotified(id uint, at time.Time) error
+}
+
+// alertRepository is the concrete implementation
+type alertRepository struct {
+	db *gorm.DB
+}
+
+// NewAlertRepository creates a new instance of AlertRepository
+func NewAlertRepository(db *gorm.DB) AlertRepository {
+	return &alertRepository{db: db}
+}
+
+// Create inserts a new alert
+func (r *alertRepository) Create(alert *models.Alert) error {
+	return r.db.Create(alert).Error
+}
+
+// FindByID retrieves an alert by its ID
+func (r *alertRepository) FindByID(userID, id uint) (*models.Alert, error) {
+	var alert models.Alert
+	err := r.db.Preload("Product").Where("user_id = ?", userID).First(&alert, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("alert not found")
+		}
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// FindAll retrieves alerts, newest first, optionally filtered by acknowledged state
+func (r *alertRepository) FindAll(userID uint, acknowledged *bool) ([]*models.Alert, error) {
+	var alerts []*models.Alert
+	query := r.db.Preload("Product").Where("user_id = ?", userID)
+	if acknowledged != nil {
+		query = query.Where("acknowledged = ?", *acknowledged)
+	}
+
+	err := query.Order(newestFirst).Find(&alerts).Error
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// Acknowledge marks an alert as seen by the user
+func (r *alertRepository) Acknowledge(userID, id uint) error {
+	result := r.db.Model(&models.Alert{}).Where("id = ? AND user_id = ?", id, userID).Updates(map[string]interface{}{
+		"acknowledged":    true,
+		"acknowledged_at": time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("alert not found")
+	}
+	return nil
+}
+
+// MarkNotified records when the alert was delivered by the notifier
+func (r *alertRepository) MarkNotified(id uint, at time.Time) error {
+	return r.db.Model(&models.Alert{}).Where("id = ?", id).Update("notified_at", at).Error
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// APITokenRepository defines the interface for API token data operations
+type APITokenRepository interface {
+	Create(token *models.APIToken) error
+	FindAll(userID uint) ([]*models.APIToken, error)
+	FindByHash(tokenHash string) (*models.APIToken, error)
+	Revoke(userID, id uint) error
+	Touch(id uint, usedAt time.Time) error
+	WithTx(tx *gorm.DB) APITokenRepository
+}
+
+// apiTokenRepository is the concrete implementation
+type apiTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewAPITokenRepository creates a new instance of APITokenRepository
+func NewAPITokenRepository(db *gorm.DB) APITokenRepository {
+	return &apiTokenRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *apiTokenRepository) WithTx(tx *gorm.DB) APITokenRepository {
+	return &apiTokenRepository{db: tx}
+}
+
+// Create inserts a new API token
+func (r *apiTokenRepository) Create(token *models.APIToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindAll retrieves the user's API tokens, revoked ones included, newest first
+func (r *apiTokenRepository) FindAll(userID uint) ([]*models.APIToken, error) {
+	var tokens []*models.APIToken
+	if err := r.db.Where("user_id = ?", userID).Order(newestFirst).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// FindByHash retrieves an API token by the SHA-256 hash of its value
+func (r *apiTokenRepository) FindByHash(tokenHash string) (*models.APIToken, error) {
+	var token models.APIToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("api token not found")
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Revoke marks an API token of the user as no longer usable
+func (r *apiTokenRepository) Revoke(userID, id uint) error {
+	result := r.db.Model(&models.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("api token not found")
+	}
+	return nil
+}
+
+// Touch records when an API token was last used
+func (r *apiTokenRepository) Touch(id uint, usedAt time.Time) error {
+	return r.db.Model(&models.APIToken{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+}
@@ -10,10 +10,11 @@ import (
 // CategoryRepository defines the interface for category data operations
 type CategoryRepository interface {
 	Create(category *models.Category) error
-	FindByID(id uint) (*models.Category, error)
-	FindAll() ([]*models.Category, error)
+	FindByID(userID, id uint) (*models.Category, error)
+	FindAll(userID uint) ([]*models.Category, error)
 	Update(category *models.Category) error
-	Delete(id uint) error
+	Delete(userID, id uint) error
+	WithTx(tx *gorm.DB) CategoryRepository
 }
 
 // categoryRepository is the concrete implementation
@@ -26,15 +27,20 @@ func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 	return &categoryRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *categoryRepository) WithTx(tx *gorm.DB) CategoryRepository {
+	return &categoryRepository{db: tx}
+}
+
 // Create inserts a new category into the database
 func (r *categoryRepository) Create(category *models.Category) error {
 	return r.db.Create(category).Error
 }
 
 // FindByID retrieves a category by its ID
-func (r *categoryRepository) FindByID(id uint) (*models.Category, error) {
+func (r *categoryRepository) FindByID(userID, id uint) (*models.Category, error) {
 	var category models.Category
-	err := r.db.First(&category, id).Error
+	err := r.db.Where("user_id = ?", userID).First(&category, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("category not found")
@@ -45,9 +51,9 @@ func (r *categoryRepository) FindByID(id uint) (*models.Category, error) {
 }
 
 // FindAll retrieves all categories
-func (r *categoryRepository) FindAll() ([]*models.Category, error) {
+func (r *categoryRepository) FindAll(userID uint) ([]*models.Category, error) {
 	var categories []*models.Category
-	err := r.db.Find(&categories).Error
+	err := r.db.Where("user_id = ?", userID).Find(&categories).Error
 	if err != nil {
 		return nil, err
 	}
@@ -60,8 +66,8 @@ func (r *categoryRepository) Update(category *models.Category) error {
 }
 
 // Delete deletes a category by ID (soft delete if using GORM soft delete)
-func (r *categoryRepository) Delete(id uint) error {
-	result := r.db.Delete(&models.Category{}, id)
+func (r *categoryRepository) Delete(userID, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.Category{}, id)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Diferencias de dialecto entre PostgreSQL y SQLite que los repositorios tienen en cuenta:
+//   - DECIMAL(10,2): SQLite no tiene tipo decimal y lo guarda como REAL. money.Amount
+//     escribe el monto como texto ("93.49") y al leer redondea a centavos, así que
+//     ningún motor arrastra errores de float.
+//   - Booleanos: siempre se filtran con parámetros (is_purchased = ?), nunca con
+//     literales TRUE/FALSE, porque SQLite los guarda como 0/1.
+//   - Ordenamiento: PostgreSQL pone los NULL primero en DESC y SQLite al final, y
+//     ninguno garantiza el orden de empates. Todo ORDER BY usa NULLS LAST explícito
+//     (soportado por ambos) y desempata por id.
+//   - Fechas: SQLite guarda los timestamps como texto ("2026-10-16 23:04:47+00:00"),
+//     así que para agrupar por mes se toma el prefijo en vez de usar to_char.
+
+// newestFirst is the default ordering for listings
+const newestFirst = "created_at DESC, id DESC"
+
+// orderBy builds an ORDER BY clause that behaves the same on PostgreSQL and SQLite.
+// column must come from a whitelist, never from user input. A qualified column
+// ("price_history.recorded_at") qualifies the id tiebreaker too, so joins stay unambiguous.
+func orderBy(column string, desc bool) string {
+	id := "id"
+	if i := strings.LastIndex(column, "."); i >= 0 {
+		id = column[:i+1] + "id"
+	}
+	if desc {
+		return fmt.Sprintf("%s DESC NULLS LAST, %s DESC", column, id)
+	}
+	return fmt.Sprintf("%s ASC NULLS LAST, %s ASC", column, id)
+}
+
+// monthOf returns an SQL expression with the month ("2026-10") of a timestamp
+// column. column must come from a whitelist, never from user input.
+func monthOf(db *gorm.DB, column string) string {
+	if db.Dialector.Name() == "postgres" {
+		return fmt.Sprintf("to_char(%s, 'YYYY-MM')", column)
+	}
+	return fmt.Sprintf("substr(%s, 1, 7)", column)
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExchangeRateRepository defines the interface for exchange rate data operations
+type ExchangeRateRepository interface {
+	Upsert(rate *models.ExchangeRate) error
+	FindAll(userID uint, currency string) ([]*models.ExchangeRate, error)
+	FindLatest(userID uint, from, to string, on time.Time) (*models.ExchangeRate, error)
+	Delete(userID, id uint) error
+	WithTx(tx *gorm.DB) ExchangeRateRepository
+}
+
+// exchangeRateRepository is the concrete implementation
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+// NewExchangeRateRepository creates a new instance of ExchangeRateRepository
+func NewExchangeRateRepository(db *gorm.DB) ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *exchangeRateRepository) WithTx(tx *gorm.DB) ExchangeRateRepository {
+	return &exchangeRateRepository{db: tx}
+}
+
+// Upsert inserts a rate, or replaces the rate already loaded for the same pair and date
+func (r *exchangeRateRepository) Upsert(rate *models.ExchangeRate) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "from_currency"}, {Name: "to_currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+	}).Create(rate).Error
+}
+
+// FindAll retrieves the user's rates, newest date first, optionally only the
+// ones involving a currency
+func (r *exchangeRateRepository) FindAll(userID uint, currency string) ([]*models.ExchangeRate, error) {
+	var rates []*models.ExchangeRate
+	query := r.db.Where("user_id = ?", userID)
+	if currency != "" {
+		query = query.Where("from_currency = ? OR to_currency = ?", currency, currency)
+	}
+
+	err := query.Order("date DESC, from_currency ASC, to_currency ASC").Find(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// FindLatest retrieves the most recent rate from -> to dated on or before the
+// given day. Returns nil when there is none.
+func (r *exchangeRateRepository) FindLatest(userID uint, from, to string, on time.Time) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := r.db.Where("user_id = ? AND from_currency = ? AND to_currency = ? AND date <= ?", userID, from, to, on).
+		Order("date DESC").
+		First(&rate).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// Delete removes an exchange rate
+func (r *exchangeRateRepository) Delete(userID, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.ExchangeRate{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("exchange rate not found")
+	}
+	return nil
+}
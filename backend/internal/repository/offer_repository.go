@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// OfferRepository defines the interface for offer data operations
+type OfferRepository interface {
+	Create(offer *models.Offer) error
+	FindByID(userID, productID, id uint) (*models.Offer, error)
+	FindByProductID(userID, productID uint) ([]*models.Offer, error)
+	FindAll(userID uint) ([]*models.Offer, error)
+	Update(offer *models.Offer) error
+	Delete(userID, productID, id uint) error
+	WithTx(tx *gorm.DB) OfferRepository
+}
+
+// offerRepository is the concrete implementation
+type offerRepository struct {
+	db *gorm.DB
+}
+
+// NewOfferRepository creates a new instance of OfferRepository
+func NewOfferRepository(db *gorm.DB) OfferRepository {
+	return &offerRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *offerRepository) WithTx(tx *gorm.DB) OfferRepository {
+	return &offerRepository{db: tx}
+}
+
+// Create inserts a new offer
+func (r *offerRepository) Create(offer *models.Offer) error {
+	return r.db.Create(offer).Error
+}
+
+// FindByID retrieves an offer of one of the user's products
+func (r *offerRepository) FindByID(userID, productID, id uint) (*models.Offer, error) {
+	var offer models.Offer
+	err := r.db.Where("user_id = ? AND product_id = ?", userID, productID).First(&offer, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("offer not found")
+		}
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// FindByProductID retrieves the offers of a product, oldest first
+func (r *offerRepository) FindByProductID(userID, productID uint) ([]*models.Offer, error) {
+	var offers []*models.Offer
+	err := r.db.Where("user_id = ? AND product_id = ?", userID, productID).
+		Order("id ASC").
+		Find(&offers).Error
+	if err != nil {
+		return nil, err
+	}
+	return offers, nil
+}
+
+// FindAll retrieves the offers of all the user's products, oldest first
+func (r *offerRepository) FindAll(userID uint) ([]*models.Offer, error) {
+	var offers []*models.Offer
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&offers).Error
+	if err != nil {
+		return nil, err
+	}
+	return offers, nil
+}
+
+// Update updates an existing offer
+func (r *offerRepository) Update(offer *models.Offer) error {
+	return r.db.Save(offer).Error
+}
+
+// Delete removes an offer
+func (r *offerRepository) Delete(userID, productID, id uint) error {
+	result := r.db.Where("user_id = ? AND product_id = ?", userID, productID).Delete(&models.Offer{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("offer not found")
+	}
+	return nil
+}
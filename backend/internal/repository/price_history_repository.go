@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// PriceHistoryRepository defines the interface for price history data operations
+type PriceHistoryRepository interface {
+	Create(entry *models.PriceHistory) error
+	FindByProductID(userID, productID uint, from, to *time.Time) ([]*models.PriceHistory, error)
+	FindAll(userID uint) ([]*models.PriceHistory, error)
+	WithTx(tx *gorm.DB) PriceHistoryRepository
+}
+
+// priceHistoryRepository is the concrete implementation
+type priceHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPriceHistoryRepository creates a new instance of PriceHistoryRepository
+func NewPriceHistoryRepository(db *gorm.DB) PriceHistoryRepository {
+	return &priceHistoryRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *priceHistoryRepository) WithTx(tx *gorm.DB) PriceHistoryRepository {
+	return &priceHistoryRepository{db: tx}
+}
+
+// Create inserts a new price history entry
+func (r *priceHistoryRepository) Create(entry *models.PriceHistory) error {
+	return r.db.Create(entry).Error
+}
+
+// FindByProductID retrieves the price history of a product owned by the user, newest first.
+// from and to are optional bounds on recorded_at (from inclusive, to exclusive).
+func (r *priceHistoryRepository) FindByProductID(userID, productID uint, from, to *time.Time) ([]*models.PriceHistory, error) {
+	var entries []*models.PriceHistory
+	query := r.db.Joins("JOIN products ON products.id = price_history.product_id").
+		Where("products.user_id = ? AND price_history.product_id = ?", userID, productID)
+	if from != nil {
+		query = query.Where("price_history.recorded_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("price_history.recorded_at < ?", *to)
+	}
+
+	err := query.Order(orderBy("price_history.recorded_at", true)).Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindAll retrieves the price history of all the user's products, oldest first
+func (r *priceHistoryRepository) FindAll(userID uint) ([]*models.PriceHistory, error) {
+	var entries []*models.PriceHistory
+	err := r.db.Joins("JOIN products ON products.id = price_history.product_id").
+		Where("products.user_id = ?", userID).
+		Order(orderBy("price_history.recorded_at", false)).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
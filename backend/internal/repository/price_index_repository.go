@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PriceIndexRepository defines the interface for price index data operations
+type PriceIndexRepository interface {
+	Upsert(index *models.PriceIndex) error
+	FindAll(userID uint, currency string) ([]*models.PriceIndex, error)
+	FindSeries(userID uint, currency string) ([]*models.PriceIndex, error)
+	Delete(userID, id uint) error
+	WithTx(tx *gorm.DB) PriceIndexRepository
+}
+
+// priceIndexRepository is the concrete implementation
+type priceIndexRepository struct {
+	db *gorm.DB
+}
+
+// NewPriceIndexRepository creates a new instance of PriceIndexRepository
+func NewPriceIndexRepository(db *gorm.DB) PriceIndexRepository {
+	return &priceIndexRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *priceIndexRepository) WithTx(tx *gorm.DB) PriceIndexRepository {
+	return &priceIndexRepository{db: tx}
+}
+
+// Upsert inserts an index value, or replaces the one already loaded for the
+// same currency and month
+func (r *priceIndexRepository) Upsert(index *models.PriceIndex) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "currency"}, {Name: "month"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(index).Error
+}
+
+// FindAll retrieves the user's index values, newest month first, optionally
+// only the ones of a currency
+func (r *priceIndexRepository) FindAll(userID uint, currency string) ([]*models.PriceIndex, error) {
+	var indexes []*models.PriceIndex
+	query := r.db.Where("user_id = ?", userID)
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+
+	err := query.Order("month DESC, currency ASC").Find(&indexes).Error
+	if err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// FindSeries retrieves every value of a currency's index, oldest month first.
+// Son a lo sumo 12 filas por año, así que se cargan completas.
+func (r *priceIndexRepository) FindSeries(userID uint, currency string) ([]*models.PriceIndex, error) {
+	var indexes []*models.PriceIndex
+	err := r.db.Where("user_id = ? AND currency = ?", userID, currency).
+		Order("month ASC").
+		Find(&indexes).Error
+	if err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// Delete removes an index value
+func (r *priceIndexRepository) Delete(userID, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.PriceIndex{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("price index not found")
+	}
+	return nil
+}
@@ -0,0 +1,363 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"gorm.io/gorm"
+)
+
+// Límites de página para los listados de productos
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// RecurrenceNone filters products without recurrence (recurrence_unit IS NULL)
+const RecurrenceNone = "none"
+
+// ErrInvalidCursor is returned when a pagination cursor can't be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// productSortColumns whitelists the columns a listing can be sorted by
+var productSortColumns = map[string]bool{
+	"created_at":        true,
+	"updated_at":        true,
+	"price_date":        true,
+	"purchase_date":     true,
+	"next_billing_date": true,
+	"base_price":        true,
+	"shipping_cost":     true,
+	"taxes":             true,
+	"total_price":       true,
+}
+
+// productDateColumns are the sort columns whose cursor value is a timestamp
+var productDateColumns = map[string]bool{
+	"created_at":        true,
+	"updated_at":        true,
+	"price_date":        true,
+	"purchase_date":     true,
+	"next_billing_date": true,
+}
+
+// ProductQuery combines the filters, sorting and pagination of a product listing.
+// Nil/zero fields don't filter. Date ranges are [From, To).
+type ProductQuery struct {
+	Purchased      *bool
+	CategoryID     *uint
+	SubcategoryID  *uint
+	StoreID        *uint
+	MinPrice       *money.Amount // Sobre total_price
+	MaxPrice       *money.Amount
+	RecurrenceUnit *string // "day", "week", "month", "year" o RecurrenceNone
+	Currency       string  // Código ISO 4217 ya normalizado
+
+	SubscriptionStatus *string // "trial", "active", "paused" o "cancelled"
+
+	CreatedFrom      *time.Time
+	CreatedTo        *time.Time
+	PriceDateFrom    *time.Time
+	PriceDateTo      *time.Time
+	PurchaseDateFrom *time.Time
+	PurchaseDateTo   *time.Time
+
+	SortBy   string // Columna de productSortColumns, default created_at
+	SortDesc bool
+
+	Limit  int
+	Offset int
+	Cursor string // next_cursor de la página anterior; excluyente con Offset
+}
+
+// ProductPage is one page of a product listing
+type ProductPage struct {
+	Items      []*models.Product `json:"items"`
+	Total      int64             `json:"total"`
+	Limit      int               `json:"limit"`
+	Offset     int               `json:"offset"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// productCursor is the keyset position after the last item of a page
+type productCursor struct {
+	Value interface{} `json:"v"`
+	ID    uint        `json:"id"`
+}
+
+// Validate checks the query and fills in defaults
+func (q *ProductQuery) Validate() error {
+	if q.SortBy == "" {
+		q.SortBy = "created_at"
+	}
+	if !productSortColumns[q.SortBy] {
+		return fmt.Errorf("invalid sort column: %s", q.SortBy)
+	}
+	if q.RecurrenceUnit != nil {
+		switch *q.RecurrenceUnit {
+		case models.RecurrenceDay, models.RecurrenceWeek, models.RecurrenceMonth, models.RecurrenceYear, RecurrenceNone:
+		default:
+			return fmt.Errorf("invalid recurrence_unit: %s", *q.RecurrenceUnit)
+		}
+	}
+	if q.SubscriptionStatus != nil && !models.IsValidSubscriptionStatus(*q.SubscriptionStatus) {
+		return fmt.Errorf("invalid subscription_status: %s", *q.SubscriptionStatus)
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil && *q.MinPrice > *q.MaxPrice {
+		return errors.New("min_price can't be greater than max_price")
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageLimit
+	}
+	if q.Limit > MaxPageLimit {
+		q.Limit = MaxPageLimit
+	}
+	if q.Offset < 0 {
+		return errors.New("offset can't be negative")
+	}
+	if q.Cursor != "" && q.Offset > 0 {
+		return errors.New("cursor and offset can't be combined")
+	}
+	return nil
+}
+
+// List returns a page of products matching the query, plus the total count
+func (r *productRepository) List(userID uint, q ProductQuery) (*ProductPage, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	filtered := r.applyProductFilters(r.db.Model(&models.Product{}).Where("user_id = ?", userID), q)
+
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	page := filtered.Session(&gorm.Session{})
+	if q.Cursor != "" {
+		cursor, err := decodeProductCursor(q.Cursor, q.SortBy)
+		if err != nil {
+			return nil, err
+		}
+		page = applyProductCursor(page, q.SortBy, q.SortDesc, cursor)
+	}
+
+	// Se pide uno de más para saber si hay otra página
+	var products []*models.Product
+	err := page.Preload("Category").Preload("Subcategory").
+		Order(orderBy(q.SortBy, q.SortDesc)).
+		Limit(q.Limit + 1).Offset(q.Offset).
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProductPage{Total: total, Limit: q.Limit, Offset: q.Offset}
+	if len(products) > q.Limit {
+		products = products[:q.Limit]
+		next, err := encodeProductCursor(products[len(products)-1], q.SortBy)
+		if err != nil {
+			return nil, err
+		}
+		result.NextCursor = next
+	}
+	result.Items = products
+	return result, nil
+}
+
+// applyProductFilters adds the WHERE conditions of the query
+func (r *productRepository) applyProductFilters(db *gorm.DB, q ProductQuery) *gorm.DB {
+	if q.Purchased != nil {
+		db = db.Where("is_purchased = ?", *q.Purchased)
+	}
+	if q.CategoryID != nil {
+		db = db.Where("category_id = ?", *q.CategoryID)
+	}
+	if q.SubcategoryID != nil {
+		db = db.Where("subcategory_id = ?", *q.SubcategoryID)
+	}
+	if q.StoreID != nil {
+		db = db.Where("store_id = ?", *q.StoreID)
+	}
+	if q.MinPrice != nil {
+		db = db.Where("total_price >= ?", *q.MinPrice)
+	}
+	if q.MaxPrice != nil {
+		db = db.Where("total_price <= ?", *q.MaxPrice)
+	}
+	if q.Currency != "" {
+		db = db.Where("currency = ?", q.Currency)
+	}
+	if q.SubscriptionStatus != nil {
+		db = db.Where("subscription_status = ?", *q.SubscriptionStatus)
+	}
+	if q.RecurrenceUnit != nil {
+		if *q.RecurrenceUnit == RecurrenceNone {
+			db = db.Where("recurrence_unit IS NULL")
+		} else {
+			db = db.Where("recurrence_unit = ?", *q.RecurrenceUnit)
+		}
+	}
+
+	ranges := []struct {
+		column   string
+		from, to *time.Time
+	}{
+		{"created_at", q.CreatedFrom, q.CreatedTo},
+		{"price_date", q.PriceDateFrom, q.PriceDateTo},
+		{"purchase_date", q.PurchaseDateFrom, q.PurchaseDateTo},
+	}
+	for _, rg := range ranges {
+		if rg.from != nil {
+			db = db.Where(rg.column+" >= ?", *rg.from)
+		}
+		if rg.to != nil {
+			db = db.Where(rg.column+" < ?", *rg.to)
+		}
+	}
+	return db
+}
+
+// applyProductCursor continues after the cursor position. Matches orderBy:
+// NULLs go last in both directions and id breaks ties.
+func applyProductCursor(db *gorm.DB, column string, desc bool, c *productCursor) *gorm.DB {
+	cmp, idCmp := ">", ">"
+	if desc {
+		cmp, idCmp = "<", "<"
+	}
+
+	if c.Value == nil {
+		return db.Where(fmt.Sprintf("%s IS NULL AND id %s ?", column, idCmp), c.ID)
+	}
+	return db.Where(
+		fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[3]s ?) OR %[1]s IS NULL)", column, cmp, idCmp),
+		c.Value, c.Value, c.ID,
+	)
+}
+
+// ForEach walks every product matching the query's filters in the query's
+// order, loading them in batches. With a cursor it starts after it; limit and
+// offset are ignored. It stops at the first error returned by fn.
+func (r *productRepository) ForEach(userID uint, q ProductQuery, fn func(*models.Product) error) error {
+	q.Limit, q.Offset = 0, 0
+	if err := q.Validate(); err != nil {
+		return err
+	}
+
+	var cursor *productCursor
+	if q.Cursor != "" {
+		var err error
+		if cursor, err = decodeProductCursor(q.Cursor, q.SortBy); err != nil {
+			return err
+		}
+	}
+
+	filtered := r.applyProductFilters(r.db.Model(&models.Product{}).Where("user_id = ?", userID), q)
+	for {
+		batch := filtered.Session(&gorm.Session{})
+		if cursor != nil {
+			batch = applyProductCursor(batch, q.SortBy, q.SortDesc, cursor)
+		}
+
+		var products []*models.Product
+		err := batch.Preload("Category").Preload("Subcategory").
+			Order(orderBy(q.SortBy, q.SortDesc)).
+			Limit(MaxPageLimit).
+			Find(&products).Error
+		if err != nil {
+			return err
+		}
+
+		for _, p := range products {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		if len(products) < MaxPageLimit {
+			return nil
+		}
+		cursor = productCursorAfter(products[len(products)-1], q.SortBy)
+	}
+}
+
+// encodeProductCursor builds an opaque cursor from the sort value and id of a product
+func encodeProductCursor(p *models.Product, column string) (string, error) {
+	raw, err := json.Marshal(productCursorAfter(p, column))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// productCursorAfter returns the keyset position of a product for the given sort column
+func productCursorAfter(p *models.Product, column string) *productCursor {
+	var value interface{}
+	switch column {
+	case "created_at":
+		value = p.CreatedAt
+	case "updated_at":
+		value = p.UpdatedAt
+	case "price_date":
+		if p.PriceDate != nil {
+			value = *p.PriceDate
+		}
+	case "purchase_date":
+		if p.PurchaseDate != nil {
+			value = *p.PurchaseDate
+		}
+	case "next_billing_date":
+		if p.NextBillingDate != nil {
+			value = *p.NextBillingDate
+		}
+	case "base_price":
+		value = p.BasePrice
+	case "shipping_cost":
+		value = p.ShippingCost
+	case "taxes":
+		value = p.Taxes
+	case "total_price":
+		value = p.TotalPrice
+	}
+	return &productCursor{Value: value, ID: p.ID}
+}
+
+// decodeProductCursor parses a cursor back, restoring timestamps for date columns
+func decodeProductCursor(cursor, column string) (*productCursor, error) {
+	invalid := ErrInvalidCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	var c productCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, invalid
+	}
+
+	switch v := c.Value.(type) {
+	case nil:
+	case string:
+		if !productDateColumns[column] {
+			return nil, invalid
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, invalid
+		}
+		c.Value = t
+	case float64:
+		if productDateColumns[column] {
+			return nil, invalid
+		}
+		c.Value = money.FromFloat(v)
+	default:
+		return nil, invalid
+	}
+	return &c, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/dbtest"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+func TestForEachStartsAfterCursor(t *testing.T) {
+	db := dbtest.New(t)
+	user := dbtest.CreateUser(t, db, "ana@example.com")
+	category := &models.Category{UserID: user.ID, Name: "Hardware", Type: "one_time"}
+	dbtest.MustCreate(t, db, category)
+	subcategory := &models.Subcategory{UserID: user.ID, CategoryID: category.ID, Name: "Teclados"}
+	dbtest.MustCreate(t, db, subcategory)
+	for _, price := range []string{"30.00", "10.00", "20.00"} {
+		dbtest.MustCreate(t, db, &models.Product{
+			UserID: user.ID, Name: price, BasePrice: money.MustParse(price), Currency: "ARS",
+			CategoryID: category.ID, SubcategoryID: subcategory.ID,
+		})
+	}
+
+	repo := NewProductRepository(db)
+	query := ProductQuery{SortBy: "base_price", Limit: 2}
+	page, err := repo.List(user.ID, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.NextCursor == "" {
+		t.Fatal("List returned no next_cursor")
+	}
+
+	var names []string
+	query.Cursor = page.NextCursor
+	err = repo.ForEach(user.ID, query, func(p *models.Product) error {
+		names = append(names, p.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "30.00" {
+		t.Errorf("ForEach after the first page = %v, want [30.00]", names)
+	}
+
+	query.Cursor = "not-a-cursor"
+	if err := repo.ForEach(user.ID, query, func(*models.Product) error { return nil }); err != ErrInvalidCursor {
+		t.Errorf("ForEach with an invalid cursor = %v, want ErrInvalidCursor", err)
+	}
+}
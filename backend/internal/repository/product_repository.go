@@ -2,21 +2,37 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/buylist-manager/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ProductRepository defines the interface for product data operations
 type ProductRepository interface {
 	Create(product *models.Product) error
-	FindByID(id uint) (*models.Product, error)
-	FindAll() ([]*models.Product, error)
-	FindByCategoryID(categoryID uint) ([]*models.Product, error)
-	FindBySubcategoryID(subcategoryID uint) ([]*models.Product, error)
-	FindPending() ([]*models.Product, error) // Productos no comprados
+	FindByID(userID, id uint) (*models.Product, error)
+	FindAll(userID uint) ([]*models.Product, error)
+	FindPending(userID uint) ([]*models.Product, error) // Productos no comprados
+	List(userID uint, query ProductQuery) (*ProductPage, error)
+	ForEach(userID uint, query ProductQuery, fn func(*models.Product) error) error
+	Search(userID uint, query string, limit int) ([]*models.Product, error)
+	Totals(userID uint) ([]*ProductTotals, error)
+	TotalsByPriceMonth(userID uint) ([]*ProductTotals, error)
+	MonthlySpend(userID uint, from, to time.Time) ([]*MonthlySpend, error)
 	Update(product *models.Product) error
-	Delete(id uint) error
+	FindSubscriptionsToRenew(today time.Time) ([]*models.Product, error)
+	UpdateSubscription(product *models.Product) error
+	SetPurchaseDate(userID, id uint, date time.Time) error
+	FindPricesToRefresh(checkedBefore time.Time) ([]*models.Product, error)
+	FindInstallmentPlans(userID uint, from time.Time) ([]*models.Product, error)
+	FindStoreLinks(userID uint) ([]*models.Product, error)
+	SetStore(userID uint, ids []uint, storeID *uint) error
+	ClearTaxProfile(userID, profileID uint) error
+	StoreTotals(userID uint) ([]*StoreTotals, error)
+	Delete(userID, id uint) error
+	WithTx(tx *gorm.DB) ProductRepository
 }
 
 // productRepository is the concrete implementation
@@ -29,16 +45,22 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 	return &productRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *productRepository) WithTx(tx *gorm.DB) ProductRepository {
+	return &productRepository{db: tx}
+}
+
 // Create inserts a new product into the database
 func (r *productRepository) Create(product *models.Product) error {
 	return r.db.Create(product).Error
 }
 
 // FindByID retrieves a product by its ID
-func (r *productRepository) FindByID(id uint) (*models.Product, error) {
+func (r *productRepository) FindByID(userID, id uint) (*models.Product, error) {
 	var product models.Product
 	// Preload Category y Subcategory (como ->with(['category', 'subcategory']) en Laravel)
-	err := r.db.Preload("Category").Preload("Subcategory").First(&product, id).Error
+	err := r.db.Preload("Category").Preload("Subcategory").
+		Where("user_id = ?", userID).First(&product, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("product not found")
@@ -49,23 +71,24 @@ func (r *productRepository) FindByID(id uint) (*models.Product, error) {
 }
 
 // FindAll retrieves all products
-func (r *productRepository) FindAll() ([]*models.Product, error) {
+func (r *productRepository) FindAll(userID uint) ([]*models.Product, error) {
 	var products []*models.Product
 	err := r.db.Preload("Category").Preload("Subcategory").
-		Order("created_at DESC").Find(&products).Error
+		Where("user_id = ?", userID).
+		Order(newestFirst).Find(&products).Error
 	if err != nil {
 		return nil, err
 	}
 	return products, nil
 }
 
-// FindByCategoryID retrieves all products for a specific category
-// Laravel: Product::where('category_id', $categoryId)->get()
-func (r *productRepository) FindByCategoryID(categoryID uint) ([]*models.Product, error) {
+// FindPending retrieves all products that haven't been purchased yet
+// Laravel: Product::where('is_purchased', false)->get()
+func (r *productRepository) FindPending(userID uint) ([]*models.Product, error) {
 	var products []*models.Product
 	err := r.db.Preload("Category").Preload("Subcategory").
-		Where("category_id = ?", categoryID).
-		Order("created_at DESC").
+		Where("user_id = ? AND is_purchased = ?", userID, false).
+		Order(newestFirst).
 		Find(&products).Error
 	if err != nil {
 		return nil, err
@@ -73,12 +96,23 @@ func (r *productRepository) FindByCategoryID(categoryID uint) ([]*models.Product
 	return products, nil
 }
 
-// FindBySubcategoryID retrieves all products for a specific subcategory
-func (r *productRepository) FindBySubcategoryID(subcategoryID uint) ([]*models.Product, error) {
+// Update updates an existing product
+// Las relaciones precargadas se omiten para que no pisen category_id/subcategory_id
+func (r *productRepository) Update(product *models.Product) error {
+	return r.db.Omit(clause.Associations).Save(product).Error
+}
+
+// FindSubscriptionsToRenew retrieves, for every user, the active subscriptions
+// whose next billing date is missing or before today, and the trials that end
+// today or earlier
+func (r *productRepository) FindSubscriptionsToRenew(today time.Time) ([]*models.Product, error) {
 	var products []*models.Product
-	err := r.db.Preload("Category").Preload("Subcategory").
-		Where("subcategory_id = ?", subcategoryID).
-		Order("created_at DESC").
+	err := r.db.
+		Where("recurrence_unit IS NOT NULL").
+		Where(r.db.
+			Where("subscription_status = ? AND (next_billing_date IS NULL OR next_billing_date < ?)", models.SubscriptionActive, today).
+			Or("subscription_status = ? AND trial_ends_at <= ?", models.SubscriptionTrial, today)).
+		Order("id ASC").
 		Find(&products).Error
 	if err != nil {
 		return nil, err
@@ -86,13 +120,28 @@ func (r *productRepository) FindBySubcategoryID(subcategoryID uint) ([]*models.P
 	return products, nil
 }
 
-// FindPending retrieves all products that haven't been purchased yet
-// Laravel: Product::where('is_purchased', false)->get()
-func (r *productRepository) FindPending() ([]*models.Product, error) {
+// UpdateSubscription saves only the subscription status and next billing date
+func (r *productRepository) UpdateSubscription(product *models.Product) error {
+	return r.db.Model(&models.Product{}).Where("id = ?", product.ID).Updates(map[string]interface{}{
+		"subscription_status": product.SubscriptionStatus,
+		"next_billing_date":   product.NextBillingDate,
+	}).Error
+}
+
+// SetPurchaseDate saves only the purchase date of a product
+func (r *productRepository) SetPurchaseDate(userID, id uint, date time.Time) error {
+	return r.db.Model(&models.Product{}).Where("user_id = ? AND id = ?", userID, id).
+		UpdateColumn("purchase_date", date).Error
+}
+
+// FindPricesToRefresh retrieves, for every user, the pending products with a
+// source URL whose price wasn't checked since checkedBefore
+func (r *productRepository) FindPricesToRefresh(checkedBefore time.Time) ([]*models.Product, error) {
 	var products []*models.Product
-	err := r.db.Preload("Category").Preload("Subcategory").
-		Where("is_purchased = ?", false).
-		Order("created_at DESC").
+	err := r.db.
+		Where("is_purchased = ? AND source_url <> ?", false, "").
+		Where("price_checked_at IS NULL OR price_checked_at < ?", checkedBefore).
+		Order("id ASC").
 		Find(&products).Error
 	if err != nil {
 		return nil, err
@@ -100,14 +149,57 @@ func (r *productRepository) FindPending() ([]*models.Product, error) {
 	return products, nil
 }
 
-// Update updates an existing product
-func (r *productRepository) Update(product *models.Product) error {
-	return r.db.Save(product).Error
+// FindInstallmentPlans retrieves the purchased products whose installment
+// plan still has installments due on or after the given day
+func (r *productRepository) FindInstallmentPlans(userID uint, from time.Time) ([]*models.Product, error) {
+	var products []*models.Product
+	err := r.db.
+		Where("user_id = ? AND is_purchased = ? AND last_installment_date >= ?", userID, true, from).
+		Order("id ASC").
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// FindStoreLinks retrieves the id, source URL and store of every product of the
+// user, also the deleted ones so their link doesn't point to a removed store
+func (r *productRepository) FindStoreLinks(userID uint) ([]*models.Product, error) {
+	var products []*models.Product
+	err := r.db.Unscoped().
+		Select("id", "source_url", "store_id").
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// SetStore links the given products to a store (nil unlinks them). It is not an
+// edit of the products, so updated_at is left as it is.
+func (r *productRepository) SetStore(userID uint, ids []uint, storeID *uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Unscoped().Model(&models.Product{}).
+		Where("user_id = ? AND id IN ?", userID, ids).
+		UpdateColumn("store_id", storeID).Error
+}
+
+// ClearTaxProfile unlinks the products that use a tax profile. Their taxes stay
+// as they were computed.
+func (r *productRepository) ClearTaxProfile(userID, profileID uint) error {
+	return r.db.Unscoped().Model(&models.Product{}).
+		Where("user_id = ? AND tax_profile_id = ?", userID, profileID).
+		UpdateColumn("tax_profile_id", nil).Error
 }
 
 // Delete deletes a product by ID
-func (r *productRepository) Delete(id uint) error {
-	result := r.db.Delete(&models.Product{}, id)
+func (r *productRepository) Delete(userID, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.Product{}, id)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"sort"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/search"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Pesos de cada campo al rankear en el fallback (en PostgreSQL son los setweight A/B/C)
+const (
+	nameWeight        = 1.0
+	descriptionWeight = 0.6
+	notesWeight       = 0.4
+)
+
+// Search finds products whose name, description or notes match the query.
+// Matching ignores accents and tolerates typos; results come best match first.
+func (r *productRepository) Search(userID uint, query string, limit int) ([]*models.Product, error) {
+	if r.db.Dialector.Name() == "postgres" {
+		return r.searchPostgres(userID, query, limit)
+	}
+	return r.searchFallback(userID, query, limit)
+}
+
+// searchPostgres uses the search_vector (full-text) and search_text (trigram)
+// generated columns from migration 0006. Full-text handles whole words and
+// ranking; trigram word similarity catches typos and partial words.
+func (r *productRepository) searchPostgres(userID uint, query string, limit int) ([]*models.Product, error) {
+	var products []*models.Product
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// El default de pg_trgm (0.6) no deja pasar un error de tipeo en palabras cortas
+		if err := tx.Exec("SET LOCAL pg_trgm.word_similarity_threshold = 0.4").Error; err != nil {
+			return err
+		}
+		return tx.Preload("Category").Preload("Subcategory").
+			Where("user_id = ?", userID).
+			Where("search_vector @@ websearch_to_tsquery('simple', f_unaccent(?)) OR lower(f_unaccent(?)) <% search_text", query, query).
+			Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "ts_rank(search_vector, websearch_to_tsquery('simple', f_unaccent(?))) + word_similarity(lower(f_unaccent(?)), search_text) DESC, id DESC",
+				Vars:               []interface{}{query, query},
+				WithoutParentheses: true,
+			}}).
+			Limit(limit).
+			Find(&products).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// searchFallback scores the user's products in Go for drivers without
+// unaccent/pg_trgm. Every query word has to match some field (like the AND of
+// websearch_to_tsquery). Lists are per user, so scanning them is cheap.
+func (r *productRepository) searchFallback(userID uint, query string, limit int) ([]*models.Product, error) {
+	tokens := search.Tokenize(query)
+	if len(tokens) == 0 {
+		return []*models.Product{}, nil
+	}
+
+	var all []*models.Product
+	err := r.db.Preload("Category").Preload("Subcategory").
+		Where("user_id = ?", userID).
+		Order(newestFirst).
+		Find(&all).Error
+	if err != nil {
+		return nil, err
+	}
+
+	type scored struct {
+		product *models.Product
+		score   float64
+	}
+	matches := make([]scored, 0, len(all))
+
+	for _, p := range all {
+		fields := []struct {
+			words  []string
+			weight float64
+		}{
+			{search.Tokenize(p.Name), nameWeight},
+			{search.Tokenize(p.Description), descriptionWeight},
+			{search.Tokenize(p.Notes), notesWeight},
+		}
+
+		total := 0.0
+		matched := true
+		for _, token := range tokens {
+			best, ranked := 0.0, 0.0
+			for _, f := range fields {
+				s := search.WordScore(token, f.words)
+				if s > best {
+					best = s
+				}
+				if s*f.weight > ranked {
+					ranked = s * f.weight
+				}
+			}
+			if best < search.MinSimilarity {
+				matched = false
+				break
+			}
+			total += ranked
+		}
+		if matched {
+			matches = append(matches, scored{product: p, score: total})
+		}
+	}
+
+	// Stable: a igual score se mantiene el orden newestFirst
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	products := make([]*models.Product, len(matches))
+	for i, m := range matches {
+		products[i] = m.product
+	}
+	return products, nil
+}
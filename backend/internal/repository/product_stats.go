@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// ProductTotals is one group of the products aggregation: how many products
+// share all the other fields and the sum of their total_price. Amounts stay in
+// their own currency; converting them is up to the caller.
+type ProductTotals struct {
+	CategoryID         uint
+	SubcategoryID      uint
+	CategoryType       string
+	Currency           string
+	IsPurchased        bool
+	RecurrenceInterval *int
+	RecurrenceUnit     *string
+	SubscriptionStatus *string
+	PriceMonth         string // "2026-10", sólo en TotalsByPriceMonth
+	Count              int64
+	Total              money.Amount
+}
+
+// MonthlySpend is what was purchased in a month in one currency
+type MonthlySpend struct {
+	Month    string // "2026-10"
+	Currency string
+	Count    int64
+	Total    money.Amount
+}
+
+// StoreTotals is one group of the one-time purchases of a store: the products
+// in one currency and purchase state, with the sum of each price component
+type StoreTotals struct {
+	StoreID      *uint // nil = sin tienda
+	Currency     string
+	IsPurchased  bool
+	Count        int64
+	BasePrice    money.Amount
+	ShippingCost money.Amount
+	Taxes        money.Amount
+	Total        money.Amount
+}
+
+// Totals aggregates the user's products by category, subcategory, currency,
+// purchase state and recurrence. The groups are few even with thousands of
+// products, so callers can convert and normalize them in Go.
+// Los productos de categorías eliminadas quedan afuera, como en los listados.
+func (r *productRepository) Totals(userID uint) ([]*ProductTotals, error) {
+	return r.totals(userID, "")
+}
+
+// TotalsByPriceMonth is Totals also grouped by the month each price is from,
+// so the groups can be restated with a price index: the purchase_date of
+// purchased products and the price_date of the rest. There are more groups, at
+// most one per month with prices.
+func (r *productRepository) TotalsByPriceMonth(userID uint) ([]*ProductTotals, error) {
+	return r.totals(userID, monthOf(r.db, "COALESCE(CASE WHEN products.is_purchased THEN products.purchase_date END, "+
+		"products.price_date, products.created_at)"))
+}
+
+// totals runs the aggregation, grouping also by the month expression if given
+func (r *productRepository) totals(userID uint, month string) ([]*ProductTotals, error) {
+	columns := "products.category_id, products.subcategory_id, categories.type, products.currency, " +
+		"products.is_purchased, products.recurrence_interval, products.recurrence_unit, products.subscription_status"
+	selected := "products.category_id, products.subcategory_id, categories.type AS category_type, " +
+		"products.currency, products.is_purchased, products.recurrence_interval, products.recurrence_unit, " +
+		"products.subscription_status, COUNT(*) AS count, SUM(products.total_price) AS total"
+	if month != "" {
+		columns += ", " + month
+		selected += ", " + month + " AS price_month"
+	}
+
+	var totals []*ProductTotals
+	err := r.db.Table("products").
+		Select(selected).
+		Joins("JOIN categories ON categories.id = products.category_id AND categories.deleted_at IS NULL").
+		Where("products.user_id = ? AND products.deleted_at IS NULL", userID).
+		Group(columns).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// MonthlySpend sums the products purchased in [from, to) by month and currency,
+// oldest month first. Months without purchases are not returned.
+func (r *productRepository) MonthlySpend(userID uint, from, to time.Time) ([]*MonthlySpend, error) {
+	month := monthOf(r.db, "purchase_date")
+
+	var spend []*MonthlySpend
+	err := r.db.Table("products").
+		Select(month+" AS month, currency, COUNT(*) AS count, SUM(total_price) AS total").
+		Where("user_id = ? AND deleted_at IS NULL AND is_purchased = ?", userID, true).
+		Where("purchase_date >= ? AND purchase_date < ?", from, to).
+		Group(month + ", currency").
+		Order(month + ", currency").
+		Scan(&spend).Error
+	if err != nil {
+		return nil, err
+	}
+	return spend, nil
+}
+
+// StoreTotals aggregates the user's one-time purchases by store, currency and
+// purchase state. Subscriptions aren't bought at a store, so they are left out.
+func (r *productRepository) StoreTotals(userID uint) ([]*StoreTotals, error) {
+	var totals []*StoreTotals
+	err := r.db.Table("products").
+		Select("products.store_id, products.currency, products.is_purchased, COUNT(*) AS count, "+
+			"SUM(products.base_price) AS base_price, SUM(products.shipping_cost) AS shipping_cost, "+
+			"SUM(products.taxes) AS taxes, SUM(products.total_price) AS total").
+		Joins("JOIN categories ON categories.id = products.category_id AND categories.deleted_at IS NULL").
+		Where("products.user_id = ? AND products.deleted_at IS NULL AND categories.type = ?", userID, "one_time").
+		Group("products.store_id, products.currency, products.is_purchased").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
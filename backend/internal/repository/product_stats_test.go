@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/dbtest"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+// statsFixture is a user with one-time and recurring products, plus the rows
+// the aggregations have to leave out
+type statsFixture struct {
+	userID    uint
+	hardware  *models.Category
+	keyboards *models.Subcategory
+	services  *models.Category
+	streaming *models.Subcategory
+}
+
+func day(year int, month time.Month, d int) *time.Time {
+	t := time.Date(year, month, d, 12, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func newStatsFixture(t *testing.T) (*statsFixture, ProductRepository) {
+	db := dbtest.New(t)
+	user := dbtest.CreateUser(t, db, "ana@example.com")
+	other := dbtest.CreateUser(t, db, "beto@example.com")
+
+	f := &statsFixture{
+		userID:   user.ID,
+		hardware: &models.Category{UserID: user.ID, Name: "Hardware", Type: "one_time"},
+		services: &models.Category{UserID: user.ID, Name: "Servicios", Type: "recurring"},
+	}
+	removed := &models.Category{UserID: user.ID, Name: "Vieja", Type: "one_time"}
+	foreign := &models.Category{UserID: other.ID, Name: "Hardware", Type: "one_time"}
+	dbtest.MustCreate(t, db, f.hardware, f.services, removed, foreign)
+
+	f.keyboards = &models.Subcategory{UserID: user.ID, CategoryID: f.hardware.ID, Name: "Teclados"}
+	f.streaming = &models.Subcategory{UserID: user.ID, CategoryID: f.services.ID, Name: "Streaming"}
+	removedSub := &models.Subcategory{UserID: user.ID, CategoryID: removed.ID, Name: "Varios"}
+	foreignSub := &models.Subcategory{UserID: other.ID, CategoryID: foreign.ID, Name: "Teclados"}
+	dbtest.MustCreate(t, db, f.keyboards, f.streaming, removedSub, foreignSub)
+
+	product := func(userID uint, sub *models.Subcategory, price, currency string, priceDate, purchased *time.Time) *models.Product {
+		return &models.Product{
+			UserID: userID, Name: "Producto", BasePrice: money.MustParse(price), Currency: currency,
+			CategoryID: sub.CategoryID, SubcategoryID: sub.ID,
+			PriceDate: priceDate, IsPurchased: purchased != nil, PurchaseDate: purchased,
+		}
+	}
+	month, one := "month", 1
+	subscription := product(user.ID, f.streaming, "15.00", "ARS", day(2026, 2, 1), nil)
+	subscription.RecurrenceUnit, subscription.RecurrenceInterval = &month, &one
+	shipped := product(user.ID, f.keyboards, "100.00", "ARS", day(2026, 3, 10), nil)
+	shipped.ShippingCost = money.MustParse("10.00")
+	deleted := product(user.ID, f.keyboards, "999.00", "ARS", day(2026, 3, 1), nil)
+
+	dbtest.MustCreate(t, db,
+		shipped,
+		product(user.ID, f.keyboards, "50.00", "ARS", day(2026, 3, 20), nil),
+		product(user.ID, f.keyboards, "30.00", "USD", day(2026, 3, 1), nil),
+		// Comprados: cuenta el mes de la compra, no el del precio
+		product(user.ID, f.keyboards, "200.00", "ARS", day(2026, 1, 1), day(2026, 4, 5)),
+		product(user.ID, f.keyboards, "70.00", "ARS", day(2026, 5, 2), day(2026, 5, 31)),
+		product(user.ID, f.keyboards, "40.00", "ARS", day(2026, 5, 2), day(2026, 6, 1)),
+		subscription,
+		deleted,
+		product(user.ID, removedSub, "999.00", "ARS", day(2026, 3, 1), nil),
+		product(other.ID, foreignSub, "999.00", "ARS", day(2026, 3, 1), day(2026, 4, 5)),
+	)
+	if err := db.Delete(deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(removed).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return f, NewProductRepository(db)
+}
+
+// totalsKey identifies a group of ProductTotals in the tests
+func totalsKey(t *ProductTotals) string {
+	recurrence := ""
+	if t.RecurrenceUnit != nil {
+		recurrence = fmt.Sprintf("%d %s", *t.RecurrenceInterval, *t.RecurrenceUnit)
+	}
+	return fmt.Sprintf("%d/%d %s %s purchased=%t %s %s",
+		t.CategoryID, t.SubcategoryID, t.CategoryType, t.Currency, t.IsPurchased, recurrence, t.PriceMonth)
+}
+
+func checkTotals(t *testing.T, got []*ProductTotals, want map[string]string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("got %d groups, want %d", len(got), len(want))
+	}
+	for _, group := range got {
+		key := totalsKey(group)
+		value := fmt.Sprintf("%d %s", group.Count, group.Total)
+		if want[key] != value {
+			t.Errorf("%s = %s, want %q", key, value, want[key])
+		}
+	}
+}
+
+func TestTotals(t *testing.T) {
+	f, repo := newStatsFixture(t)
+
+	got, err := repo.Totals(f.userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hw := fmt.Sprintf("%d/%d one_time", f.hardware.ID, f.keyboards.ID)
+	checkTotals(t, got, map[string]string{
+		hw + " ARS purchased=false  ": "2 160.00",
+		hw + " USD purchased=false  ": "1 30.00",
+		hw + " ARS purchased=true  ":  "3 310.00",
+		fmt.Sprintf("%d/%d recurring ARS purchased=false 1 month ", f.services.ID, f.streaming.ID): "1 15.00",
+	})
+}
+
+func TestTotalsByPriceMonth(t *testing.T) {
+	f, repo := newStatsFixture(t)
+
+	got, err := repo.TotalsByPriceMonth(f.userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hw := fmt.Sprintf("%d/%d one_time", f.hardware.ID, f.keyboards.ID)
+	checkTotals(t, got, map[string]string{
+		hw + " ARS purchased=false  2026-03": "2 160.00",
+		hw + " USD purchased=false  2026-03": "1 30.00",
+		hw + " ARS purchased=true  2026-04":  "1 200.00",
+		hw + " ARS purchased=true  2026-05":  "1 70.00",
+		hw + " ARS purchased=true  2026-06":  "1 40.00",
+		fmt.Sprintf("%d/%d recurring ARS purchased=false 1 month 2026-02", f.services.ID, f.streaming.ID): "1 15.00",
+	})
+}
+
+func TestMonthlySpend(t *testing.T) {
+	f, repo := newStatsFixture(t)
+
+	// [abril, junio): la compra del 1 de junio queda afuera
+	got, err := repo.MonthlySpend(f.userID, *day(2026, 4, 1), time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"2026-04 ARS 1 200.00", "2026-05 ARS 1 70.00"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d months, want %d", len(got), len(want))
+	}
+	for i, spend := range got {
+		if line := fmt.Sprintf("%s %s %d %s", spend.Month, spend.Currency, spend.Count, spend.Total); line != want[i] {
+			t.Errorf("month %d = %q, want %q", i, line, want[i])
+		}
+	}
+}
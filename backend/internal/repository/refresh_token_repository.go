@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository defines the interface for refresh token data operations
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	FindByHash(tokenHash string) (*models.RefreshToken, error)
+	Revoke(id uint) error
+	WithTx(tx *gorm.DB) RefreshTokenRepository
+}
+
+// refreshTokenRepository is the concrete implementation
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new instance of RefreshTokenRepository
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *refreshTokenRepository) WithTx(tx *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: tx}
+}
+
+// Create inserts a new refresh token
+func (r *refreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindByHash retrieves a refresh token by the SHA-256 hash of its value
+func (r *refreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("refresh token not found")
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Revoke marks a refresh token as no longer usable.
+// Fails if it was already revoked, so a token can't be rotated twice concurrently.
+func (r *refreshTokenRepository) Revoke(id uint) error {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("refresh token already revoked")
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ScheduledJobRepository defines the interface for the background jobs' state
+type ScheduledJobRepository interface {
+	FindAll() ([]*models.ScheduledJob, error)
+	FindByName(name string) (*models.ScheduledJob, error)
+	FindOrCreate(job *models.ScheduledJob) error
+	Update(job *models.ScheduledJob) error
+	Start(name string, now, staleBefore time.Time, next *time.Time) (bool, error)
+	Finish(name string, finishedAt time.Time, status, result string, duration time.Duration) error
+}
+
+// scheduledJobRepository is the concrete implementation
+type scheduledJobRepository struct {
+	db *gorm.DB
+}
+
+// NewScheduledJobRepository creates a new instance of ScheduledJobRepository
+func NewScheduledJobRepository(db *gorm.DB) ScheduledJobRepository {
+	return &scheduledJobRepository{db: db}
+}
+
+// FindAll retrieves every job sorted by name
+func (r *scheduledJobRepository) FindAll() ([]*models.ScheduledJob, error) {
+	var jobs []*models.ScheduledJob
+	if err := r.db.Order("name ASC").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// FindByName retrieves a job by its name
+func (r *scheduledJobRepository) FindByName(name string) (*models.ScheduledJob, error) {
+	var job models.ScheduledJob
+	err := r.db.Where("name = ?", name).First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("job not found")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindOrCreate loads the stored state of the job, or stores the given one
+// when the job is new. Either way job ends up with the stored state.
+func (r *scheduledJobRepository) FindOrCreate(job *models.ScheduledJob) error {
+	return r.db.Where("name = ?", job.Name).Attrs(*job).FirstOrCreate(job).Error
+}
+
+// Update saves the schedule, the enabled flag and the next run of a job
+func (r *scheduledJobRepository) Update(job *models.ScheduledJob) error {
+	return r.db.Model(&models.ScheduledJob{}).Where("name = ?", job.Name).Updates(map[string]interface{}{
+		"schedule":    job.Schedule,
+		"enabled":     job.Enabled,
+		"next_run_at": job.NextRunAt,
+	}).Error
+}
+
+// Start marks a job as running, unless it already is. A run that started
+// before staleBefore is considered dead (its instance stopped) and doesn't
+// block. The check and the mark are one UPDATE, so two instances sharing the
+// database can't both start the job.
+func (r *scheduledJobRepository) Start(name string, now, staleBefore time.Time, next *time.Time) (bool, error) {
+	result := r.db.Model(&models.ScheduledJob{}).
+		Where("name = ? AND (running_since IS NULL OR running_since < ?)", name, staleBefore).
+		Updates(map[string]interface{}{
+			"running_since":   now,
+			"last_started_at": now,
+			"next_run_at":     next,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Finish records the outcome of a run and releases the job
+func (r *scheduledJobRepository) Finish(name string, finishedAt time.Time, status, result string, duration time.Duration) error {
+	updates := map[string]interface{}{
+		"running_since":    nil,
+		"last_finished_at": finishedAt,
+		"last_status":      status,
+		"last_result":      result,
+		"last_duration_ms": duration.Milliseconds(),
+		"run_count":        gorm.Expr("run_count + 1"),
+	}
+	if status == models.JobStatusError {
+		updates["failure_count"] = gorm.Expr("failure_count + 1")
+	}
+	return r.db.Model(&models.ScheduledJob{}).Where("name = ?", name).Updates(updates).Error
+}
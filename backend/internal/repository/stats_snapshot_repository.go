@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StatsSnapshotRepository defines the interface for stats snapshot data operations
+type StatsSnapshotRepository interface {
+	Upsert(snapshot *models.StatsSnapshot) error
+	FindAll(userID uint, from, to *time.Time) ([]*models.StatsSnapshot, error)
+	WithTx(tx *gorm.DB) StatsSnapshotRepository
+}
+
+// statsSnapshotRepository is the concrete implementation
+type statsSnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewStatsSnapshotRepository creates a new instance of StatsSnapshotRepository
+func NewStatsSnapshotRepository(db *gorm.DB) StatsSnapshotRepository {
+	return &statsSnapshotRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *statsSnapshotRepository) WithTx(tx *gorm.DB) StatsSnapshotRepository {
+	return &statsSnapshotRepository{db: tx}
+}
+
+// Upsert inserts a snapshot, or replaces the one already taken that day, so
+// running the job twice in a day keeps the latest numbers
+func (r *statsSnapshotRepository) Upsert(snapshot *models.StatsSnapshot) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"currency", "pending_count", "purchased_count", "pending_total", "purchased_total",
+			"total_pending_one_time", "monthly_recurring_cost", "installments_remaining", "updated_at",
+		}),
+	}).Create(snapshot).Error
+}
+
+// FindAll retrieves the user's snapshots, oldest first. from and to are
+// optional bounds on the date (from inclusive, to exclusive).
+func (r *statsSnapshotRepository) FindAll(userID uint, from, to *time.Time) ([]*models.StatsSnapshot, error) {
+	var snapshots []*models.StatsSnapshot
+	query := r.db.Where("user_id = ?", userID)
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+
+	err := query.Order("date ASC").Find(&snapshots).Error
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// StoreRepository defines the interface for store data operations
+type StoreRepository interface {
+	Create(store *models.Store) error
+	FindByID(userID, id uint) (*models.Store, error)
+	FindByDomain(userID uint, domain string) (*models.Store, error)
+	FindAll(userID uint) ([]*models.Store, error)
+	Update(store *models.Store) error
+	Delete(userID, id uint) error
+	ClearTaxProfile(userID, profileID uint) error
+	WithTx(tx *gorm.DB) StoreRepository
+}
+
+// storeRepository is the concrete implementation
+type storeRepository struct {
+	db *gorm.DB
+}
+
+// NewStoreRepository creates a new instance of StoreRepository
+func NewStoreRepository(db *gorm.DB) StoreRepository {
+	return &storeRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *storeRepository) WithTx(tx *gorm.DB) StoreRepository {
+	return &storeRepository{db: tx}
+}
+
+// Create inserts a new store
+func (r *storeRepository) Create(store *models.Store) error {
+	return r.db.Create(store).Error
+}
+
+// FindByID retrieves a store of the user
+func (r *storeRepository) FindByID(userID, id uint) (*models.Store, error) {
+	var store models.Store
+	err := r.db.Where("user_id = ?", userID).First(&store, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("store not found")
+		}
+		return nil, err
+	}
+	return &store, nil
+}
+
+// FindByDomain retrieves the user's store with exactly that domain
+func (r *storeRepository) FindByDomain(userID uint, domain string) (*models.Store, error) {
+	var store models.Store
+	err := r.db.Where("user_id = ? AND domain = ?", userID, domain).First(&store).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("store not found")
+		}
+		return nil, err
+	}
+	return &store, nil
+}
+
+// FindAll retrieves the user's stores sorted by name
+func (r *storeRepository) FindAll(userID uint) ([]*models.Store, error) {
+	var stores []*models.Store
+	err := r.db.Where("user_id = ?", userID).Order("name ASC, id ASC").Find(&stores).Error
+	if err != nil {
+		return nil, err
+	}
+	return stores, nil
+}
+
+// Update updates an existing store
+func (r *storeRepository) Update(store *models.Store) error {
+	return r.db.Save(store).Error
+}
+
+// Delete removes a store
+func (r *storeRepository) Delete(userID, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.Store{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("store not found")
+	}
+	return nil
+}
+
+// ClearTaxProfile unlinks the stores that use a tax profile
+func (r *storeRepository) ClearTaxProfile(userID, profileID uint) error {
+	return r.db.Model(&models.Store{}).
+		Where("user_id = ? AND tax_profile_id = ?", userID, profileID).
+		UpdateColumn("tax_profile_id", nil).Error
+}
@@ -5,16 +5,18 @@ import (
 
 	"github.com/buylist-manager/backend/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // SubcategoryRepository defines the interface for subcategory data operations
 type SubcategoryRepository interface {
 	Create(subcategory *models.Subcategory) error
-	FindByID(id uint) (*models.Subcategory, error)
-	FindAll() ([]*models.Subcategory, error)
-	FindByCategoryID(categoryID uint) ([]*models.Subcategory, error)
+	FindByID(userID, id uint) (*models.Subcategory, error)
+	FindAll(userID uint) ([]*models.Subcategory, error)
+	FindByCategoryID(userID, categoryID uint) ([]*models.Subcategory, error)
 	Update(subcategory *models.Subcategory) error
-	Delete(id uint) error
+	Delete(userID, id uint) error
+	WithTx(tx *gorm.DB) SubcategoryRepository
 }
 
 // subcategoryRepository is the concrete implementation
@@ -27,16 +29,21 @@ func NewSubcategoryRepository(db *gorm.DB) SubcategoryRepository {
 	return &subcategoryRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *subcategoryRepository) WithTx(tx *gorm.DB) SubcategoryRepository {
+	return &subcategoryRepository{db: tx}
+}
+
 // Create inserts a new subcategory into the database
 func (r *subcategoryRepository) Create(subcategory *models.Subcategory) error {
 	return r.db.Create(subcategory).Error
 }
 
 // FindByID retrieves a subcategory by its ID
-func (r *subcategoryRepository) FindByID(id uint) (*models.Subcategory, error) {
+func (r *subcategoryRepository) FindByID(userID, id uint) (*models.Subcategory, error) {
 	var subcategory models.Subcategory
 	// Preload carga la relación Category (como ->with('category') en Laravel)
-	err := r.db.Preload("Category").First(&subcategory, id).Error
+	err := r.db.Preload("Category").Where("user_id = ?", userID).First(&subcategory, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("subcategory not found")
@@ -47,10 +54,10 @@ func (r *subcategoryRepository) FindByID(id uint) (*models.Subcategory, error) {
 }
 
 // FindAll retrieves all subcategories
-func (r *subcategoryRepository) FindAll() ([]*models.Subcategory, error) {
+func (r *subcategoryRepository) FindAll(userID uint) ([]*models.Subcategory, error) {
 	var subcategories []*models.Subcategory
 	// Preload Category para cada subcategory
-	err := r.db.Preload("Category").Find(&subcategories).Error
+	err := r.db.Preload("Category").Where("user_id = ?", userID).Find(&subcategories).Error
 	if err != nil {
 		return nil, err
 	}
@@ -59,23 +66,32 @@ func (r *subcategoryRepository) FindAll() ([]*models.Subcategory, error) {
 
 // FindByCategoryID retrieves all subcategories for a specific category
 // Equivalente en Laravel: Subcategory::where('category_id', $categoryId)->get()
-func (r *subcategoryRepository) FindByCategoryID(categoryID uint) ([]*models.Subcategory, error) {
+func (r *subcategoryRepository) FindByCategoryID(userID, categoryID uint) ([]*models.Subcategory, error) {
 	var subcategories []*models.Subcategory
-	err := r.db.Where("category_id = ?", categoryID).Find(&subcategories).Error
+	err := r.db.Where("user_id = ? AND category_id = ?", userID, categoryID).Find(&subcategories).Error
 	if err != nil {
 		return nil, err
 	}
 	return subcategories, nil
 }
 
-// Update updates an existing subcategory
+// Update updates an existing subcategory. Moved to another category, its
+// products move with it, the deleted ones too.
 func (r *subcategoryRepository) Update(subcategory *models.Subcategory) error {
-	return r.db.Save(subcategory).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Sin asociaciones: la Category precargada pisaría el category_id nuevo
+		if err := tx.Omit(clause.Associations).Save(subcategory).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Product{}).
+			Where("user_id = ? AND subcategory_id = ? AND category_id <> ?", subcategory.UserID, subcategory.ID, subcategory.CategoryID).
+			Update("category_id", subcategory.CategoryID).Error
+	})
 }
 
 // Delete deletes a subcategory by ID
-func (r *subcategoryRepository) Delete(id uint) error {
-	result := r.db.Delete(&models.Subcategory{}, id)
+func (r *subcategoryRepository) Delete(userID, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.Subcategory{}, id)
 	if result.Error != nil {
 		return result.Error
 	}
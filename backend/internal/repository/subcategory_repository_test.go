@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/dbtest"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+func TestSubcategoryUpdateMovesProducts(t *testing.T) {
+	db := dbtest.New(t)
+	user := dbtest.CreateUser(t, db, "ana@example.com")
+
+	hardware := &models.Category{UserID: user.ID, Name: "Hardware", Type: "one_time"}
+	home := &models.Category{UserID: user.ID, Name: "Casa", Type: "one_time"}
+	dbtest.MustCreate(t, db, hardware, home)
+	keyboards := &models.Subcategory{UserID: user.ID, CategoryID: hardware.ID, Name: "Teclados"}
+	mice := &models.Subcategory{UserID: user.ID, CategoryID: hardware.ID, Name: "Mouses"}
+	dbtest.MustCreate(t, db, keyboards, mice)
+
+	product := func(name string, subcategory *models.Subcategory) *models.Product {
+		return &models.Product{
+			UserID: user.ID, Name: name, BasePrice: money.MustParse("100.00"), Currency: "ARS",
+			CategoryID: subcategory.CategoryID, SubcategoryID: subcategory.ID,
+		}
+	}
+	keyboard := product("Keychron K2", keyboards)
+	deleted := product("Teclado viejo", keyboards)
+	mouse := product("Logitech G305", mice)
+	dbtest.MustCreate(t, db, keyboard, deleted, mouse)
+	if err := db.Delete(deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewSubcategoryRepository(db)
+	found, err := repo.FindByID(user.ID, keyboards.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found.CategoryID = home.ID
+	found.Name = "Teclados de casa"
+	if err := repo.Update(found); err != nil {
+		t.Fatal(err)
+	}
+
+	// La Category precargada no pisa el category_id nuevo
+	moved, err := repo.FindByID(user.ID, keyboards.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.CategoryID != home.ID || moved.Name != "Teclados de casa" {
+		t.Errorf("subcategory = category %d %q, want category %d %q", moved.CategoryID, moved.Name, home.ID, "Teclados de casa")
+	}
+
+	tests := []struct {
+		product *models.Product
+		want    uint
+	}{
+		{keyboard, home.ID},
+		{deleted, home.ID}, // También los eliminados, por si se restauran
+		{mouse, hardware.ID},
+	}
+	for _, tt := range tests {
+		var got models.Product
+		if err := db.Unscoped().First(&got, tt.product.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if got.CategoryID != tt.want {
+			t.Errorf("%s: category_id = %d, want %d", tt.product.Name, got.CategoryID, tt.want)
+		}
+	}
+}
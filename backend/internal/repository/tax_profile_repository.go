@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// TaxProfileRepository defines the interface for tax profile data operations
+type TaxProfileRepository interface {
+	Create(profile *models.TaxProfile) error
+	FindByID(userID, id uint) (*models.TaxProfile, error)
+	FindByName(userID uint, name string) (*models.TaxProfile, error)
+	FindAll(userID uint) ([]*models.TaxProfile, error)
+	Update(profile *models.TaxProfile) error
+	Delete(userID, id uint) error
+	WithTx(tx *gorm.DB) TaxProfileRepository
+}
+
+// taxProfileRepository is the concrete implementation
+type taxProfileRepository struct {
+	db *gorm.DB
+}
+
+// NewTaxProfileRepository creates a new instance of TaxProfileRepository
+func NewTaxProfileRepository(db *gorm.DB) TaxProfileRepository {
+	return &taxProfileRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *taxProfileRepository) WithTx(tx *gorm.DB) TaxProfileRepository {
+	return &taxProfileRepository{db: tx}
+}
+
+// rulesInOrder preloads the rules in the order they are applied
+func rulesInOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
+// Create inserts a new profile with its rules
+func (r *taxProfileRepository) Create(profile *models.TaxProfile) error {
+	return r.db.Create(profile).Error
+}
+
+// FindByID retrieves a profile of the user with its rules
+func (r *taxProfileRepository) FindByID(userID, id uint) (*models.TaxProfile, error) {
+	var profile models.TaxProfile
+	err := r.db.Preload("Rules", rulesInOrder).Where("user_id = ?", userID).First(&profile, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tax profile not found")
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// FindByName retrieves the user's profile with exactly that name, without rules
+func (r *taxProfileRepository) FindByName(userID uint, name string) (*models.TaxProfile, error) {
+	var profile models.TaxProfile
+	err := r.db.Where("user_id = ? AND name = ?", userID, name).First(&profile).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("tax profile not found")
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// FindAll retrieves the user's profiles with their rules, sorted by name
+func (r *taxProfileRepository) FindAll(userID uint) ([]*models.TaxProfile, error) {
+	var profiles []*models.TaxProfile
+	err := r.db.Preload("Rules", rulesInOrder).
+		Where("user_id = ?", userID).
+		Order("name ASC, id ASC").
+		Find(&profiles).Error
+	if err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// Update saves a profile and replaces its rules with the given ones. Run it
+// inside a transaction so the old rules aren't lost if the insert fails.
+func (r *taxProfileRepository) Update(profile *models.TaxProfile) error {
+	if err := r.db.Omit("Rules").Save(profile).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("tax_profile_id = ?", profile.ID).Delete(&models.TaxRule{}).Error; err != nil {
+		return err
+	}
+	if len(profile.Rules) == 0 {
+		return nil
+	}
+	for i := range profile.Rules {
+		profile.Rules[i].ID = 0
+		profile.Rules[i].TaxProfileID = profile.ID
+	}
+	return r.db.Create(&profile.Rules).Error
+}
+
+// Delete removes a profile; its rules go with it
+func (r *taxProfileRepository) Delete(userID, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.TaxProfile{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("tax profile not found")
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// Transactor runs a set of repository operations inside a single database transaction
+type Transactor interface {
+	WithinTransaction(fn func(tx *gorm.DB) error) error
+}
+
+// transactor is the concrete implementation
+type transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a new instance of Transactor
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// afterCommitKey is the context key of the afterCommit list of a transaction
+type afterCommitKey struct{}
+
+// afterCommit are the functions waiting for a transaction to commit
+type afterCommit struct {
+	fns []func()
+}
+
+// WithinTransaction commits if fn returns nil and rolls back otherwise.
+// Called with a transaction (a transactor built with WithTx), it runs in a
+// savepoint, and what it queued with AfterCommit moves to the enclosing
+// transaction instead of running: it waits for the outermost one.
+// Laravel: DB::transaction(function () { ... })
+func (t *transactor) WithinTransaction(fn func(tx *gorm.DB) error) error {
+	parent, _ := t.db.Statement.Context.Value(afterCommitKey{}).(*afterCommit)
+	pending := &afterCommit{}
+	db := t.db.WithContext(context.WithValue(t.db.Statement.Context, afterCommitKey{}, pending))
+	if err := db.Transaction(fn); err != nil {
+		return err
+	}
+
+	if parent != nil {
+		parent.fns = append(parent.fns, pending.fns...)
+		return nil
+	}
+	for _, fn := range pending.fns {
+		fn()
+	}
+	return nil
+}
+
+// AfterCommit queues fn to run once the outermost transaction of tx commits.
+// It's dropped if that transaction, or the savepoint tx belongs to, rolls back.
+// Outside a transaction started by a Transactor, fn runs right away.
+func AfterCommit(tx *gorm.DB, fn func()) {
+	pending, ok := tx.Statement.Context.Value(afterCommitKey{}).(*afterCommit)
+	if !ok {
+		fn()
+		return
+	}
+	pending.fns = append(pending.fns, fn)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// UserRepository defines the interface for user data operations
+type UserRepository interface {
+	Create(user *models.User) error
+	FindByID(id uint) (*models.User, error)
+	FindByEmail(email string) (*models.User, error)
+	Count() (int64, error)
+	FindAllIDs() ([]uint, error)
+	FindAdmins() ([]*models.User, error)
+	SetAdmin(email string, admin bool) error
+	ClaimUnownedData(userID uint) (int64, error)
+	WithTx(tx *gorm.DB) UserRepository
+}
+
+// userRepository is the concrete implementation
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository creates a new instance of UserRepository
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *userRepository) WithTx(tx *gorm.DB) UserRepository {
+	return &userRepository{db: tx}
+}
+
+// Create inserts a new user
+func (r *userRepository) Create(user *models.User) error {
+	return r.db.Create(user).Error
+}
+
+// FindByID retrieves a user by its ID
+func (r *userRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	err := r.db.First(&user, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByEmail retrieves a user by its (normalized) email
+func (r *userRepository) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("email = ?", email).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Count returns the number of registered users
+func (r *userRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Count(&count).Error
+	return count, err
+}
+
+// FindAllIDs returns the IDs of every registered user
+func (r *userRepository) FindAllIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.User{}).Order("id ASC").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FindAdmins returns the users with the admin flag
+func (r *userRepository) FindAdmins() ([]*models.User, error) {
+	var users []*models.User
+	err := r.db.Where("is_admin = ?", true).Order("id ASC").Find(&users).Error
+	return users, err
+}
+
+// SetAdmin grants or revokes the admin flag of the user with the email
+func (r *userRepository) SetAdmin(email string, admin bool) error {
+	result := r.db.Model(&models.User{}).Where("email = ?", email).Update("is_admin", admin)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+// ClaimUnownedData assigns rows created before authentication existed (user_id = 0)
+// to the given user. Returns how many categories were claimed.
+func (r *userRepository) ClaimUnownedData(userID uint) (int64, error) {
+	var claimedCategories int64
+	for _, model := range []interface{}{&models.Category{}, &models.Subcategory{}, &models.Product{}, &models.Alert{}} {
+		result := r.db.Unscoped().Model(model).Where("user_id = ?", 0).Update("user_id", userID)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if _, ok := model.(*models.Category); ok {
+			claimedCategories = result.RowsAffected
+		}
+	}
+	return claimedCategories, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// WebhookDeliveryRepository defines the interface for webhook delivery data operations
+type WebhookDeliveryRepository interface {
+	Create(delivery *models.WebhookDelivery) error
+	FindByWebhookID(userID, webhookID uint, status string, limit int) ([]*models.WebhookDelivery, error)
+	FindDue(now time.Time, limit int) ([]*models.WebhookDelivery, error)
+	Claim(id uint, now, until time.Time) (bool, error)
+	Update(delivery *models.WebhookDelivery) error
+	WithTx(tx *gorm.DB) WebhookDeliveryRepository
+}
+
+// webhookDeliveryRepository is the concrete implementation
+type webhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookDeliveryRepository creates a new instance of WebhookDeliveryRepository
+func NewWebhookDeliveryRepository(db *gorm.DB) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *webhookDeliveryRepository) WithTx(tx *gorm.DB) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{db: tx}
+}
+
+// Create inserts a new delivery
+func (r *webhookDeliveryRepository) Create(delivery *models.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+// FindByWebhookID retrieves the latest deliveries of a webhook, newest first,
+// optionally only the ones with a status
+func (r *webhookDeliveryRepository) FindByWebhookID(userID, webhookID uint, status string, limit int) ([]*models.WebhookDelivery, error) {
+	query := r.db.Where("user_id = ? AND webhook_id = ?", userID, webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []*models.WebhookDelivery
+	if err := query.Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// FindDue retrieves the pending deliveries of every user whose next attempt
+// has come, the oldest first, with their webhook
+func (r *webhookDeliveryRepository) FindDue(now time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	err := r.db.Preload("Webhook").
+		Where("status = ? AND next_attempt_at <= ?", models.DeliveryStatusPending, now).
+		Order("next_attempt_at ASC, id ASC").Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Claim reserves a due delivery until the given time so no other worker sends
+// it meanwhile. The check and the reservation are one UPDATE, like the jobs'.
+func (r *webhookDeliveryRepository) Claim(id uint, now, until time.Time) (bool, error) {
+	result := r.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, models.DeliveryStatusPending, now).
+		UpdateColumn("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Update saves the outcome of an attempt
+func (r *webhookDeliveryRepository) Update(delivery *models.WebhookDelivery) error {
+	return r.db.Omit("Webhook").Save(delivery).Error
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// WebhookRepository defines the interface for webhook data operations
+type WebhookRepository interface {
+	Create(webhook *models.Webhook) error
+	FindByID(userID, id uint) (*models.Webhook, error)
+	FindAll(userID uint) ([]*models.Webhook, error)
+	FindActive(userID uint) ([]*models.Webhook, error)
+	Update(webhook *models.Webhook) error
+	Delete(userID, id uint) error
+	WithTx(tx *gorm.DB) WebhookRepository
+}
+
+// webhookRepository is the concrete implementation
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// WithTx returns a copy of the repository bound to the given transaction
+func (r *webhookRepository) WithTx(tx *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: tx}
+}
+
+// Create inserts a new webhook
+func (r *webhookRepository) Create(webhook *models.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+// FindByID retrieves a webhook of the user
+func (r *webhookRepository) FindByID(userID, id uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := r.db.Where("user_id = ?", userID).First(&webhook, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("webhook not found")
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// FindAll retrieves the user's webhooks, oldest first
+func (r *webhookRepository) FindAll(userID uint) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	if err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// FindActive retrieves the user's active webhooks
+func (r *webhookRepository) FindActive(userID uint) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	err := r.db.Where("user_id = ? AND active = ?", userID, true).Order("id ASC").Find(&webhooks).Error
+	if err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// Update updates an existing webhook
+func (r *webhookRepository) Update(webhook *models.Webhook) error {
+	return r.db.Save(webhook).Error
+}
+
+// Delete removes a webhook; its deliveries go with it (ON DELETE CASCADE)
+func (r *webhookRepository) Delete(userID, id uint) error {
+	result := r.db.Where("user_id = ?", userID).Delete(&models.Webhook{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("webhook not found")
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func at(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		from string
+		want string
+	}{
+		// Rangos y pasos
+		{"range within the day", "*/15 8-20 * * 1-5", "2026-10-16 10:07:00", "2026-10-16 10:15:00"},
+		{"range skips the weekend", "*/15 8-20 * * 1-5", "2026-10-16 20:50:00", "2026-10-19 08:00:00"},
+		{"step from a value", "5/20 * * * *", "2026-10-16 10:06:00", "2026-10-16 10:25:00"},
+		{"step from a value wraps the hour", "5/20 * * * *", "2026-10-16 10:45:00", "2026-10-16 11:05:00"},
+		{"range with step", "0 0-23/6 * * *", "2026-10-17 07:00:00", "2026-10-17 12:00:00"},
+		{"list", "0 9,18 * * *", "2026-10-17 09:00:00", "2026-10-17 18:00:00"},
+		{"strictly after from", "0 9 * * *", "2026-10-17 09:00:00", "2026-10-18 09:00:00"},
+		{"seconds are dropped", "*/15 * * * *", "2026-10-17 10:14:30", "2026-10-17 10:15:00"},
+
+		// Día del mes y de la semana: con los dos restringidos alcanza con uno
+		{"dom or dow: day of month first", "0 12 13 * 5", "2026-10-10 00:00:00", "2026-10-13 12:00:00"},
+		{"dom or dow: day of week first", "0 12 13 * 5", "2026-10-14 00:00:00", "2026-10-16 12:00:00"},
+		{"dom only", "0 0 13 * *", "2026-10-14 00:00:00", "2026-11-13 00:00:00"},
+		{"dow only", "0 10 * * 1", "2026-10-17 00:00:00", "2026-10-19 10:00:00"},
+		{"7 is sunday", "0 10 * * 7", "2026-10-17 00:00:00", "2026-10-18 10:00:00"},
+
+		// Cambios de mes y de año
+		{"month without the day", "0 0 31 * *", "2026-04-15 00:00:00", "2026-05-31 00:00:00"},
+		{"month range", "0 0 1 3-5 *", "2026-06-01 00:00:00", "2027-03-01 00:00:00"},
+		{"year rollover", "30 8 1 1 *", "2026-10-17 00:00:00", "2027-01-01 08:30:00"},
+		{"last minute of the year", "59 23 31 12 *", "2026-12-31 23:59:00", "2027-12-31 23:59:00"},
+		{"leap day", "0 0 29 2 *", "2026-03-01 00:00:00", "2028-02-29 00:00:00"},
+
+		// Descriptores
+		{"hourly", "@hourly", "2026-10-17 10:30:00", "2026-10-17 11:00:00"},
+		{"daily", "@daily", "2026-10-17 10:30:00", "2026-10-18 00:00:00"},
+		{"weekly", "@weekly", "2026-10-17 10:30:00", "2026-10-18 00:00:00"},
+		{"monthly", "@monthly", "2026-10-17 10:30:00", "2026-11-01 00:00:00"},
+		{"yearly", "@yearly", "2026-10-17 10:30:00", "2027-01-01 00:00:00"},
+		{"every", "@every 90m", "2026-10-17 10:00:30", "2026-10-17 11:30:30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			if got := schedule.Next(at(tt.from)); !got.Equal(at(tt.want)) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got.Format(time.DateTime), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"0 0 30 2 *", // Nunca corre
+		"@every 30s",
+		"@every soon",
+		"@fortnightly",
+	}
+
+	for _, spec := range tests {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := Parse(spec); err == nil {
+				t.Errorf("Parse(%q) = nil error, want an error", spec)
+			}
+		})
+	}
+}
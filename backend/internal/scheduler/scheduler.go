@@ -0,0 +1,321 @@
+// Package scheduler runs the background jobs of the API (subscription
+// renewals, price refreshes, stats snapshots, backups) on cron-like schedules.
+// The state of each job lives in the database, so the last run survives
+// restarts and several instances sharing the database don't run a job twice.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+var (
+	// ErrUnknownJob is returned for a job that isn't registered
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrJobRunning is returned when starting a job that is already running
+	ErrJobRunning = errors.New("job is already running")
+	// ErrInvalidSchedule is returned when configuring a job with a schedule Parse rejects
+	ErrInvalidSchedule = errors.New("invalid schedule")
+)
+
+const (
+	// tickInterval is how often the scheduler looks for jobs due
+	tickInterval = 30 * time.Second
+	// defaultTimeout limits a run when the job doesn't set its own
+	defaultTimeout = 30 * time.Minute
+)
+
+// Job is a background task. Run returns a short summary of what it did.
+type Job struct {
+	Name     string
+	Schedule string        // Por defecto; el guardado en la base tiene prioridad
+	Timeout  time.Duration // 0 = defaultTimeout
+	// RunOnStart runs the job when the scheduler starts too, if it's enabled
+	RunOnStart bool
+	Run        func(ctx context.Context) (string, error)
+}
+
+// JobStatus is the stored state of a job plus whether this instance is running it
+type JobStatus struct {
+	*models.ScheduledJob
+	Running    bool `json:"running"`    // Corriendo en esta instancia
+	Registered bool `json:"registered"` // false = quedó en la base de una versión anterior
+}
+
+// Scheduler runs the registered jobs when they are due
+type Scheduler struct {
+	repo    repository.ScheduledJobRepository
+	jobs    map[string]*Job
+	order   []string
+	mu      sync.Mutex
+	running map[string]bool
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// New creates a scheduler that keeps the jobs' state in repo
+func New(repo repository.ScheduledJobRepository) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		repo:    repo,
+		jobs:    make(map[string]*Job),
+		running: make(map[string]bool),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Register adds a job and stores it, with its first run scheduled, if it's
+// new. The job shows in Status and can be run with RunNow even if the
+// scheduler is never started. Call it before Start.
+func (s *Scheduler) Register(job Job) error {
+	if _, err := Parse(job.Schedule); err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s registered twice", job.Name)
+	}
+	if job.Timeout == 0 {
+		job.Timeout = defaultTimeout
+	}
+
+	state := &models.ScheduledJob{Name: job.Name, Schedule: job.Schedule, Enabled: true}
+	if err := s.repo.FindOrCreate(state); err != nil {
+		return err
+	}
+	if state.NextRunAt == nil {
+		schedule, err := Parse(state.Schedule)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", job.Name, err)
+		}
+		next := schedule.Next(time.Now())
+		state.NextRunAt = &next
+		if err := s.repo.Update(state); err != nil {
+			return err
+		}
+	}
+
+	s.jobs[job.Name] = &job
+	s.order = append(s.order, job.Name)
+	return nil
+}
+
+// Start moves the jobs that run on start to now, then checks for due jobs in
+// the background until Stop. A job whose run was due while the server was
+// down runs once right away.
+func (s *Scheduler) Start() error {
+	now := time.Now()
+	for _, name := range s.order {
+		if !s.jobs[name].RunOnStart {
+			continue
+		}
+		state, err := s.repo.FindByName(name)
+		if err != nil {
+			return err
+		}
+		state.NextRunAt = &now
+		if err := s.repo.Update(state); err != nil {
+			return err
+		}
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+		for {
+			s.runDue()
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops scheduling, cancels the running jobs and waits for them to
+// return, or until ctx is done
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status lists every stored job
+func (s *Scheduler) Status() ([]*JobStatus, error) {
+	states, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]*JobStatus, 0, len(states))
+	for _, state := range states {
+		_, registered := s.jobs[state.Name]
+		statuses = append(statuses, &JobStatus{
+			ScheduledJob: state,
+			Running:      s.running[state.Name],
+			Registered:   registered,
+		})
+	}
+	return statuses, nil
+}
+
+// Configure changes the schedule and/or the enabled flag of a job. A new
+// schedule moves its next run.
+func (s *Scheduler) Configure(name string, enabled *bool, schedule *string) (*models.ScheduledJob, error) {
+	if _, ok := s.jobs[name]; !ok {
+		return nil, ErrUnknownJob
+	}
+	state, err := s.repo.FindByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule != nil {
+		parsed, err := Parse(*schedule)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		next := parsed.Next(time.Now())
+		state.Schedule, state.NextRunAt = *schedule, &next
+	}
+	if enabled != nil {
+		state.Enabled = *enabled
+	}
+	if err := s.repo.Update(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// RunNow starts a job right away, in the background, even if it's disabled.
+// Its next scheduled run doesn't change.
+func (s *Scheduler) RunNow(name string) error {
+	if _, ok := s.jobs[name]; !ok {
+		return ErrUnknownJob
+	}
+	state, err := s.repo.FindByName(name)
+	if err != nil {
+		return err
+	}
+	if !s.start(state, state.NextRunAt) {
+		return ErrJobRunning
+	}
+	return nil
+}
+
+// runDue starts the enabled jobs whose next run has come
+func (s *Scheduler) runDue() {
+	states, err := s.repo.FindAll()
+	if err != nil {
+		log.Printf("Warning: scheduler failed to load jobs: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, state := range states {
+		if _, ok := s.jobs[state.Name]; !ok || !state.Enabled {
+			continue
+		}
+		if state.NextRunAt != nil && state.NextRunAt.After(now) {
+			continue
+		}
+
+		schedule, err := Parse(state.Schedule)
+		if err != nil {
+			log.Printf("Warning: job %s has an invalid schedule: %v", state.Name, err)
+			continue
+		}
+		next := schedule.Next(now)
+		s.start(state, &next)
+	}
+}
+
+// start runs a job in the background unless it's already running, here or in
+// another instance. It returns whether the job started.
+func (s *Scheduler) start(state *models.ScheduledJob, next *time.Time) bool {
+	job := s.jobs[state.Name]
+
+	s.mu.Lock()
+	if s.running[job.Name] || s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return false
+	}
+	s.running[job.Name] = true
+	s.mu.Unlock()
+
+	release := func() {
+		s.mu.Lock()
+		delete(s.running, job.Name)
+		s.mu.Unlock()
+	}
+
+	// Una corrida que lleva más que el timeout quedó huérfana (su instancia se cayó)
+	startedAt := time.Now()
+	started, err := s.repo.Start(job.Name, startedAt, startedAt.Add(-job.Timeout), next)
+	if err != nil || !started {
+		if err != nil {
+			log.Printf("Warning: failed to start job %s: %v", job.Name, err)
+		}
+		release()
+		return false
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer release()
+		s.run(job, startedAt)
+	}()
+	return true
+}
+
+// run executes a job and stores how it went. A panic counts as a failure.
+func (s *Scheduler) run(job *Job, startedAt time.Time) {
+	ctx, cancel := context.WithTimeout(s.ctx, job.Timeout)
+	defer cancel()
+
+	result, err := func() (result string, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return job.Run(ctx)
+	}()
+
+	status := models.JobStatusSuccess
+	if err != nil {
+		status, result = models.JobStatusError, err.Error()
+		log.Printf("Warning: job %s failed: %v", job.Name, err)
+	} else if result != "" {
+		log.Printf("Job %s: %s", job.Name, result)
+	}
+
+	finishedAt := time.Now()
+	if err := s.repo.Finish(job.Name, finishedAt, status, result, finishedAt.Sub(startedAt)); err != nil {
+		log.Printf("Warning: failed to record run of job %s: %v", job.Name, err)
+	}
+}
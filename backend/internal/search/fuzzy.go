@@ -0,0 +1,126 @@
+// Package search provides accent-insensitive, typo-tolerant text matching.
+// It mirrors what PostgreSQL does with unaccent + pg_trgm, so drivers without
+// those extensions (SQLite) can rank results the same way in Go.
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MinSimilarity is the trigram similarity a word needs to count as a match (a typo or two)
+const MinSimilarity = 0.4
+
+// Normalize lowercases s and strips diacritics ("Cámara" -> "camara")
+func Normalize(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	out, _, err := transform.String(t, s)
+	if err != nil {
+		out = s
+	}
+	return strings.ToLower(out)
+}
+
+// Tokenize normalizes s and splits it into words
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(Normalize(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// trigrams returns the distinct trigrams of a word, padded like pg_trgm ("  w", " wo", ..., "rd ")
+func trigrams(word string) map[string]struct{} {
+	padded := []rune("  " + word + " ")
+	set := make(map[string]struct{}, len(padded))
+	for i := 0; i+3 <= len(padded); i++ {
+		set[string(padded[i:i+3])] = struct{}{}
+	}
+	return set
+}
+
+// Similarity returns how alike two normalized words are (0..1): the trigram
+// similarity, or for words of 4+ letters within two edits (swapped letters
+// included) the share of letters that didn't change, whichever is higher.
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	best := trigramSimilarity(a, b)
+
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if len(ra) >= 4 && len(rb) >= 4 {
+		if d := editDistance(ra, rb); d <= 2 {
+			if s := 1 - float64(d)/float64(longest); s > best {
+				best = s
+			}
+		}
+	}
+	return best
+}
+
+// trigramSimilarity is pg_trgm's similarity(): shared trigrams over all trigrams
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	union := len(ta) + len(tb) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// editDistance is the optimal string alignment distance: insertions, deletions,
+// substitutions and transpositions of adjacent letters each cost 1
+func editDistance(a, b []rune) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
+
+// WordScore returns how well a query token matches a list of words.
+// A prefix match scores 1 (so partial words work while typing); otherwise the
+// best Similarity is used.
+func WordScore(token string, words []string) float64 {
+	best := 0.0
+	for _, w := range words {
+		if strings.HasPrefix(w, token) {
+			return 1
+		}
+		if s := Similarity(token, w); s > best {
+			best = s
+		}
+	}
+	return best
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/notifier"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+// notifyTimeout bounds how long a single alert delivery can take
+const notifyTimeout = 30 * time.Second
+
+// AlertService evaluates price alerts and delivers them through a notifier
+type AlertService interface {
+	EvaluatePriceChange(product *models.Product, previousTotal money.Amount) ([]*models.Alert, error)
+	GetAlerts(userID uint, acknowledged *bool) ([]*models.Alert, error)
+	Acknowledge(userID, id uint) (*models.Alert, error)
+}
+
+// alertService is the concrete implementation
+type alertService struct {
+	alertRepo repository.AlertRepository
+	userRepo  repository.UserRepository
+	notifier  notifier.Notifier
+}
+
+// NewAlertService creates a new instance of AlertService
+func NewAlertService(alertRepo repository.AlertRepository, userRepo repository.UserRepository, n notifier.Notifier) AlertService {
+	return &alertService{
+		alertRepo: alertRepo,
+		userRepo:  userRepo,
+		notifier:  n,
+	}
+}
+
+// EvaluatePriceChange checks the product alert settings against a price change.
+// A target price alert fires only when the price crosses the target (previous
+// above, current at or below), so it is not repeated on every later update.
+// A drop alert fires when the price fell at least TargetDropPercent since the
+// previous price. Triggered alerts are persisted and delivered asynchronously.
+func (s *alertService) EvaluatePriceChange(product *models.Product, previousTotal money.Amount) ([]*models.Alert, error) {
+	current := product.TotalPrice
+	var triggered []*models.Alert
+
+	if product.TargetPrice != nil && previousTotal > *product.TargetPrice && current <= *product.TargetPrice {
+		triggered = append(triggered, &models.Alert{
+			ProductID:     product.ID,
+			Type:          models.AlertTypeTargetPrice,
+			PreviousPrice: previousTotal,
+			CurrentPrice:  current,
+			TargetPrice:   product.TargetPrice,
+			DropPercent:   dropPercent(previousTotal, current),
+			Message: fmt.Sprintf("%s reached the target price: %s %s (target %s, was %s)",
+				product.Name, product.Currency, current, *product.TargetPrice, previousTotal),
+		})
+	}
+
+	if product.TargetDropPercent != nil && previousTotal > 0 {
+		drop := dropPercent(previousTotal, current)
+		if drop >= *product.TargetDropPercent {
+			triggered = append(triggered, &models.Alert{
+				ProductID:     product.ID,
+				Type:          models.AlertTypePriceDrop,
+				PreviousPrice: previousTotal,
+				CurrentPrice:  current,
+				DropPercent:   drop,
+				Message: fmt.Sprintf("%s dropped %.2f%%: %s %s (was %s)",
+					product.Name, drop, product.Currency, current, previousTotal),
+			})
+		}
+	}
+
+	for _, alert := range triggered {
+		alert.UserID = product.UserID
+		if err := s.alertRepo.Create(alert); err != nil {
+			return nil, err
+		}
+		alert.Product = product
+		go s.deliver(alert)
+	}
+
+	return triggered, nil
+}
+
+// GetAlerts lists the user alerts, optionally filtered by acknowledged state
+func (s *alertService) GetAlerts(userID uint, acknowledged *bool) ([]*models.Alert, error) {
+	return s.alertRepo.FindAll(userID, acknowledged)
+}
+
+// Acknowledge marks an alert as seen and returns it updated
+func (s *alertService) Acknowledge(userID, id uint) (*models.Alert, error) {
+	if err := s.alertRepo.Acknowledge(userID, id); err != nil {
+		return nil, err
+	}
+	return s.alertRepo.FindByID(userID, id)
+}
+
+// deliver sends an alert to its owner through the notifier and records the
+// delivery time
+func (s *alertService) deliver(alert *models.Alert) {
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	owner, err := s.userRepo.FindByID(alert.UserID)
+	if err != nil {
+		log.Printf("Warning: failed to deliver alert %d: owner not found: %v", alert.ID, err)
+		return
+	}
+	alert.User = owner
+
+	if err := s.notifier.Notify(ctx, alert); err != nil {
+		log.Printf("Warning: failed to deliver alert %d: %v", alert.ID, err)
+		return
+	}
+
+	if err := s.alertRepo.MarkNotified(alert.ID, time.Now()); err != nil {
+		log.Printf("Warning: failed to mark alert %d as notified: %v", alert.ID, err)
+	}
+}
+
+// dropPercent returns how much the price fell, as a percentage of the previous price
+func dropPercent(previous, current money.Amount) float64 {
+	if previous <= 0 {
+		return 0
+	}
+	return math.Round(float64(previous-current)/float64(previous)*10000) / 100
+}
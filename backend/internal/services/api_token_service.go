@@ -0,0 +1,107 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+// apiTokenScopes are the scopes a token can be created for
+var apiTokenScopes = []string{models.APITokenScopeQuickAdd}
+
+// APITokenService manages the user's API tokens. Unlike the access tokens
+// they don't expire, so each one only works for its scope and can be revoked.
+type APITokenService interface {
+	List(userID uint) ([]*models.APIToken, error)
+	Create(userID uint, name, scope string) (*models.APIToken, string, error)
+	Revoke(userID, id uint) error
+	Authenticate(token, scope string) (uint, error)
+}
+
+// apiTokenService is the concrete implementation
+type apiTokenService struct {
+	repo repository.APITokenRepository
+}
+
+// NewAPITokenService creates a new instance of APITokenService
+func NewAPITokenService(repo repository.APITokenRepository) APITokenService {
+	return &apiTokenService{repo: repo}
+}
+
+// List returns the user's API tokens, without their values
+func (s *apiTokenService) List(userID uint) ([]*models.APIToken, error) {
+	return s.repo.FindAll(userID)
+}
+
+// Create issues a token for the scope. The value is returned only here:
+// just its hash is stored.
+func (s *apiTokenService) Create(userID uint, name, scope string) (*models.APIToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", errors.New("name is required")
+	}
+	if len(name) > 100 {
+		return nil, "", errors.New("name cannot be longer than 100 characters")
+	}
+	if scope == "" {
+		scope = models.APITokenScopeQuickAdd
+	}
+	if !isAPITokenScope(scope) {
+		return nil, "", errors.New("scope must be one of: " + strings.Join(apiTokenScopes, ", "))
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", err
+	}
+	value := models.APITokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	token := &models.APIToken{
+		UserID:    userID,
+		Name:      name,
+		Scope:     scope,
+		TokenHash: hashToken(value),
+	}
+	if err := s.repo.Create(token); err != nil {
+		return nil, "", err
+	}
+	return token, value, nil
+}
+
+// Revoke stops a token from working. It stays listed as revoked.
+func (s *apiTokenService) Revoke(userID, id uint) error {
+	return s.repo.Revoke(userID, id)
+}
+
+// Authenticate returns the owner of a valid, unrevoked token of the scope
+func (s *apiTokenService) Authenticate(token, scope string) (uint, error) {
+	if !strings.HasPrefix(token, models.APITokenPrefix) {
+		return 0, ErrInvalidToken
+	}
+	stored, err := s.repo.FindByHash(hashToken(token))
+	if err != nil || stored.RevokedAt != nil || stored.Scope != scope {
+		return 0, ErrInvalidToken
+	}
+
+	// Sólo informativo: si falla, el token igual sirve
+	if err := s.repo.Touch(stored.ID, time.Now()); err != nil {
+		log.Printf("Warning: failed to record use of api token %d: %v", stored.ID, err)
+	}
+	return stored.UserID, nil
+}
+
+// isAPITokenScope tells whether a token can be created for the scope
+func isAPITokenScope(scope string) bool {
+	for _, s := range apiTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
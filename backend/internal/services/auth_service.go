@@ -0,0 +1,274 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Errores de autenticación que los handlers traducen a 401/409
+var (
+	ErrInvalidCredentials  = errors.New("invalid email or password")
+	ErrEmailTaken          = errors.New("email already registered")
+	ErrInvalidToken        = errors.New("invalid or expired token")
+	ErrInvalidRegistration = errors.New("a valid email and a password of at least 8 characters are required")
+)
+
+// minPasswordLength is the minimum accepted password length
+const minPasswordLength = 8
+
+// TokenPair is returned on login, registration and refresh
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // Segundos de validez del access token
+}
+
+// AuthService handles user accounts and JWT authentication
+type AuthService interface {
+	Register(email, password, name string) (*models.User, *TokenPair, error)
+	Login(email, password string) (*models.User, *TokenPair, error)
+	Refresh(refreshToken string) (*TokenPair, error)
+	Logout(refreshToken string) error
+	ParseAccessToken(token string) (uint, error)
+	GetUser(id uint) (*models.User, error)
+}
+
+// authService is the concrete implementation
+type authService struct {
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	categoryRepo     repository.CategoryRepository
+	subcategoryRepo  repository.SubcategoryRepository
+	transactor       repository.Transactor
+	secret           []byte
+	accessTTL        time.Duration
+	refreshTTL       time.Duration
+}
+
+// NewAuthService creates a new instance of AuthService
+func NewAuthService(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	categoryRepo repository.CategoryRepository,
+	subcategoryRepo repository.SubcategoryRepository,
+	transactor repository.Transactor,
+	secret string,
+	accessTTL, refreshTTL time.Duration,
+) AuthService {
+	return &authService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		categoryRepo:     categoryRepo,
+		subcategoryRepo:  subcategoryRepo,
+		transactor:       transactor,
+		secret:           []byte(secret),
+		accessTTL:        accessTTL,
+		refreshTTL:       refreshTTL,
+	}
+}
+
+// Register creates a new user with its default categories.
+// The first user to register also claims the data created before auth existed.
+func (s *authService) Register(email, password, name string) (*models.User, *TokenPair, error) {
+	email = normalizeEmail(email)
+	if !strings.Contains(email, "@") || len(password) < minPasswordLength {
+		return nil, nil, ErrInvalidRegistration
+	}
+
+	if _, err := s.userRepo.FindByEmail(email); err == nil {
+		return nil, nil, ErrEmailTaken
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user := &models.User{Email: email, Name: name, PasswordHash: string(hash)}
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		users := s.userRepo.WithTx(tx)
+
+		count, err := users.Count()
+		if err != nil {
+			return err
+		}
+		if err := users.Create(user); err != nil {
+			return err
+		}
+
+		if count == 0 {
+			claimed, err := users.ClaimUnownedData(user.ID)
+			if err != nil {
+				return err
+			}
+			if claimed > 0 {
+				return nil // Ya tiene sus categorías de antes
+			}
+		}
+
+		return s.createDefaultCategories(tx, user.ID)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.issueTokens(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokens, nil
+}
+
+// Login validates credentials and issues a new token pair
+func (s *authService) Login(email, password string) (*models.User, *TokenPair, error) {
+	user, err := s.userRepo.FindByEmail(normalizeEmail(email))
+	if err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	tokens, err := s.issueTokens(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokens, nil
+}
+
+// Refresh rotates a refresh token: the old one is revoked and a new pair is issued
+func (s *authService) Refresh(refreshToken string) (*TokenPair, error) {
+	stored, err := s.refreshTokenRepo.FindByHash(hashToken(refreshToken))
+	if err != nil || !stored.IsValid(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+
+	if err := s.refreshTokenRepo.Revoke(stored.ID); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return s.issueTokens(stored.UserID)
+}
+
+// Logout revokes a refresh token (access tokens expire on their own)
+func (s *authService) Logout(refreshToken string) error {
+	stored, err := s.refreshTokenRepo.FindByHash(hashToken(refreshToken))
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if stored.RevokedAt != nil {
+		return nil
+	}
+	return s.refreshTokenRepo.Revoke(stored.ID)
+}
+
+// ParseAccessToken validates a JWT access token and returns the user ID
+func (s *authService) ParseAccessToken(token string) (uint, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+
+	var userID uint
+	if _, err := fmt.Sscan(claims.Subject, &userID); err != nil || userID == 0 {
+		return 0, ErrInvalidToken
+	}
+	return userID, nil
+}
+
+// GetUser returns a user by ID
+func (s *authService) GetUser(id uint) (*models.User, error) {
+	return s.userRepo.FindByID(id)
+}
+
+// issueTokens creates a signed access token and a persisted refresh token
+func (s *authService) issueTokens(userID uint) (*TokenPair, error) {
+	now := time.Now()
+	claims := jwt.RegisteredClaims{
+		Subject:   fmt.Sprint(userID),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTTL)),
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+
+	err = s.refreshTokenRepo.Create(&models.RefreshToken{
+		UserID:    userID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(s.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.accessTTL.Seconds()),
+	}, nil
+}
+
+// createDefaultCategories gives a new user the same starting categories the MVP seeded
+func (s *authService) createDefaultCategories(tx *gorm.DB, userID uint) error {
+	defaults := []struct {
+		name          string
+		categoryType  string
+		subcategories []string
+	}{
+		{"Compra Única", "one_time", []string{"Reparación de Electrónicos", "Trabajo/Productividad", "Gaming", "Hogar"}},
+		{"Suscripción Mensual", "recurring", []string{"IA y Herramientas", "Entretenimiento"}},
+		{"Suscripción Anual", "recurring", []string{"Software Profesional"}},
+	}
+
+	categories := s.categoryRepo.WithTx(tx)
+	subcategories := s.subcategoryRepo.WithTx(tx)
+	for _, d := range defaults {
+		category := &models.Category{UserID: userID, Name: d.name, Type: d.categoryType}
+		if err := categories.Create(category); err != nil {
+			return fmt.Errorf("failed to create default categories: %w", err)
+		}
+		for _, name := range d.subcategories {
+			if err := subcategories.Create(&models.Subcategory{UserID: userID, CategoryID: category.ID, Name: name}); err != nil {
+				return fmt.Errorf("failed to create default subcategories: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeEmail lowercases and trims an email so lookups are case-insensitive
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// hashToken returns the hex SHA-256 of a refresh or API token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+// backupTimeLayout goes in the file names; it sorts chronologically as text
+const backupTimeLayout = "20060102-150405"
+
+// BackupService writes the users' dumps to disk
+type BackupService interface {
+	Backup(now time.Time) (int, error)
+}
+
+// backupService is the concrete implementation
+type backupService struct {
+	userRepo      repository.UserRepository
+	exportService ExportService
+	dir           string
+	keep          int
+}
+
+// NewBackupService creates a new instance of BackupService. It keeps the
+// newest keep backups of each user in dir.
+func NewBackupService(userRepo repository.UserRepository, exportService ExportService, dir string, keep int) BackupService {
+	return &backupService{userRepo: userRepo, exportService: exportService, dir: dir, keep: keep}
+}
+
+// Backup writes the dump of every user to its own JSON file and deletes the
+// user's older backups beyond the ones to keep. Returns how many were written.
+func (s *backupService) Backup(now time.Time) (int, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return 0, err
+	}
+	userIDs, err := s.userRepo.FindAllIDs()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, userID := range userIDs {
+		dump, err := s.exportService.Dump(userID)
+		if err != nil {
+			return i, err
+		}
+		prefix := fmt.Sprintf("buylist-user%d-", userID)
+		if err := writeJSONFile(filepath.Join(s.dir, prefix+now.Format(backupTimeLayout)+".json"), dump); err != nil {
+			return i, err
+		}
+		if err := s.prune(prefix); err != nil {
+			return i + 1, err
+		}
+	}
+	return len(userIDs), nil
+}
+
+// prune deletes the oldest backups with the given prefix beyond s.keep
+func (s *backupService) prune(prefix string) error {
+	files, err := filepath.Glob(filepath.Join(s.dir, prefix+"*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for len(files) > s.keep {
+		if err := os.Remove(files[0]); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// writeJSONFile writes v to a temporary file and renames it, so a backup
+// interrupted halfway never replaces a good one
+func writeJSONFile(path string, v interface{}) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+strings.TrimSuffix(filepath.Base(path), ".json")+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	enc := json.NewEncoder(tmp)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
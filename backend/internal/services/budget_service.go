@@ -0,0 +1,225 @@
+package services
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+// BudgetStatus compares a budget with what its category or subcategory is set
+// to spend. Pending one-time purchases count in full whatever the period;
+// subscriptions count their normalized cost for the period.
+type BudgetStatus struct {
+	CategoryID     uint         `json:"category_id"`
+	SubcategoryID  *uint        `json:"subcategory_id"` // nil = presupuesto de toda la categoría
+	Name           string       `json:"name"`
+	Period         string       `json:"period"`
+	Currency       string       `json:"currency"`
+	Budget         money.Amount `json:"budget"`
+	PendingOneTime money.Amount `json:"pending_one_time"`
+	Recurring      money.Amount `json:"recurring"`
+	Total          money.Amount `json:"total"`
+	Remaining      money.Amount `json:"remaining"` // Negativo si se excede
+	OverBudget     bool         `json:"over_budget"`
+}
+
+// BudgetService manages category and subcategory budgets
+type BudgetService interface {
+	SetCategoryBudget(category *models.Category, budget models.Budget) error
+	SetSubcategoryBudget(subcategory *models.Subcategory, budget models.Budget) error
+	GetStatus(userID uint) ([]*BudgetStatus, error)
+}
+
+// budgetService is the concrete implementation
+type budgetService struct {
+	categoryRepo    repository.CategoryRepository
+	subcategoryRepo repository.SubcategoryRepository
+	productRepo     repository.ProductRepository
+	exchangeRates   ExchangeRateService
+	defaultCurrency string
+}
+
+// NewBudgetService creates a new instance of BudgetService
+func NewBudgetService(
+	categoryRepo repository.CategoryRepository,
+	subcategoryRepo repository.SubcategoryRepository,
+	productRepo repository.ProductRepository,
+	exchangeRates ExchangeRateService,
+	defaultCurrency string,
+) BudgetService {
+	return &budgetService{
+		categoryRepo:    categoryRepo,
+		subcategoryRepo: subcategoryRepo,
+		productRepo:     productRepo,
+		exchangeRates:   exchangeRates,
+		defaultCurrency: defaultCurrency,
+	}
+}
+
+// SetCategoryBudget validates and stores a category's budget. An empty budget removes it.
+func (s *budgetService) SetCategoryBudget(category *models.Category, budget models.Budget) error {
+	if err := s.validateBudget(&budget); err != nil {
+		return err
+	}
+	category.Budget = budget
+	return s.categoryRepo.Update(category)
+}
+
+// SetSubcategoryBudget validates and stores a subcategory's budget. An empty budget removes it.
+func (s *budgetService) SetSubcategoryBudget(subcategory *models.Subcategory, budget models.Budget) error {
+	if err := s.validateBudget(&budget); err != nil {
+		return err
+	}
+	subcategory.Budget = budget
+	return s.subcategoryRepo.Update(subcategory)
+}
+
+// GetStatus returns the status of every budget of the user, category budgets
+// first. Amounts are converted to each budget's currency with today's rates.
+func (s *budgetService) GetStatus(userID uint) ([]*BudgetStatus, error) {
+	categories, err := s.categoryRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	subcategories, err := s.subcategoryRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.productRepo.Totals(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Un conversor por moneda de presupuesto, así cada cotización se busca una vez
+	now := time.Now()
+	converters := make(map[string]*CurrencyConverter)
+	converterFor := func(currency string) *CurrencyConverter {
+		if converters[currency] == nil {
+			converters[currency] = s.exchangeRates.NewConverter(userID, currency, now)
+		}
+		return converters[currency]
+	}
+
+	statuses := []*BudgetStatus{}
+	for _, category := range categories {
+		if !category.HasBudget() {
+			continue
+		}
+		status := newBudgetStatus(category.Budget, category.ID, nil, category.Name)
+		err := status.compute(totals, converterFor(status.Currency), func(row *repository.ProductTotals) bool {
+			return row.CategoryID == category.ID
+		})
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, subcategory := range subcategories {
+		if !subcategory.HasBudget() {
+			continue
+		}
+		id := subcategory.ID
+		status := newBudgetStatus(subcategory.Budget, subcategory.CategoryID, &id, subcategory.Name)
+		err := status.compute(totals, converterFor(status.Currency), func(row *repository.ProductTotals) bool {
+			return row.SubcategoryID == id
+		})
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// validateBudget checks a budget and fills in its defaults: monthly, in the
+// default currency
+func (s *budgetService) validateBudget(budget *models.Budget) error {
+	if !budget.HasBudget() {
+		if budget.BudgetPeriod != nil || budget.BudgetCurrency != nil {
+			return errors.New("budget period and currency require a budget amount")
+		}
+		return nil
+	}
+
+	if budget.BudgetAmount.IsNegative() {
+		return errors.New("budget amount cannot be negative")
+	}
+
+	if budget.BudgetPeriod == nil {
+		period := models.BudgetMonth
+		budget.BudgetPeriod = &period
+	}
+	if !models.IsValidBudgetPeriod(*budget.BudgetPeriod) {
+		return errors.New("budget period must be 'month' or 'year'")
+	}
+
+	code := ""
+	if budget.BudgetCurrency != nil {
+		code = *budget.BudgetCurrency
+	}
+	currency := s.defaultCurrency
+	if code != "" {
+		var err error
+		if currency, err = money.NormalizeCurrency(code); err != nil {
+			return err
+		}
+	}
+	budget.BudgetCurrency = &currency
+	return nil
+}
+
+// newBudgetStatus starts the status of a stored (already validated) budget
+func newBudgetStatus(budget models.Budget, categoryID uint, subcategoryID *uint, name string) *BudgetStatus {
+	return &BudgetStatus{
+		CategoryID:    categoryID,
+		SubcategoryID: subcategoryID,
+		Name:          name,
+		Period:        *budget.BudgetPeriod,
+		Currency:      *budget.BudgetCurrency,
+		Budget:        *budget.BudgetAmount,
+	}
+}
+
+// compute adds up the pending one-time purchases and active subscriptions of
+// the product groups that match the budget
+func (b *BudgetStatus) compute(totals []*repository.ProductTotals, converter *CurrencyConverter, match func(*repository.ProductTotals) bool) error {
+	recurring := new(big.Rat)
+	for _, row := range totals {
+		if !match(row) {
+			continue
+		}
+
+		pendingOneTime := row.CategoryType == "one_time" && !row.IsPurchased
+		recurrence := activeRecurrence(row)
+		if !pendingOneTime && recurrence == nil {
+			continue
+		}
+
+		total, err := converter.Convert(row.Total, row.Currency)
+		if err != nil {
+			return err
+		}
+		if pendingOneTime {
+			b.PendingOneTime = b.PendingOneTime.Add(total)
+		} else {
+			recurring.Add(recurring, yearlyCost(total, *recurrence))
+		}
+	}
+
+	// Como en las estadísticas, el total anual se divide una sola vez
+	if b.Period == models.BudgetMonth {
+		recurring.Quo(recurring, big.NewRat(12, 1))
+	}
+	b.Recurring = money.FromRat(recurring)
+
+	b.Total = b.PendingOneTime.Add(b.Recurring)
+	b.Remaining = b.Budget.Sub(b.Total)
+	b.OverBudget = b.Remaining.IsNegative()
+	return nil
+}
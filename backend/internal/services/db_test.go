@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/dbtest"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// testEnv is a test database with a user and the product service wired over
+// it like in cmd/api (without alerts nor webhooks)
+type testEnv struct {
+	db            *gorm.DB
+	user          *models.User
+	transactor    repository.Transactor
+	exchangeRates ExchangeRateService
+	products      ProductService
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	db := dbtest.New(t)
+	transactor := repository.NewTransactor(db)
+
+	events := NewEventBus()
+	SubscribePurchaseDate(events, repository.NewProductRepository(db))
+	SubscribePriceHistory(events, repository.NewPriceHistoryRepository(db))
+
+	exchangeRates := NewExchangeRateService(repository.NewExchangeRateRepository(db), transactor)
+	priceIndexes := NewPriceIndexService(repository.NewPriceIndexRepository(db), transactor, "ARS", 10)
+	return &testEnv{
+		db:            db,
+		user:          dbtest.CreateUser(t, db, "ana@example.com"),
+		transactor:    transactor,
+		exchangeRates: exchangeRates,
+		products: NewProductService(
+			repository.NewProductRepository(db), repository.NewCategoryRepository(db),
+			repository.NewSubcategoryRepository(db), repository.NewPriceHistoryRepository(db),
+			repository.NewStoreRepository(db), repository.NewTaxProfileRepository(db),
+			transactor, events, exchangeRates, priceIndexes, "ARS",
+		),
+	}
+}
+
+// count returns how many rows of the model the test user has
+func (e *testEnv) count(t *testing.T, model interface{}) int64 {
+	t.Helper()
+	var n int64
+	if err := e.db.Model(model).Where("user_id = ?", e.user.ID).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// createProduct creates a pending one-time product of the test user in ARS,
+// in a new category, through the product service
+func (e *testEnv) createProduct(t *testing.T, name, price string) *models.Product {
+	t.Helper()
+	category := &models.Category{UserID: e.user.ID, Name: name, Type: "one_time"}
+	dbtest.MustCreate(t, e.db, category)
+	subcategory := &models.Subcategory{UserID: e.user.ID, CategoryID: category.ID, Name: name}
+	dbtest.MustCreate(t, e.db, subcategory)
+
+	product := &models.Product{
+		UserID: e.user.ID, Name: name, BasePrice: money.MustParse(price), Currency: "ARS",
+		CategoryID: category.ID, SubcategoryID: subcategory.ID,
+	}
+	if err := e.products.CreateProduct(product); err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
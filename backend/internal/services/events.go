@@ -0,0 +1,154 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Event is a change in the domain, published by the services once it's written
+type Event interface {
+	EventName() string
+}
+
+// ProductCreated is published when a product is created
+type ProductCreated struct {
+	Product *models.Product
+}
+
+// ProductUpdated is published on every save of a product, after the more
+// specific events below
+type ProductUpdated struct {
+	Product  *models.Product
+	Previous *models.Product // Como estaba guardado antes
+}
+
+// ProductPurchased is published when is_purchased becomes true
+type ProductPurchased struct {
+	Product *models.Product
+}
+
+// PriceChanged is published when any component of a product's price changed
+type PriceChanged struct {
+	Product  *models.Product
+	Previous *models.Product
+}
+
+// ProductDeleted is published when a product is deleted, with its last data
+type ProductDeleted struct {
+	Product *models.Product
+}
+
+// EventName returns the name subscribers use
+func (ProductCreated) EventName() string { return models.EventProductCreated }
+
+// EventName returns the name subscribers use
+func (ProductUpdated) EventName() string { return models.EventProductUpdated }
+
+// EventName returns the name subscribers use
+func (ProductPurchased) EventName() string { return models.EventProductPurchased }
+
+// EventName returns the name subscribers use
+func (PriceChanged) EventName() string { return models.EventPriceChanged }
+
+// EventName returns the name subscribers use
+func (ProductDeleted) EventName() string { return models.EventProductDeleted }
+
+// TxHandler runs inside the transaction of the write that published the
+// event. An error rolls the whole write back.
+type TxHandler func(tx *gorm.DB, event Event) error
+
+// Handler runs once the write committed. It can't undo it, so it handles its
+// own errors.
+type Handler func(event Event)
+
+// EventBus delivers the domain events to their subscribers, in the order
+// they subscribed. Subscribe everything before serving requests.
+type EventBus struct {
+	mu          sync.RWMutex
+	txHandlers  map[string][]TxHandler
+	afterCommit map[string][]Handler
+}
+
+// NewEventBus creates a bus without subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{
+		txHandlers:  make(map[string][]TxHandler),
+		afterCommit: make(map[string][]Handler),
+	}
+}
+
+// SubscribeTx runs handler inside the transaction of every event with the name
+func (b *EventBus) SubscribeTx(name string, handler TxHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.txHandlers[name] = append(b.txHandlers[name], handler)
+}
+
+// Subscribe runs handler after the transaction of every event with the name commits
+func (b *EventBus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.afterCommit[name] = append(b.afterCommit[name], handler)
+}
+
+// PublishTx runs the transactional subscribers of the events, stopping at
+// the first error, and queues the after-commit ones on the transaction. Call
+// it inside the transaction of the write, started by a Transactor: the events
+// are dispatched when the outermost transaction commits (an import commits
+// every row at the end), and dropped if it rolls back, dry runs included.
+func (b *EventBus) PublishTx(tx *gorm.DB, events ...Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, event := range events {
+		for _, handler := range b.txHandlers[event.EventName()] {
+			if err := handler(tx, event); err != nil {
+				return fmt.Errorf("%s subscriber: %w", event.EventName(), err)
+			}
+		}
+	}
+
+	repository.AfterCommit(tx, func() { b.Dispatch(events...) })
+	return nil
+}
+
+// Dispatch runs the after-commit subscribers of the events. PublishTx calls
+// it once the transaction committed. A panicking subscriber is logged and
+// doesn't stop the rest.
+func (b *EventBus) Dispatch(events ...Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, event := range events {
+		for _, handler := range b.afterCommit[event.EventName()] {
+			runHandler(handler, event)
+		}
+	}
+}
+
+// runHandler calls an after-commit subscriber recovering from its panics
+func runHandler(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Warning: %s subscriber panicked: %v", event.EventName(), r)
+		}
+	}()
+	handler(event)
+}
+
+// productUpdateEvents lists the events of saving product over previous. The
+// specific ones go first, so what their subscribers change on the product
+// (the purchase date) is in place for the ProductUpdated subscribers.
+func productUpdateEvents(previous, product *models.Product) []Event {
+	var events []Event
+	if product.IsPurchased && !previous.IsPurchased {
+		events = append(events, ProductPurchased{Product: product})
+	}
+	if !previous.HasSamePrice(product) {
+		events = append(events, PriceChanged{Product: product, Previous: previous})
+	}
+	return append(events, ProductUpdated{Product: product, Previous: previous})
+}
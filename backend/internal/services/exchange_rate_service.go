@@ -0,0 +1,238 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrNoExchangeRate is returned when an amount can't be converted because no
+// rate between the two currencies has been loaded
+var ErrNoExchangeRate = errors.New("no exchange rate")
+
+// exchangeRateRequiredColumns must be present in an exchange rate CSV
+var exchangeRateRequiredColumns = []string{"date", "from_currency", "to_currency", "rate"}
+
+// ExchangeRateImportResult summarizes an exchange rate import
+type ExchangeRateImportResult struct {
+	TotalRows int              `json:"total_rows"`
+	Imported  int              `json:"imported"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// ExchangeRateService manages the user's exchange rates and converts amounts with them
+type ExchangeRateService interface {
+	List(userID uint, currency string) ([]*models.ExchangeRate, error)
+	SetRate(rate *models.ExchangeRate) error
+	Delete(userID, id uint) error
+	ImportRates(userID uint, r io.Reader) (*ExchangeRateImportResult, error)
+	NewConverter(userID uint, to string, on time.Time) *CurrencyConverter
+}
+
+// exchangeRateService is the concrete implementation
+type exchangeRateService struct {
+	repo       repository.ExchangeRateRepository
+	transactor repository.Transactor
+}
+
+// NewExchangeRateService creates a new instance of ExchangeRateService
+func NewExchangeRateService(repo repository.ExchangeRateRepository, transactor repository.Transactor) ExchangeRateService {
+	return &exchangeRateService{
+		repo:       repo,
+		transactor: transactor,
+	}
+}
+
+// List returns the user's rates, optionally only the ones involving a currency
+func (s *exchangeRateService) List(userID uint, currency string) ([]*models.ExchangeRate, error) {
+	return s.repo.FindAll(userID, currency)
+}
+
+// SetRate validates a rate and stores it, replacing the rate of the same pair and day
+func (s *exchangeRateService) SetRate(rate *models.ExchangeRate) error {
+	if err := validateExchangeRate(rate); err != nil {
+		return err
+	}
+	return s.repo.Upsert(rate)
+}
+
+// Delete removes an exchange rate
+func (s *exchangeRateService) Delete(userID, id uint) error {
+	return s.repo.Delete(userID, id)
+}
+
+// ImportRates loads rates from a CSV with columns date, from_currency,
+// to_currency and rate. Like the product import it is all or nothing: if any
+// row is invalid nothing is stored.
+func (s *exchangeRateService) ImportRates(userID uint, r io.Reader) (*ExchangeRateImportResult, error) {
+	reader, header, err := newImportReader(r, exchangeRateRequiredColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExchangeRateImportResult{Errors: []ImportRowError{}}
+	var rates []*models.ExchangeRate
+	err = readImportRecords(reader, header, func(line int, row map[string]string) error {
+		result.TotalRows++
+		rate, rowErr := parseExchangeRateRow(row)
+		if rowErr == nil {
+			rate.UserID = userID
+			if err := validateExchangeRate(rate); err != nil {
+				rowErr = &ImportRowError{Message: err.Error()}
+			}
+		}
+		if rowErr != nil {
+			rowErr.Row = line
+			result.Errors = append(result.Errors, *rowErr)
+			return nil
+		}
+		rates = append(rates, rate)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		repo := s.repo.WithTx(tx)
+		for _, rate := range rates {
+			if err := repo.Upsert(rate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(rates)
+	return result, nil
+}
+
+// NewConverter returns a converter to the given currency using the rates in
+// effect on the given day
+func (s *exchangeRateService) NewConverter(userID uint, to string, on time.Time) *CurrencyConverter {
+	return &CurrencyConverter{
+		repo:   s.repo,
+		userID: userID,
+		to:     to,
+		on:     models.DateOnly(on),
+		rates:  make(map[string]conversion),
+	}
+}
+
+// CurrencyConverter converts amounts to a single currency. It remembers the
+// rate found for each source currency, so it is meant for one operation.
+type CurrencyConverter struct {
+	repo   repository.ExchangeRateRepository
+	userID uint
+	to     string
+	on     time.Time
+	rates  map[string]conversion // Por moneda de origen
+}
+
+// conversion is the rate used for a source currency; inverse means the rate
+// was loaded in the opposite direction and the amount is divided by it
+type conversion struct {
+	rate    float64
+	inverse bool
+}
+
+// Convert converts an amount in the from currency. It uses the latest rate
+// loaded on or before the converter's day, in either direction; if both
+// directions exist the most recent one wins.
+func (c *CurrencyConverter) Convert(amount money.Amount, from string) (money.Amount, error) {
+	if from == c.to || amount.IsZero() {
+		return amount, nil
+	}
+
+	conv, ok := c.rates[from]
+	if !ok {
+		direct, err := c.repo.FindLatest(c.userID, from, c.to, c.on)
+		if err != nil {
+			return money.Zero, err
+		}
+		inverse, err := c.repo.FindLatest(c.userID, c.to, from, c.on)
+		if err != nil {
+			return money.Zero, err
+		}
+
+		switch {
+		case direct != nil && (inverse == nil || !inverse.Date.After(direct.Date)):
+			conv = conversion{rate: direct.Rate}
+		case inverse != nil:
+			conv = conversion{rate: inverse.Rate, inverse: true}
+		default:
+			return money.Zero, fmt.Errorf("%w from %s to %s", ErrNoExchangeRate, from, c.to)
+		}
+		c.rates[from] = conv
+	}
+
+	if conv.inverse {
+		return amount.ConvertInverse(conv.rate), nil
+	}
+	return amount.Convert(conv.rate), nil
+}
+
+// validateExchangeRate normalizes the currencies, rounds the rate to the six
+// decimals the column keeps and moves the date to the start of its day
+func validateExchangeRate(rate *models.ExchangeRate) error {
+	var err error
+	if rate.FromCurrency, err = money.NormalizeCurrency(rate.FromCurrency); err != nil {
+		return err
+	}
+	if rate.ToCurrency, err = money.NormalizeCurrency(rate.ToCurrency); err != nil {
+		return err
+	}
+	if rate.FromCurrency == rate.ToCurrency {
+		return errors.New("from and to currencies must be different")
+	}
+
+	rate.Rate = math.Round(rate.Rate*1e6) / 1e6
+	if rate.Rate <= 0 || math.IsInf(rate.Rate, 0) || math.IsNaN(rate.Rate) {
+		return errors.New("rate must be greater than 0")
+	}
+
+	if rate.Date.IsZero() {
+		rate.Date = time.Now()
+	}
+	rate.Date = models.DateOnly(rate.Date)
+	return nil
+}
+
+// parseExchangeRateRow converts the CSV values into a rate (without user)
+func parseExchangeRateRow(row map[string]string) (*models.ExchangeRate, *ImportRowError) {
+	rate := &models.ExchangeRate{
+		FromCurrency: row["from_currency"],
+		ToCurrency:   row["to_currency"],
+	}
+
+	date, err := parseImportDate(row["date"])
+	if err != nil {
+		return nil, &ImportRowError{Field: "date", Message: err.Error()}
+	}
+	if date == nil {
+		return nil, &ImportRowError{Field: "date", Message: "date is required"}
+	}
+	rate.Date = *date
+
+	value := normalizeImportNumber(row["rate"])
+	if value == "" {
+		return nil, &ImportRowError{Field: "rate", Message: "rate is required"}
+	}
+	if rate.Rate, err = strconv.ParseFloat(value, 64); err != nil {
+		return nil, &ImportRowError{Field: "rate", Message: fmt.Sprintf("invalid rate %q", value)}
+	}
+	return rate, nil
+}
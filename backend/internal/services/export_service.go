@@ -0,0 +1,434 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+// Export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// DumpVersion is bumped whenever the dump layout changes
+const DumpVersion = 2
+
+// ExportRow is a flat product with its category and subcategory names resolved.
+// The CSV columns follow the field order.
+type ExportRow struct {
+	ID                 uint         `json:"id"`
+	Name               string       `json:"name"`
+	Description        string       `json:"description"`
+	Category           string       `json:"category"`
+	Subcategory        string       `json:"subcategory"`
+	BasePrice          money.Amount `json:"base_price"`
+	ShippingCost       money.Amount `json:"shipping_cost"`
+	Taxes              money.Amount `json:"taxes"`
+	TotalPrice         money.Amount `json:"total_price"`
+	Currency           string       `json:"currency"`
+	RecurrenceInterval *int         `json:"recurrence_interval"`
+	RecurrenceUnit     *string      `json:"recurrence_unit"`
+	IsPurchased        bool         `json:"is_purchased"`
+	PurchaseDate       *time.Time   `json:"purchase_date"`
+	PriceDate          *time.Time   `json:"price_date"`
+
+	SubscriptionStatus *string    `json:"subscription_status"`
+	StartDate          *time.Time `json:"start_date"`
+	TrialEndsAt        *time.Time `json:"trial_ends_at"`
+	NextBillingDate    *time.Time `json:"next_billing_date"`
+
+	InstallmentCount     *int          `json:"installment_count"`
+	InstallmentRate      *float64      `json:"installment_rate"`
+	InstallmentTotal     *money.Amount `json:"installment_total"`
+	FirstInstallmentDate *time.Time    `json:"first_installment_date"`
+
+	TargetPrice       *money.Amount `json:"target_price"`
+	TargetDropPercent *float64      `json:"target_drop_percent"`
+
+	SourceURL string    `json:"source_url"`
+	ImageURL  string    `json:"image_url"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+var exportCSVHeader = []string{
+	"id", "name", "description", "category", "subcategory",
+	"base_price", "shipping_cost", "taxes", "total_price", "currency",
+	"recurrence_interval", "recurrence_unit", "is_purchased", "purchase_date", "price_date",
+	"subscription_status", "start_date", "trial_ends_at", "next_billing_date",
+	"installment_count", "installment_rate", "installment_total", "first_installment_date",
+	"target_price", "target_drop_percent",
+	"source_url", "image_url", "notes", "created_at", "updated_at",
+}
+
+// Dump is a full copy of a user's data, with ids, for archival
+type Dump struct {
+	Version       int                    `json:"version"`
+	ExportedAt    time.Time              `json:"exported_at"`
+	Categories    []*models.Category     `json:"categories"`
+	Subcategories []*models.Subcategory  `json:"subcategories"`
+	Products      []*models.Product      `json:"products"`
+	PriceHistory  []*models.PriceHistory `json:"price_history"`
+	Alerts        []*models.Alert        `json:"alerts"`
+	ExchangeRates []*models.ExchangeRate `json:"exchange_rates"`
+	PriceIndexes  []*models.PriceIndex   `json:"price_indexes"`
+	Offers        []*models.Offer        `json:"offers"`
+	Stores        []*models.Store        `json:"stores"`
+	TaxProfiles   []*models.TaxProfile   `json:"tax_profiles"`
+	Webhooks      []*models.Webhook      `json:"webhooks"` // Sin el secret ni el log de entregas
+}
+
+// ExportService exports a user's data as CSV or JSON
+type ExportService interface {
+	ExportProducts(userID uint, format string, query repository.ProductQuery) (func(w io.Writer) error, error)
+	Dump(userID uint) (*Dump, error)
+}
+
+// exportService is the concrete implementation
+type exportService struct {
+	categoryRepo     repository.CategoryRepository
+	subcategoryRepo  repository.SubcategoryRepository
+	productRepo      repository.ProductRepository
+	priceHistoryRepo repository.PriceHistoryRepository
+	alertRepo        repository.AlertRepository
+	exchangeRateRepo repository.ExchangeRateRepository
+	priceIndexRepo   repository.PriceIndexRepository
+	offerRepo        repository.OfferRepository
+	storeRepo        repository.StoreRepository
+	taxProfileRepo   repository.TaxProfileRepository
+	webhookRepo      repository.WebhookRepository
+}
+
+// NewExportService creates a new instance of ExportService
+func NewExportService(
+	categoryRepo repository.CategoryRepository,
+	subcategoryRepo repository.SubcategoryRepository,
+	productRepo repository.ProductRepository,
+	priceHistoryRepo repository.PriceHistoryRepository,
+	alertRepo repository.AlertRepository,
+	exchangeRateRepo repository.ExchangeRateRepository,
+	priceIndexRepo repository.PriceIndexRepository,
+	offerRepo repository.OfferRepository,
+	storeRepo repository.StoreRepository,
+	taxProfileRepo repository.TaxProfileRepository,
+	webhookRepo repository.WebhookRepository,
+) ExportService {
+	return &exportService{
+		categoryRepo:     categoryRepo,
+		subcategoryRepo:  subcategoryRepo,
+		productRepo:      productRepo,
+		priceHistoryRepo: priceHistoryRepo,
+		alertRepo:        alertRepo,
+		exchangeRateRepo: exchangeRateRepo,
+		priceIndexRepo:   priceIndexRepo,
+		offerRepo:        offerRepo,
+		storeRepo:        storeRepo,
+		taxProfileRepo:   taxProfileRepo,
+		webhookRepo:      webhookRepo,
+	}
+}
+
+// ExportProducts loads the first batch of products matching the query and
+// returns the function that writes the export to w. The rest are loaded one
+// batch at a time while writing, so large lists are never held in memory; an
+// error loading the first batch is returned before anything is written.
+func (s *exportService) ExportProducts(userID uint, format string, query repository.ProductQuery) (func(w io.Writer) error, error) {
+	if format != ExportFormatCSV && format != ExportFormatJSON {
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	query.Limit, query.Offset, query.Cursor = repository.MaxPageLimit, 0, ""
+	first, err := s.productRepo.List(userID, query)
+	if err != nil {
+		return nil, err
+	}
+	each := func(fn func(*models.Product) error) error {
+		for _, p := range first.Items {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		if first.NextCursor == "" {
+			return nil
+		}
+		rest := query
+		rest.Cursor = first.NextCursor
+		return s.productRepo.ForEach(userID, rest, fn)
+	}
+
+	if format == ExportFormatCSV {
+		return func(w io.Writer) error { return exportCSV(w, each) }, nil
+	}
+	return func(w io.Writer) error { return exportJSON(w, each) }, nil
+}
+
+// exportCSV writes the products that each walks as CSV rows
+func exportCSV(w io.Writer, each func(func(*models.Product) error) error) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportCSVHeader); err != nil {
+		return err
+	}
+
+	err := each(func(p *models.Product) error {
+		row := newExportRow(p)
+		return cw.Write([]string{
+			strconv.FormatUint(uint64(row.ID), 10),
+			csvText(row.Name),
+			csvText(row.Description),
+			csvText(row.Category),
+			csvText(row.Subcategory),
+			csvMoney(row.BasePrice),
+			csvMoney(row.ShippingCost),
+			csvMoney(row.Taxes),
+			csvMoney(row.TotalPrice),
+			row.Currency,
+			csvOptionalInt(row.RecurrenceInterval),
+			csvOptional(row.RecurrenceUnit),
+			strconv.FormatBool(row.IsPurchased),
+			csvTime(row.PurchaseDate),
+			csvTime(row.PriceDate),
+			csvOptional(row.SubscriptionStatus),
+			csvDate(row.StartDate),
+			csvDate(row.TrialEndsAt),
+			csvDate(row.NextBillingDate),
+			csvOptionalInt(row.InstallmentCount),
+			csvOptionalFloat(row.InstallmentRate),
+			csvOptionalMoney(row.InstallmentTotal),
+			csvDate(row.FirstInstallmentDate),
+			csvOptionalMoney(row.TargetPrice),
+			csvOptionalFloat(row.TargetDropPercent),
+			csvText(row.SourceURL),
+			csvText(row.ImageURL),
+			csvText(row.Notes),
+			row.CreatedAt.Format(time.RFC3339),
+			row.UpdatedAt.Format(time.RFC3339),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// exportJSON writes the products that each walks as a JSON array
+func exportJSON(w io.Writer, each func(func(*models.Product) error) error) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	first := true
+	err := each(func(p *models.Product) error {
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		return enc.Encode(newExportRow(p))
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]\n")
+	return err
+}
+
+// Dump loads all of the user's data, exchange rates and price indexes included.
+// Relations are left out so every record appears once; they're linked by id.
+func (s *exportService) Dump(userID uint) (*Dump, error) {
+	categories, err := s.categoryRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	subcategories, err := s.subcategoryRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.productRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.priceHistoryRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := s.alertRepo.FindAll(userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := s.exchangeRateRepo.FindAll(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := s.priceIndexRepo.FindAll(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	offers, err := s.offerRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	stores, err := s.storeRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	taxProfiles, err := s.taxProfileRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	webhooks, err := s.webhookRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range categories {
+		c.Subcategories = nil
+	}
+	for _, sc := range subcategories {
+		sc.Category = nil
+	}
+	for _, p := range products {
+		p.Category, p.Subcategory = nil, nil
+	}
+	for _, a := range alerts {
+		a.Product = nil
+	}
+
+	return &Dump{
+		Version:       DumpVersion,
+		ExportedAt:    time.Now(),
+		Categories:    categories,
+		Subcategories: subcategories,
+		Products:      products,
+		PriceHistory:  history,
+		Alerts:        alerts,
+		ExchangeRates: rates,
+		PriceIndexes:  indexes,
+		Offers:        offers,
+		Stores:        stores,
+		TaxProfiles:   taxProfiles,
+		Webhooks:      webhooks,
+	}, nil
+}
+
+// newExportRow flattens a product with preloaded Category and Subcategory
+func newExportRow(p *models.Product) ExportRow {
+	row := ExportRow{
+		ID:                 p.ID,
+		Name:               p.Name,
+		Description:        p.Description,
+		BasePrice:          p.BasePrice,
+		ShippingCost:       p.ShippingCost,
+		Taxes:              p.Taxes,
+		TotalPrice:         p.TotalPrice,
+		Currency:           p.Currency,
+		RecurrenceInterval: p.RecurrenceInterval,
+		RecurrenceUnit:     p.RecurrenceUnit,
+		IsPurchased:        p.IsPurchased,
+		PurchaseDate:       p.PurchaseDate,
+		PriceDate:          p.PriceDate,
+
+		SubscriptionStatus: p.SubscriptionStatus,
+		StartDate:          p.StartDate,
+		TrialEndsAt:        p.TrialEndsAt,
+		NextBillingDate:    p.NextBillingDate,
+
+		InstallmentCount:     p.InstallmentCount,
+		InstallmentRate:      p.InstallmentRate,
+		InstallmentTotal:     p.InstallmentTotal,
+		FirstInstallmentDate: p.FirstInstallmentDate,
+
+		TargetPrice:       p.TargetPrice,
+		TargetDropPercent: p.TargetDropPercent,
+
+		SourceURL: p.SourceURL,
+		ImageURL:  p.ImageURL,
+		Notes:     p.Notes,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+	if p.Category != nil {
+		row.Category = p.Category.Name
+	}
+	if p.Subcategory != nil {
+		row.Subcategory = p.Subcategory.Name
+	}
+	return row
+}
+
+// csvText escapes text that a spreadsheet would run as a formula (=, +, -, @)
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// csvUntext reverts csvText, so an exported file can be imported back
+func csvUntext(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+func csvMoney(value money.Amount) string {
+	return value.String()
+}
+
+func csvOptional(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func csvOptionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func csvOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func csvOptionalMoney(value *money.Amount) string {
+	if value == nil {
+		return ""
+	}
+	return value.String()
+}
+
+func csvTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
+
+func csvDate(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.Format("2006-01-02")
+}
@@ -0,0 +1,574 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"github.com/buylist-manager/backend/internal/search"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidImport is returned when the uploaded file can't be read as a product CSV
+var ErrInvalidImport = errors.New("invalid import file")
+
+// errImportRollback discards the import transaction (dry run or rows with errors)
+var errImportRollback = errors.New("import rolled back")
+
+// importRequiredColumns must be present in the CSV header
+var importRequiredColumns = []string{"name", "category", "subcategory", "base_price"}
+
+// importColumns are the columns the importer understands. The names match the
+// CSV export, so an exported file can be imported back; id, total_price,
+// next_billing_date (recalculated) and timestamps are ignored. category_type is
+// only used to create new categories, and recurrence_type ("monthly"/"yearly")
+// is still read from files exported before recurrence_interval/unit existed.
+var importColumns = map[string]bool{
+	"name": true, "description": true, "category": true, "category_type": true,
+	"subcategory": true, "base_price": true, "shipping_cost": true, "taxes": true,
+	"recurrence_interval": true, "recurrence_unit": true, "recurrence_type": true,
+	"is_purchased": true, "purchase_date": true, "price_date": true, "currency": true,
+	"source_url": true, "image_url": true, "notes": true, "target_price": true, "target_drop_percent": true,
+	"subscription_status": true, "start_date": true, "trial_ends_at": true,
+	"installment_count": true, "installment_rate": true, "installment_total": true,
+	"first_installment_date": true,
+}
+
+// ImportRowError describes why a CSV row can't be imported
+type ImportRowError struct {
+	Row     int    `json:"row"` // Línea del CSV (el header es la 1)
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult summarizes a product import
+type ImportResult struct {
+	DryRun               bool             `json:"dry_run"`
+	TotalRows            int              `json:"total_rows"`
+	ValidRows            int              `json:"valid_rows"`
+	Imported             int              `json:"imported"`
+	CreatedCategories    []string         `json:"created_categories"`
+	CreatedSubcategories []string         `json:"created_subcategories"`
+	IgnoredColumns       []string         `json:"ignored_columns"`
+	Errors               []ImportRowError `json:"errors"`
+}
+
+// ImportService imports products from CSV files
+type ImportService interface {
+	ImportProducts(userID uint, r io.Reader, dryRun bool) (*ImportResult, error)
+}
+
+// importService is the concrete implementation
+type importService struct {
+	categoryRepo    repository.CategoryRepository
+	subcategoryRepo repository.SubcategoryRepository
+	productService  ProductService
+	transactor      repository.Transactor
+}
+
+// NewImportService creates a new instance of ImportService
+func NewImportService(
+	categoryRepo repository.CategoryRepository,
+	subcategoryRepo repository.SubcategoryRepository,
+	productService ProductService,
+	transactor repository.Transactor,
+) ImportService {
+	return &importService{
+		categoryRepo:    categoryRepo,
+		subcategoryRepo: subcategoryRepo,
+		productService:  productService,
+		transactor:      transactor,
+	}
+}
+
+// importState holds what an import resolves while it runs
+type importState struct {
+	userID        uint
+	categories    map[string]*models.Category    // Por nombre normalizado
+	subcategories map[string]*models.Subcategory // Por categoryID + nombre normalizado
+	result        *ImportResult
+}
+
+// ImportProducts creates one product per CSV row, resolving categories and
+// subcategories by name (case and accent insensitive) and creating the missing
+// ones. Every row goes through ProductService.CreateProduct in its own savepoint.
+// The import is all or nothing: with a dry run, or if any row fails, the
+// transaction is rolled back and the result only reports what would happen.
+func (s *importService) ImportProducts(userID uint, r io.Reader, dryRun bool) (*ImportResult, error) {
+	reader, header, err := newImportReader(r, importRequiredColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		DryRun:               dryRun,
+		CreatedCategories:    []string{},
+		CreatedSubcategories: []string{},
+		IgnoredColumns:       []string{},
+		Errors:               []ImportRowError{},
+	}
+	for _, column := range header {
+		if !importColumns[column] {
+			result.IgnoredColumns = append(result.IgnoredColumns, column)
+		}
+	}
+
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		state, err := s.newImportState(tx, userID, result)
+		if err != nil {
+			return err
+		}
+
+		err = readImportRecords(reader, header, func(line int, row map[string]string) error {
+			result.TotalRows++
+			if rowErr := s.importRow(tx, state, row); rowErr != nil {
+				rowErr.Row = line
+				result.Errors = append(result.Errors, *rowErr)
+				return nil
+			}
+			result.ValidRows++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if dryRun || len(result.Errors) > 0 {
+			return errImportRollback
+		}
+		result.Imported = result.ValidRows
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRollback) {
+		return nil, err
+	}
+	return result, nil
+}
+
+// newImportReader reads the header, detecting ";" as separator (common in
+// spreadsheets with decimal commas), and checks the required columns
+func newImportReader(r io.Reader, required []string) (*csv.Reader, []string, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	text := strings.TrimPrefix(string(content), "\ufeff") // BOM de Excel
+
+	firstLine := text
+	if i := strings.IndexAny(text, "\r\n"); i >= 0 {
+		firstLine = text[:i]
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	if strings.Count(firstLine, ";") > strings.Count(firstLine, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true // Planillas con comillas sueltas (Monitor 27")
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: missing header", ErrInvalidImport)
+	}
+	for i, column := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+	}
+
+	present := make(map[string]bool, len(header))
+	for _, column := range header {
+		present[column] = true
+	}
+	for _, column := range required {
+		if !present[column] {
+			return nil, nil, fmt.Errorf("%w: missing column %q", ErrInvalidImport, column)
+		}
+	}
+	return reader, header, nil
+}
+
+// newImportState loads the user's categories and subcategories for name lookups
+func (s *importService) newImportState(tx *gorm.DB, userID uint, result *ImportResult) (*importState, error) {
+	categories, err := s.categoryRepo.WithTx(tx).FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	subcategories, err := s.subcategoryRepo.WithTx(tx).FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &importState{
+		userID:        userID,
+		categories:    make(map[string]*models.Category, len(categories)),
+		subcategories: make(map[string]*models.Subcategory, len(subcategories)),
+		result:        result,
+	}
+	for _, c := range categories {
+		state.categories[importKey(c.Name)] = c
+	}
+	for _, sc := range subcategories {
+		state.subcategories[subcategoryKey(sc.CategoryID, sc.Name)] = sc
+	}
+	return state, nil
+}
+
+// importRow validates and creates a single product inside a savepoint, so a
+// failing row doesn't leave a half-created category behind
+func (s *importService) importRow(tx *gorm.DB, state *importState, row map[string]string) *ImportRowError {
+	product, rowErr := parseImportRow(row)
+	if rowErr != nil {
+		return rowErr
+	}
+	product.UserID = state.userID
+
+	var created []func()
+	err := repository.NewTransactor(tx).WithinTransaction(func(rowTx *gorm.DB) error {
+		category, onCreate, err := s.resolveCategory(rowTx, state, row)
+		if err != nil {
+			return err
+		}
+		if onCreate != nil {
+			created = append(created, onCreate)
+		}
+
+		subcategory, onCreate, err := s.resolveSubcategory(rowTx, state, category, row["subcategory"])
+		if err != nil {
+			return err
+		}
+		if onCreate != nil {
+			created = append(created, onCreate)
+		}
+
+		product.CategoryID = category.ID
+		product.SubcategoryID = subcategory.ID
+		return s.productService.WithTx(rowTx).CreateProduct(product)
+	})
+	if err != nil {
+		return &ImportRowError{Message: err.Error()}
+	}
+
+	// Recién ahora las categorías nuevas quedan disponibles para las filas siguientes
+	for _, fn := range created {
+		fn()
+	}
+	return nil
+}
+
+// resolveCategory finds the category by name or creates it. The type comes from
+// category_type, or is inferred from the recurrence columns. The returned func
+// registers a new category once its row succeeded.
+func (s *importService) resolveCategory(tx *gorm.DB, state *importState, row map[string]string) (*models.Category, func(), error) {
+	name := row["category"]
+	if name == "" {
+		return nil, nil, errors.New("category is required")
+	}
+	if category, ok := state.categories[importKey(name)]; ok {
+		return category, nil, nil
+	}
+
+	categoryType := strings.ToLower(row["category_type"])
+	if categoryType == "" {
+		categoryType = "one_time"
+		if row["recurrence_unit"] != "" || row["recurrence_type"] != "" {
+			categoryType = "recurring"
+		}
+	}
+	if categoryType != "one_time" && categoryType != "recurring" {
+		return nil, nil, fmt.Errorf("invalid category_type %q (one_time or recurring)", categoryType)
+	}
+
+	category := &models.Category{UserID: state.userID, Name: name, Type: categoryType}
+	if err := s.categoryRepo.WithTx(tx).Create(category); err != nil {
+		return nil, nil, err
+	}
+	return category, func() {
+		state.categories[importKey(name)] = category
+		state.result.CreatedCategories = append(state.result.CreatedCategories, name)
+	}, nil
+}
+
+// resolveSubcategory finds the subcategory by name inside the category or creates it
+func (s *importService) resolveSubcategory(tx *gorm.DB, state *importState, category *models.Category, name string) (*models.Subcategory, func(), error) {
+	if name == "" {
+		return nil, nil, errors.New("subcategory is required")
+	}
+	key := subcategoryKey(category.ID, name)
+	if subcategory, ok := state.subcategories[key]; ok {
+		return subcategory, nil, nil
+	}
+
+	subcategory := &models.Subcategory{UserID: state.userID, Name: name, CategoryID: category.ID}
+	if err := s.subcategoryRepo.WithTx(tx).Create(subcategory); err != nil {
+		return nil, nil, err
+	}
+	return subcategory, func() {
+		state.subcategories[key] = subcategory
+		state.result.CreatedSubcategories = append(state.result.CreatedSubcategories, category.Name+" / "+name)
+	}, nil
+}
+
+// readImportRecords calls fn with every non-blank CSV record as a column -> value
+// map, plus its line number (the header is line 1)
+func readImportRecords(reader *csv.Reader, header []string, fn func(line int, row map[string]string) error) error {
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		line++
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = csvUntext(strings.TrimSpace(record[i]))
+			}
+		}
+		if err := fn(line, row); err != nil {
+			return err
+		}
+	}
+}
+
+// parseImportRow converts the CSV values into a product (without category ids)
+func parseImportRow(row map[string]string) (*models.Product, *ImportRowError) {
+	product := &models.Product{
+		Name:        row["name"],
+		Description: row["description"],
+		Currency:    row["currency"], // Vacío: la moneda por defecto
+		SourceURL:   row["source_url"],
+		ImageURL:    row["image_url"],
+		Notes:       row["notes"],
+	}
+	if product.Name == "" {
+		return nil, &ImportRowError{Field: "name", Message: "name is required"}
+	}
+	if len(product.Name) > 255 {
+		return nil, &ImportRowError{Field: "name", Message: "name is longer than 255 characters"}
+	}
+
+	amounts := []struct {
+		field    string
+		target   *money.Amount
+		required bool
+	}{
+		{"base_price", &product.BasePrice, true},
+		{"shipping_cost", &product.ShippingCost, false},
+		{"taxes", &product.Taxes, false},
+	}
+	for _, a := range amounts {
+		value, err := parseImportAmount(row[a.field])
+		if err != nil {
+			return nil, &ImportRowError{Field: a.field, Message: err.Error()}
+		}
+		if value == nil {
+			if a.required {
+				return nil, &ImportRowError{Field: a.field, Message: a.field + " is required"}
+			}
+			continue
+		}
+		*a.target = *value
+	}
+
+	var err error
+	if product.TargetPrice, err = parseImportAmount(row["target_price"]); err != nil {
+		return nil, &ImportRowError{Field: "target_price", Message: err.Error()}
+	}
+	if product.TargetDropPercent, err = parseImportPercent(row["target_drop_percent"]); err != nil {
+		return nil, &ImportRowError{Field: "target_drop_percent", Message: err.Error()}
+	}
+
+	if unit := strings.ToLower(row["recurrence_unit"]); unit != "" {
+		recurrence := models.Recurrence{Interval: 1, Unit: unit}
+		if value := row["recurrence_interval"]; value != "" {
+			if recurrence.Interval, err = strconv.Atoi(value); err != nil {
+				return nil, &ImportRowError{Field: "recurrence_interval", Message: fmt.Sprintf("invalid interval %q", value)}
+			}
+		}
+		product.SetRecurrence(&recurrence)
+	} else if legacy := strings.ToLower(row["recurrence_type"]); legacy != "" {
+		switch legacy {
+		case "monthly":
+			product.SetRecurrence(&models.Monthly)
+		case "yearly":
+			product.SetRecurrence(&models.Yearly)
+		default:
+			return nil, &ImportRowError{Field: "recurrence_type", Message: "recurrence type must be 'monthly' or 'yearly'"}
+		}
+	}
+
+	if value := row["is_purchased"]; value != "" {
+		purchased, err := parseImportBool(value)
+		if err != nil {
+			return nil, &ImportRowError{Field: "is_purchased", Message: err.Error()}
+		}
+		product.IsPurchased = purchased
+	}
+
+	if product.PurchaseDate, err = parseImportDate(row["purchase_date"]); err != nil {
+		return nil, &ImportRowError{Field: "purchase_date", Message: err.Error()}
+	}
+	if product.PriceDate, err = parseImportDate(row["price_date"]); err != nil {
+		return nil, &ImportRowError{Field: "price_date", Message: err.Error()}
+	}
+	if product.PriceDate == nil {
+		now := time.Now()
+		product.PriceDate = &now
+	}
+	if product.IsPurchased && product.PurchaseDate == nil {
+		product.PurchaseDate = product.PriceDate
+	}
+
+	if status := strings.ToLower(row["subscription_status"]); status != "" {
+		product.SubscriptionStatus = &status
+	}
+	if product.StartDate, err = parseImportDate(row["start_date"]); err != nil {
+		return nil, &ImportRowError{Field: "start_date", Message: err.Error()}
+	}
+	if product.TrialEndsAt, err = parseImportDate(row["trial_ends_at"]); err != nil {
+		return nil, &ImportRowError{Field: "trial_ends_at", Message: err.Error()}
+	}
+
+	if rowErr := parseImportInstallments(row, product); rowErr != nil {
+		return nil, rowErr
+	}
+
+	return product, nil
+}
+
+// parseImportInstallments reads the installment plan columns. Like the API, a
+// plan has a rate or a financed total; with neither it is interest-free. An
+// exported row has both (the total is derived from the rate), so the rate wins.
+func parseImportInstallments(row map[string]string, product *models.Product) *ImportRowError {
+	value := row["installment_count"]
+	if value == "" {
+		return nil
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return &ImportRowError{Field: "installment_count", Message: fmt.Sprintf("invalid installment count %q", value)}
+	}
+	product.InstallmentCount = &count
+
+	if product.InstallmentRate, err = parseImportPercent(row["installment_rate"]); err != nil {
+		return &ImportRowError{Field: "installment_rate", Message: err.Error()}
+	}
+	if product.InstallmentRate == nil {
+		if product.InstallmentTotal, err = parseImportAmount(row["installment_total"]); err != nil {
+			return &ImportRowError{Field: "installment_total", Message: err.Error()}
+		}
+	}
+	if product.InstallmentRate == nil && product.InstallmentTotal == nil {
+		interestFree := 0.0
+		product.InstallmentRate = &interestFree
+	}
+
+	if product.FirstInstallmentDate, err = parseImportDate(row["first_installment_date"]); err != nil {
+		return &ImportRowError{Field: "first_installment_date", Message: err.Error()}
+	}
+	return nil
+}
+
+// normalizeImportNumber accepts "." or "," as decimal separator ("1234.56",
+// "1.234,56", "1,234.56") and returns the number as "1234.56".
+// An empty value returns "".
+func normalizeImportNumber(value string) string {
+	value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "$"))
+
+	// El último separador es el decimal; el otro es de miles
+	lastDot, lastComma := strings.LastIndex(value, "."), strings.LastIndex(value, ",")
+	if lastComma > lastDot {
+		value = strings.ReplaceAll(value, ".", "")
+		return strings.Replace(value, ",", ".", 1)
+	}
+	return strings.ReplaceAll(value, ",", "")
+}
+
+// parseImportAmount parses a non-negative amount. An empty value returns nil.
+func parseImportAmount(value string) (*money.Amount, error) {
+	value = normalizeImportNumber(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	amount, err := money.ParseInput(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+	if amount.IsNegative() {
+		return nil, errors.New("amount cannot be negative")
+	}
+	return &amount, nil
+}
+
+// parseImportPercent parses a percentage. An empty value returns nil.
+func parseImportPercent(value string) (*float64, error) {
+	value = normalizeImportNumber(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	pct, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid percentage %q", value)
+	}
+	return &pct, nil
+}
+
+// parseImportDate accepts YYYY-MM-DD, DD/MM/YYYY or RFC3339. An empty value returns nil.
+func parseImportDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	for _, layout := range []string{"2006-01-02", "02/01/2006"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid date %q (YYYY-MM-DD or DD/MM/YYYY)", value)
+}
+
+// parseImportBool accepts the usual spreadsheet spellings of yes/no
+func parseImportBool(value string) (bool, error) {
+	switch search.Normalize(value) {
+	case "true", "1", "yes", "y", "si", "s", "x":
+		return true, nil
+	case "false", "0", "no", "n":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", value)
+}
+
+// importKey normalizes a name for lookups ("  Compra Unica" == "compra única")
+func importKey(name string) string {
+	return strings.Join(search.Tokenize(name), " ")
+}
+
+func subcategoryKey(categoryID uint, name string) string {
+	return strconv.FormatUint(uint64(categoryID), 10) + ":" + importKey(name)
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
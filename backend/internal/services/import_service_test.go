@@ -0,0 +1,96 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+func newTestImportService(env *testEnv) ImportService {
+	return NewImportService(
+		repository.NewCategoryRepository(env.db), repository.NewSubcategoryRepository(env.db),
+		env.products, env.transactor,
+	)
+}
+
+func TestImportProductsDryRunWritesNothing(t *testing.T) {
+	env := newTestEnv(t)
+
+	result, err := newTestImportService(env).ImportProducts(env.user.ID, strings.NewReader(
+		"name,category,subcategory,base_price\n"+
+			"Teclado,Hardware,Periféricos,100.00\n"+
+			"Mouse,Hardware,Periféricos,50.00\n",
+	), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.ValidRows != 2 || result.Imported != 0 || len(result.Errors) != 0 {
+		t.Errorf("dry run = %d valid, %d imported, errors %v; want 2, 0, none", result.ValidRows, result.Imported, result.Errors)
+	}
+	if !reflect.DeepEqual(result.CreatedCategories, []string{"Hardware"}) {
+		t.Errorf("created categories = %v, want [Hardware]", result.CreatedCategories)
+	}
+	for _, model := range []interface{}{&models.Category{}, &models.Subcategory{}, &models.Product{}} {
+		if n := env.count(t, model); n != 0 {
+			t.Errorf("dry run left %d %T rows, want none", n, model)
+		}
+	}
+}
+
+func TestImportProductsRollsBackTheCategoryOfAFailingRow(t *testing.T) {
+	env := newTestEnv(t)
+
+	// La fila 2 crea "Nueva" y falla al crear el producto: la fila 3 tiene que
+	// volver a crearla en vez de usar la que se deshizo
+	result, err := newTestImportService(env).ImportProducts(env.user.ID, strings.NewReader(
+		"name,category,subcategory,base_price\n"+
+			"Teclado,Nueva,Periféricos,-5.00\n"+
+			"Mouse,Nueva,Periféricos,50.00\n",
+	), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Errors) != 1 || result.Errors[0].Row != 2 {
+		t.Fatalf("errors = %v, want one on row 2", result.Errors)
+	}
+	if result.ValidRows != 1 || result.Imported != 0 {
+		t.Errorf("result = %d valid, %d imported; want 1, 0", result.ValidRows, result.Imported)
+	}
+	if !reflect.DeepEqual(result.CreatedCategories, []string{"Nueva"}) {
+		t.Errorf("created categories = %v, want [Nueva] once", result.CreatedCategories)
+	}
+	// Todo o nada: con una fila con error no se guarda ninguna
+	for _, model := range []interface{}{&models.Category{}, &models.Subcategory{}, &models.Product{}} {
+		if n := env.count(t, model); n != 0 {
+			t.Errorf("failed import left %d %T rows, want none", n, model)
+		}
+	}
+}
+
+func TestImportProductsCommitsValidFiles(t *testing.T) {
+	env := newTestEnv(t)
+
+	result, err := newTestImportService(env).ImportProducts(env.user.ID, strings.NewReader(
+		"name,category,subcategory,base_price\n"+
+			"Teclado,Hardware,Periféricos,100.00\n"+
+			"Mouse,hardware,perifericos,50.00\n", // Mismas categorías, sin importar mayúsculas ni acentos
+	), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Imported != 2 || len(result.Errors) != 0 {
+		t.Fatalf("result = %d imported, errors %v; want 2, none", result.Imported, result.Errors)
+	}
+	if n := env.count(t, &models.Category{}); n != 1 {
+		t.Errorf("%d categories, want 1", n)
+	}
+	if n := env.count(t, &models.Product{}); n != 2 {
+		t.Errorf("%d products, want 2", n)
+	}
+}
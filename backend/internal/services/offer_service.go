@@ -0,0 +1,298 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// OfferQuote is an offer with its total converted to the currency the offers
+// of a product are compared in
+type OfferQuote struct {
+	*models.Offer
+	ConvertedTotal money.Amount `json:"converted_total"`
+	Difference     money.Amount `json:"difference"` // Cuánto más cara que la mejor
+	Best           bool         `json:"best"`
+	Chosen         bool         `json:"chosen"`
+}
+
+// OfferComparison are the offers of a product, cheapest first. The product's
+// price is the one of the effective offer: the chosen one, or else the best.
+type OfferComparison struct {
+	ProductID        uint          `json:"product_id"`
+	Currency         string        `json:"currency"` // La del producto
+	BestOfferID      *uint         `json:"best_offer_id"`
+	ChosenOfferID    *uint         `json:"chosen_offer_id"`
+	EffectiveOfferID *uint         `json:"effective_offer_id"`
+	Offers           []*OfferQuote `json:"offers"`
+}
+
+// OfferService manages the offers of a product and keeps its price in sync
+// with the effective offer. The offers of a purchased product can't change:
+// it keeps the price it was bought at.
+type OfferService interface {
+	Compare(product *models.Product) (*OfferComparison, error)
+	AddOffer(product *models.Product, offer *models.Offer) error
+	UpdateOffer(product *models.Product, offer *models.Offer) error
+	DeleteOffer(product *models.Product, id uint) error
+	ChooseOffer(product *models.Product, id *uint) error
+}
+
+// offerService is the concrete implementation
+type offerService struct {
+	offerRepo      repository.OfferRepository
+	transactor     repository.Transactor
+	productService ProductService
+	exchangeRates  ExchangeRateService
+}
+
+// NewOfferService creates a new instance of OfferService
+func NewOfferService(
+	offerRepo repository.OfferRepository,
+	transactor repository.Transactor,
+	productService ProductService,
+	exchangeRates ExchangeRateService,
+) OfferService {
+	return &offerService{
+		offerRepo:      offerRepo,
+		transactor:     transactor,
+		productService: productService,
+		exchangeRates:  exchangeRates,
+	}
+}
+
+// Compare returns the product's offers, converted to its currency with
+// today's rates, cheapest first
+func (s *offerService) Compare(product *models.Product) (*OfferComparison, error) {
+	offers, err := s.offerRepo.FindByProductID(product.UserID, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.compare(product, offers)
+}
+
+// AddOffer validates and stores a new offer, then moves the product to the
+// effective offer's price
+func (s *offerService) AddOffer(product *models.Product, offer *models.Offer) error {
+	if product.IsPurchased {
+		return ErrPurchasedPrice
+	}
+	offer.UserID, offer.ProductID = product.UserID, product.ID
+	if err := s.validateOffer(offer); err != nil {
+		return err
+	}
+
+	offers, err := s.offerRepo.FindByProductID(product.UserID, product.ID)
+	if err != nil {
+		return err
+	}
+	// Se compara antes de guardar: si falta una cotización no queda nada a medias
+	comparison, err := s.compare(product, append(offers, offer))
+	if err != nil {
+		return err
+	}
+
+	return s.save(product, comparison.effective(), func(offers repository.OfferRepository) error {
+		return offers.Create(offer)
+	})
+}
+
+// UpdateOffer validates and stores an existing offer, then moves the product
+// to the effective offer's price
+func (s *offerService) UpdateOffer(product *models.Product, offer *models.Offer) error {
+	if product.IsPurchased {
+		return ErrPurchasedPrice
+	}
+	if err := s.validateOffer(offer); err != nil {
+		return err
+	}
+
+	offers, err := s.offerRepo.FindByProductID(product.UserID, product.ID)
+	if err != nil {
+		return err
+	}
+	for i, o := range offers {
+		if o.ID == offer.ID {
+			offers[i] = offer
+		}
+	}
+	comparison, err := s.compare(product, offers)
+	if err != nil {
+		return err
+	}
+
+	return s.save(product, comparison.effective(), func(offers repository.OfferRepository) error {
+		return offers.Update(offer)
+	})
+}
+
+// DeleteOffer removes an offer. If it was the chosen one, the product goes
+// back to the best offer; without offers it keeps its last price.
+func (s *offerService) DeleteOffer(product *models.Product, id uint) error {
+	if product.IsPurchased {
+		return ErrPurchasedPrice
+	}
+	offers, err := s.offerRepo.FindByProductID(product.UserID, product.ID)
+	if err != nil {
+		return err
+	}
+	remaining := make([]*models.Offer, 0, len(offers))
+	for _, o := range offers {
+		if o.ID != id {
+			remaining = append(remaining, o)
+		}
+	}
+	if product.ChosenOfferID != nil && *product.ChosenOfferID == id {
+		product.ChosenOfferID = nil
+	}
+	comparison, err := s.compare(product, remaining)
+	if err != nil {
+		return err
+	}
+
+	return s.save(product, comparison.effective(), func(offers repository.OfferRepository) error {
+		return offers.Delete(product.UserID, product.ID, id)
+	})
+}
+
+// ChooseOffer pins the product's price to one of its offers. A nil id goes
+// back to the best offer.
+func (s *offerService) ChooseOffer(product *models.Product, id *uint) error {
+	if product.IsPurchased {
+		return ErrPurchasedPrice
+	}
+	if id != nil {
+		if _, err := s.offerRepo.FindByID(product.UserID, product.ID, *id); err != nil {
+			return err
+		}
+	}
+	product.ChosenOfferID = id
+	comparison, err := s.Compare(product)
+	if err != nil {
+		return err
+	}
+	return s.apply(s.productService, product, comparison.effective())
+}
+
+// save runs write and the product's move to the effective offer in one
+// transaction, so an offer is never stored with the product at another price
+func (s *offerService) save(product *models.Product, effective *models.Offer, write func(offers repository.OfferRepository) error) error {
+	return s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := write(s.offerRepo.WithTx(tx)); err != nil {
+			return err
+		}
+		return s.apply(s.productService.WithTx(tx), product, effective)
+	})
+}
+
+// apply copies the effective offer, if any, to the product and saves it. The
+// offer's prices are converted to the product's currency with today's rates,
+// the ones the offers were compared with.
+func (s *offerService) apply(productService ProductService, product *models.Product, effective *models.Offer) error {
+	if effective != nil {
+		converter := s.exchangeRates.NewConverter(product.UserID, product.Currency, time.Now())
+		err := effective.ApplyTo(product, func(amount money.Amount) (money.Amount, error) {
+			return converter.Convert(amount, effective.Currency)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// Pasa por UpdateProduct: si cambia el precio queda en el histórico y se evalúan las alertas
+	return productService.UpdateProduct(product)
+}
+
+// compare converts the offers to the product's currency and sorts them, the
+// cheapest first (ties keep the oldest offer first). A chosen offer that is not
+// among them is cleared.
+func (s *offerService) compare(product *models.Product, offers []*models.Offer) (*OfferComparison, error) {
+	comparison := &OfferComparison{
+		ProductID: product.ID,
+		Currency:  product.Currency,
+		Offers:    make([]*OfferQuote, 0, len(offers)),
+	}
+
+	converter := s.exchangeRates.NewConverter(product.UserID, product.Currency, time.Now())
+	chosenFound := false
+	for _, offer := range offers {
+		total, err := converter.Convert(offer.CalculateTotal(), offer.Currency)
+		if err != nil {
+			return nil, err
+		}
+		quote := &OfferQuote{Offer: offer, ConvertedTotal: total}
+		if product.ChosenOfferID != nil && *product.ChosenOfferID == offer.ID {
+			quote.Chosen = true
+			chosenFound = true
+		}
+		comparison.Offers = append(comparison.Offers, quote)
+	}
+	if !chosenFound {
+		product.ChosenOfferID = nil
+	}
+	comparison.ChosenOfferID = product.ChosenOfferID
+
+	sort.SliceStable(comparison.Offers, func(i, j int) bool {
+		return comparison.Offers[i].ConvertedTotal < comparison.Offers[j].ConvertedTotal
+	})
+	if len(comparison.Offers) > 0 {
+		best := comparison.Offers[0]
+		best.Best = true
+		comparison.BestOfferID = &best.ID
+		for _, quote := range comparison.Offers {
+			quote.Difference = quote.ConvertedTotal.Sub(best.ConvertedTotal)
+		}
+	}
+
+	if effective := comparison.effective(); effective != nil {
+		comparison.EffectiveOfferID = &effective.ID
+	}
+	return comparison, nil
+}
+
+// effective returns the chosen offer, or else the best one. Nil without offers.
+func (c *OfferComparison) effective() *models.Offer {
+	for _, quote := range c.Offers {
+		if quote.Chosen {
+			return quote.Offer
+		}
+	}
+	if len(c.Offers) > 0 {
+		return c.Offers[0].Offer
+	}
+	return nil
+}
+
+// validateOffer checks the store and prices and fills in the defaults: the
+// default currency and observed now
+func (s *offerService) validateOffer(offer *models.Offer) error {
+	offer.Store = strings.TrimSpace(offer.Store)
+	if offer.Store == "" {
+		return errors.New("store is required")
+	}
+	if len(offer.Store) > 100 {
+		return errors.New("store cannot be longer than 100 characters")
+	}
+	if len(offer.URL) > 500 {
+		return errors.New("url cannot be longer than 500 characters")
+	}
+
+	if offer.BasePrice.IsNegative() || offer.ShippingCost.IsNegative() || offer.Taxes.IsNegative() {
+		return errors.New("prices cannot be negative")
+	}
+
+	var err error
+	if offer.Currency, err = s.productService.ResolveCurrency(offer.Currency); err != nil {
+		return err
+	}
+
+	if offer.ObservedAt.IsZero() {
+		offer.ObservedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+func TestOfferInAnotherCurrencyKeepsTheProductCurrency(t *testing.T) {
+	env := newTestEnv(t)
+	err := env.exchangeRates.SetRate(&models.ExchangeRate{
+		UserID: env.user.ID, FromCurrency: "USD", ToCurrency: "ARS", Rate: 1000, Date: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	product := env.createProduct(t, "Teclado", "120000.00")
+	offers := NewOfferService(repository.NewOfferRepository(env.db), env.transactor, env.products, env.exchangeRates)
+
+	local := &models.Offer{UserID: env.user.ID, ProductID: product.ID, Store: "Local", BasePrice: money.MustParse("120000.00"), Currency: "ARS"}
+	if err := offers.AddOffer(product, local); err != nil {
+		t.Fatal(err)
+	}
+	// 100 + 5 USD = 105000 ARS: es la más barata
+	amazon := &models.Offer{
+		UserID: env.user.ID, ProductID: product.ID, Store: "Amazon", URL: "https://www.amazon.com/dp/B0TEST",
+		BasePrice: money.MustParse("100.00"), ShippingCost: money.MustParse("5.00"), Currency: "USD",
+	}
+	if err := offers.AddOffer(product, amazon); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := repository.NewProductRepository(env.db).FindByID(env.user.ID, product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Currency != "ARS" || saved.BasePrice != money.MustParse("100000.00") ||
+		saved.ShippingCost != money.MustParse("5000.00") || saved.TotalPrice != money.MustParse("105000.00") {
+		t.Errorf("product = %s %s + %s = %s, want ARS 100000.00 + 5000.00 = 105000.00",
+			saved.Currency, saved.BasePrice, saved.ShippingCost, saved.TotalPrice)
+	}
+	if saved.SourceURL != amazon.URL {
+		t.Errorf("source_url = %q, want the offer's %q", saved.SourceURL, amazon.URL)
+	}
+
+	// La comparación sigue en la moneda del producto, con la oferta en dólares primero
+	comparison, err := offers.Compare(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comparison.Currency != "ARS" || len(comparison.Offers) != 2 || comparison.Offers[0].ID != amazon.ID ||
+		comparison.Offers[0].ConvertedTotal != money.MustParse("105000.00") {
+		t.Errorf("comparison in %s = %+v, want ARS with Amazon first at 105000.00", comparison.Currency, comparison.Offers)
+	}
+}
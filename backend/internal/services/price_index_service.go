@@ -0,0 +1,311 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrNoPriceIndex is returned when a price can't be restated because the index
+// of its currency doesn't reach back to the price's month
+var ErrNoPriceIndex = errors.New("no price index")
+
+// priceIndexRequiredColumns must be present in a price index CSV. currency is
+// optional and defaults to the default currency.
+var priceIndexRequiredColumns = []string{"month", "value"}
+
+// PriceIndexImportResult summarizes a price index import
+type PriceIndexImportResult struct {
+	TotalRows int              `json:"total_rows"`
+	Imported  int              `json:"imported"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// PriceIndexService manages the user's price indexes and restates prices with them
+type PriceIndexService interface {
+	List(userID uint, currency string) ([]*models.PriceIndex, error)
+	SetIndex(index *models.PriceIndex) error
+	Delete(userID, id uint) error
+	ImportIndexes(userID uint, r io.Reader) (*PriceIndexImportResult, error)
+	NewAdjuster(userID uint, to time.Time) *InflationAdjuster
+}
+
+// priceIndexService is the concrete implementation
+type priceIndexService struct {
+	repo            repository.PriceIndexRepository
+	transactor      repository.Transactor
+	defaultCurrency string
+	staleThreshold  float64
+}
+
+// NewPriceIndexService creates a new instance of PriceIndexService. A price is
+// stale once inflation since its date reaches staleThreshold percent.
+func NewPriceIndexService(
+	repo repository.PriceIndexRepository,
+	transactor repository.Transactor,
+	defaultCurrency string,
+	staleThreshold float64,
+) PriceIndexService {
+	return &priceIndexService{
+		repo:            repo,
+		transactor:      transactor,
+		defaultCurrency: defaultCurrency,
+		staleThreshold:  staleThreshold,
+	}
+}
+
+// List returns the user's index values, optionally only the ones of a currency
+func (s *priceIndexService) List(userID uint, currency string) ([]*models.PriceIndex, error) {
+	return s.repo.FindAll(userID, currency)
+}
+
+// SetIndex validates an index value and stores it, replacing the value of the
+// same currency and month
+func (s *priceIndexService) SetIndex(index *models.PriceIndex) error {
+	if err := s.validatePriceIndex(index); err != nil {
+		return err
+	}
+	return s.repo.Upsert(index)
+}
+
+// Delete removes an index value
+func (s *priceIndexService) Delete(userID, id uint) error {
+	return s.repo.Delete(userID, id)
+}
+
+// ImportIndexes loads index values from a CSV with columns month, value and
+// optionally currency. Like the other imports it is all or nothing.
+func (s *priceIndexService) ImportIndexes(userID uint, r io.Reader) (*PriceIndexImportResult, error) {
+	reader, header, err := newImportReader(r, priceIndexRequiredColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PriceIndexImportResult{Errors: []ImportRowError{}}
+	var indexes []*models.PriceIndex
+	err = readImportRecords(reader, header, func(line int, row map[string]string) error {
+		result.TotalRows++
+		index, rowErr := parsePriceIndexRow(row)
+		if rowErr == nil {
+			index.UserID = userID
+			if err := s.validatePriceIndex(index); err != nil {
+				rowErr = &ImportRowError{Message: err.Error()}
+			}
+		}
+		if rowErr != nil {
+			rowErr.Row = line
+			result.Errors = append(result.Errors, *rowErr)
+			return nil
+		}
+		indexes = append(indexes, index)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	err = s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		repo := s.repo.WithTx(tx)
+		for _, index := range indexes {
+			if err := repo.Upsert(index); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Imported = len(indexes)
+	return result, nil
+}
+
+// NewAdjuster returns an adjuster that restates prices in the money of the
+// given month
+func (s *priceIndexService) NewAdjuster(userID uint, to time.Time) *InflationAdjuster {
+	return &InflationAdjuster{
+		repo:           s.repo,
+		userID:         userID,
+		to:             models.MonthOf(to),
+		staleThreshold: s.staleThreshold,
+		series:         make(map[string][]*models.PriceIndex),
+	}
+}
+
+// InflationAdjuster restates prices in the money of one month. It loads each
+// currency's index once, so it is meant for one operation.
+type InflationAdjuster struct {
+	repo           repository.PriceIndexRepository
+	userID         uint
+	to             time.Time
+	staleThreshold float64
+	series         map[string][]*models.PriceIndex // Por moneda, del mes más viejo al más nuevo
+}
+
+// Month returns the month prices are restated to ("2026-10")
+func (a *InflationAdjuster) Month() string {
+	return a.to.Format("2006-01")
+}
+
+// Factor returns how much prices in a currency changed from a month to the
+// adjuster's month: index(to) / index(from). Each month uses the latest value
+// loaded on or before it, since the CPI is published with a delay. A currency
+// without any index returns nil: its prices are left as they are.
+func (a *InflationAdjuster) Factor(currency string, from time.Time) (*big.Rat, error) {
+	series, ok := a.series[currency]
+	if !ok {
+		var err error
+		if series, err = a.repo.FindSeries(a.userID, currency); err != nil {
+			return nil, err
+		}
+		a.series[currency] = series
+	}
+	if len(series) == 0 {
+		return nil, nil
+	}
+
+	base := indexOn(series, models.MonthOf(from))
+	if base == nil {
+		return nil, fmt.Errorf("%w for %s on or before %s", ErrNoPriceIndex, currency, from.Format("2006-01"))
+	}
+	target := indexOn(series, a.to)
+	if target == nil {
+		return nil, fmt.Errorf("%w for %s on or before %s", ErrNoPriceIndex, currency, a.Month())
+	}
+
+	factor, _ := new(big.Rat).SetString(strconv.FormatFloat(target.Value, 'f', -1, 64))
+	baseValue, _ := new(big.Rat).SetString(strconv.FormatFloat(base.Value, 'f', -1, 64))
+	return factor.Quo(factor, baseValue), nil
+}
+
+// Adjust restates an amount in a currency priced in the month of from
+func (a *InflationAdjuster) Adjust(amount money.Amount, currency string, from time.Time) (money.Amount, error) {
+	if amount.IsZero() {
+		return amount, nil
+	}
+	factor, err := a.Factor(currency, from)
+	if err != nil || factor == nil {
+		return amount, err
+	}
+	return amount.MulRat(factor), nil
+}
+
+// AdjustProduct sets the product's Inflation with its price restated from the
+// month it is from: the purchase date of purchased products, the price date of
+// the rest. Products whose currency has no index, or whose price is older than
+// the index, are left without it.
+func (a *InflationAdjuster) AdjustProduct(product *models.Product) error {
+	priced := product.CreatedAt
+	switch {
+	case product.IsPurchased && product.PurchaseDate != nil:
+		priced = *product.PurchaseDate
+	case product.PriceDate != nil:
+		priced = *product.PriceDate
+	}
+
+	factor, err := a.Factor(product.Currency, priced)
+	if errors.Is(err, ErrNoPriceIndex) {
+		return nil
+	}
+	if err != nil || factor == nil {
+		return err
+	}
+
+	f, _ := factor.Float64()
+	inflation := math.Round((f-1)*10000) / 100
+	product.Inflation = &models.PriceAdjustment{
+		To:               a.Month(),
+		Factor:           math.Round(f*1e6) / 1e6,
+		InflationPercent: inflation,
+		BasePrice:        product.BasePrice.MulRat(factor),
+		TotalPrice:       product.TotalPrice.MulRat(factor),
+		Stale:            !product.IsPurchased && inflation >= a.staleThreshold,
+	}
+	return nil
+}
+
+// indexOn returns the latest value of the series on or before a month, or nil
+func indexOn(series []*models.PriceIndex, month time.Time) *models.PriceIndex {
+	i := sort.Search(len(series), func(i int) bool {
+		return series[i].Month.After(month)
+	})
+	if i == 0 {
+		return nil
+	}
+	return series[i-1]
+}
+
+// validatePriceIndex normalizes the currency (default: the default currency),
+// moves the month to its first day and rounds the value to the six decimals
+// the column keeps
+func (s *priceIndexService) validatePriceIndex(index *models.PriceIndex) error {
+	if index.Currency == "" {
+		index.Currency = s.defaultCurrency
+	}
+	var err error
+	if index.Currency, err = money.NormalizeCurrency(index.Currency); err != nil {
+		return err
+	}
+
+	index.Value = math.Round(index.Value*1e6) / 1e6
+	if index.Value <= 0 || math.IsInf(index.Value, 0) || math.IsNaN(index.Value) {
+		return errors.New("value must be greater than 0")
+	}
+
+	if index.Month.IsZero() {
+		return errors.New("month is required")
+	}
+	index.Month = models.MonthOf(index.Month)
+	return nil
+}
+
+// parsePriceIndexRow converts the CSV values into an index value (without user)
+func parsePriceIndexRow(row map[string]string) (*models.PriceIndex, *ImportRowError) {
+	index := &models.PriceIndex{Currency: row["currency"]}
+
+	month, err := parseImportMonth(row["month"])
+	if err != nil {
+		return nil, &ImportRowError{Field: "month", Message: err.Error()}
+	}
+	if month == nil {
+		return nil, &ImportRowError{Field: "month", Message: "month is required"}
+	}
+	index.Month = *month
+
+	value := normalizeImportNumber(row["value"])
+	if value == "" {
+		return nil, &ImportRowError{Field: "value", Message: "value is required"}
+	}
+	if index.Value, err = strconv.ParseFloat(value, 64); err != nil {
+		return nil, &ImportRowError{Field: "value", Message: fmt.Sprintf("invalid value %q", value)}
+	}
+	return index, nil
+}
+
+// parseImportMonth parses a month (YYYY-MM or MM/YYYY) or any date the
+// importer accepts. An empty value returns nil.
+func parseImportMonth(value string) (*time.Time, error) {
+	for _, layout := range []string{"2006-01", "01/2006"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	t, err := parseImportDate(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid month %q (YYYY-MM or MM/YYYY)", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/provider"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+// ErrPurchasedPrice is returned when refreshing the price of a purchased
+// product, or changing the offers it takes its price from
+var ErrPurchasedPrice = errors.New("purchased products keep the price they were bought at")
+
+// PriceRefresh is the result of asking the store for a product's current price
+type PriceRefresh struct {
+	Quote         *provider.Quote `json:"quote"`
+	PreviousTotal money.Amount    `json:"previous_total"`
+	Changed       bool            `json:"changed"`  // El precio cambió: el anterior quedó en el histórico
+	OfferID       *uint           `json:"offer_id"` // La oferta actualizada, si el precio venía de una
+	Product       *models.Product `json:"product"`
+}
+
+// PriceRefreshSummary counts what a refresh of every product did
+type PriceRefreshSummary struct {
+	Checked int // Consultados a la tienda
+	Changed int // Con un precio nuevo
+	Skipped int // Sin un proveedor que maneje su URL
+	Failed  int
+}
+
+// PriceRefreshService updates product prices from the stores' APIs
+type PriceRefreshService interface {
+	Refresh(ctx context.Context, product *models.Product) (*PriceRefresh, error)
+	RefreshAll(ctx context.Context, checkedBefore time.Time) (*PriceRefreshSummary, error)
+}
+
+// priceRefreshService is the concrete implementation
+type priceRefreshService struct {
+	registry       *provider.Registry
+	productRepo    repository.ProductRepository
+	offerRepo      repository.OfferRepository
+	productService ProductService
+	offerService   OfferService
+}
+
+// NewPriceRefreshService creates a new instance of PriceRefreshService
+func NewPriceRefreshService(
+	registry *provider.Registry,
+	productRepo repository.ProductRepository,
+	offerRepo repository.OfferRepository,
+	productService ProductService,
+	offerService OfferService,
+) PriceRefreshService {
+	return &priceRefreshService{
+		registry:       registry,
+		productRepo:    productRepo,
+		offerRepo:      offerRepo,
+		productService: productService,
+		offerService:   offerService,
+	}
+}
+
+// Refresh asks the provider of the product's source URL for its current
+// price and saves it, so a change lands in the price history and triggers the
+// alerts. An unavailable product only gets its availability updated. When the
+// price comes from an offer, the offer is the one updated.
+func (s *priceRefreshService) Refresh(ctx context.Context, product *models.Product) (*PriceRefresh, error) {
+	if product.IsPurchased {
+		return nil, ErrPurchasedPrice
+	}
+
+	quote, err := s.registry.Quote(ctx, product.SourceURL)
+	if err != nil {
+		return nil, err
+	}
+	currency := product.Currency
+	if quote.Currency != "" {
+		if currency, err = money.NormalizeCurrency(quote.Currency); err != nil {
+			return nil, fmt.Errorf("provider returned an invalid currency: %w", err)
+		}
+	}
+
+	previous := *product
+	result := &PriceRefresh{Quote: quote, PreviousTotal: product.TotalPrice, Product: product}
+
+	now := time.Now()
+	product.Available = &quote.Available
+	product.PriceCheckedAt = &now
+
+	offer, err := s.sourceOffer(product)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !quote.Available:
+		// Sin stock el precio publicado no sirve: sólo se registra la consulta
+		err = s.productService.UpdateProduct(product)
+	case offer != nil:
+		offer.BasePrice = quote.Price
+		if quote.ShippingCost != nil {
+			offer.ShippingCost = *quote.ShippingCost
+		}
+		offer.Currency = currency
+		offer.ObservedAt = now
+		result.OfferID = &offer.ID
+		err = s.offerService.UpdateOffer(product, offer)
+	default:
+		product.BasePrice = quote.Price
+		if quote.ShippingCost != nil {
+			product.ShippingCost = *quote.ShippingCost
+		}
+		product.Currency = currency
+		err = s.productService.UpdateProduct(product)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Changed = !previous.HasSamePrice(product)
+	return result, nil
+}
+
+// RefreshAll refreshes the pending products of every user whose price wasn't
+// checked since checkedBefore. URLs no provider can price are skipped, and a
+// failure on one product is logged and doesn't stop the rest.
+func (s *priceRefreshService) RefreshAll(ctx context.Context, checkedBefore time.Time) (*PriceRefreshSummary, error) {
+	products, err := s.productRepo.FindPricesToRefresh(checkedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &PriceRefreshSummary{}
+	for _, product := range products {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		if _, err := s.registry.For(product.SourceURL); err != nil {
+			summary.Skipped++
+			continue
+		}
+
+		refresh, err := s.Refresh(ctx, product)
+		switch {
+		case errors.Is(err, provider.ErrUnsupportedURL):
+			summary.Skipped++
+		case err != nil:
+			summary.Failed++
+			log.Printf("Warning: failed to refresh price of product %d: %v", product.ID, err)
+		default:
+			summary.Checked++
+			if refresh.Changed {
+				summary.Changed++
+			}
+		}
+	}
+	return summary, nil
+}
+
+// sourceOffer returns the product's offer at its source URL: the effective
+// offer, whose URL the product took. Nil when the price isn't an offer's.
+func (s *priceRefreshService) sourceOffer(product *models.Product) (*models.Offer, error) {
+	offers, err := s.offerRepo.FindByProductID(product.UserID, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, offer := range offers {
+		if offer.URL != "" && offer.URL == product.SourceURL {
+			return offer, nil
+		}
+	}
+	return nil, nil
+}
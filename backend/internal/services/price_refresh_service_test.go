@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/provider"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+// fakeProvider quotes every URL with the same price
+type fakeProvider struct {
+	quote provider.Quote
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Quote(ctx context.Context, sourceURL string) (*provider.Quote, error) {
+	quote := p.quote
+	return &quote, nil
+}
+
+func newTestPriceRefresh(env *testEnv, quote provider.Quote) (PriceRefreshService, OfferService) {
+	registry := provider.NewRegistry()
+	registry.Register(&fakeProvider{quote: quote}, "tienda.example")
+
+	offerRepo := repository.NewOfferRepository(env.db)
+	offers := NewOfferService(offerRepo, env.transactor, env.products, env.exchangeRates)
+	return NewPriceRefreshService(registry, repository.NewProductRepository(env.db), offerRepo, env.products, offers), offers
+}
+
+func TestRefreshUpdatesTheProductPrice(t *testing.T) {
+	env := newTestEnv(t)
+	free := money.Zero
+	refresher, _ := newTestPriceRefresh(env, provider.Quote{
+		Price: money.MustParse("90.00"), ShippingCost: &free, Currency: "ARS", Available: true,
+	})
+
+	product := env.createProduct(t, "Teclado", "100.00")
+	product.SourceURL = "https://www.tienda.example/teclado"
+	product.ShippingCost = money.MustParse("10.00")
+	if err := env.products.UpdateProduct(product); err != nil {
+		t.Fatal(err)
+	}
+	history := priceHistoryCount(t, env, product.ID)
+
+	refresh, err := refresher.Refresh(context.Background(), product)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !refresh.Changed || refresh.OfferID != nil || refresh.PreviousTotal != money.MustParse("110.00") {
+		t.Errorf("refresh = changed %t, offer %v, previous %s; want changed, no offer, 110.00",
+			refresh.Changed, refresh.OfferID, refresh.PreviousTotal)
+	}
+	saved, err := repository.NewProductRepository(env.db).FindByID(env.user.ID, product.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.BasePrice != money.MustParse("90.00") || !saved.ShippingCost.IsZero() || saved.PriceCheckedAt == nil ||
+		saved.Available == nil || !*saved.Available {
+		t.Errorf("product = %s + %s, checked %v, available %v; want 90.00 + 0.00, checked and available",
+			saved.BasePrice, saved.ShippingCost, saved.PriceCheckedAt, saved.Available)
+	}
+	if n := priceHistoryCount(t, env, product.ID); n != history+1 {
+		t.Errorf("price history has %d rows, want %d", n, history+1)
+	}
+}
+
+func TestRefreshUpdatesTheSourceOffer(t *testing.T) {
+	env := newTestEnv(t)
+	refresher, offers := newTestPriceRefresh(env, provider.Quote{
+		Price: money.MustParse("80.00"), Currency: "ARS", Available: true,
+	})
+
+	product := env.createProduct(t, "Teclado", "100.00")
+	offer := &models.Offer{
+		UserID: env.user.ID, ProductID: product.ID, Store: "Tienda", URL: "https://www.tienda.example/teclado",
+		BasePrice: money.MustParse("95.00"), ShippingCost: money.MustParse("5.00"), Currency: "ARS",
+	}
+	if err := offers.AddOffer(product, offer); err != nil {
+		t.Fatal(err)
+	}
+
+	refresh, err := refresher.Refresh(context.Background(), product)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !refresh.Changed || refresh.OfferID == nil || *refresh.OfferID != offer.ID {
+		t.Errorf("refresh = changed %t, offer %v; want changed through offer %d", refresh.Changed, refresh.OfferID, offer.ID)
+	}
+
+	// La oferta tiene el precio nuevo (el envío no lo informa: queda el anterior) y el producto lo toma de ella
+	saved, err := repository.NewOfferRepository(env.db).FindByID(env.user.ID, product.ID, offer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.BasePrice != money.MustParse("80.00") || saved.ShippingCost != money.MustParse("5.00") {
+		t.Errorf("offer = %s + %s, want 80.00 + 5.00", saved.BasePrice, saved.ShippingCost)
+	}
+	if product.TotalPrice != money.MustParse("85.00") {
+		t.Errorf("product total = %s, want the offer's 85.00", product.TotalPrice)
+	}
+}
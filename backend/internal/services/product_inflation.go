@@ -0,0 +1,49 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+)
+
+// AdjustForInflation sets Inflation on each product: its price restated in the
+// money of the month of to. Products that can't be restated keep it nil.
+func (s *productService) AdjustForInflation(userID uint, products []*models.Product, to time.Time) error {
+	adjuster := s.priceIndexes.NewAdjuster(userID, to)
+	for _, product := range products {
+		if err := adjuster.AdjustProduct(product); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindStalePrices returns the pending products whose price lost the most to
+// inflation since its price date, at least the configured threshold, most
+// outdated first. Los precios de compras hechas son históricos y no se revisan.
+func (s *productService) FindStalePrices(userID uint) ([]*models.Product, error) {
+	products, err := s.productRepo.FindPending(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.AdjustForInflation(userID, products, time.Now()); err != nil {
+		return nil, err
+	}
+
+	stale := []*models.Product{}
+	for _, product := range products {
+		if product.Inflation == nil || !product.Inflation.Stale {
+			continue
+		}
+		if product.SubscriptionStatus != nil && *product.SubscriptionStatus == models.SubscriptionCancelled {
+			continue
+		}
+		stale = append(stale, product)
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].Inflation.Factor > stale[j].Inflation.Factor
+	})
+	return stale, nil
+}
@@ -2,17 +2,46 @@ package services
 
 import (
 	"errors"
+	"log"
+	"reflect"
+	"time"
 
 	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
 	"github.com/buylist-manager/backend/internal/repository"
+	"gorm.io/gorm"
 )
 
+// ValidationError is returned when a product doesn't pass the business rules.
+// The handlers report it as a bad request; any other error is the server's.
+type ValidationError struct {
+	Err error
+}
+
+// Error returns the message of the rule the product broke
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// invalid returns a ValidationError with the message
+func invalid(message string) error {
+	return &ValidationError{Err: errors.New(message)}
+}
+
 // ProductService handles business logic for products
 type ProductService interface {
 	CreateProduct(product *models.Product) error
-	GetTotalPendingCost() (float64, error)
-	GetMonthlyRecurringCost() (float64, error)
-	GetYearlyRecurringCost() (float64, error)
+	UpdateProduct(product *models.Product) error
+	DeleteProduct(userID, id uint) error
+	GetPriceHistory(userID, productID uint, from, to *time.Time) ([]*models.PriceHistory, error)
+	ResolveCurrency(code string) (string, error)
+	GetStats(userID uint, opts StatsOptions) (*ProductStats, error)
+	AdjustForInflation(userID uint, products []*models.Product, to time.Time) error
+	FindStalePrices(userID uint) ([]*models.Product, error)
+	RecalculateTaxes(userID uint) (int, error)
+	RenewSubscriptions(now time.Time) (int, error)
+	WithTx(tx *gorm.DB) ProductService
 }
 
 // productService is the concrete implementation
@@ -20,6 +49,14 @@ type productService struct {
 	productRepo      repository.ProductRepository
 	categoryRepo     repository.CategoryRepository
 	subcategoryRepo  repository.SubcategoryRepository
+	priceHistoryRepo repository.PriceHistoryRepository
+	storeRepo        repository.StoreRepository
+	taxProfileRepo   repository.TaxProfileRepository
+	transactor       repository.Transactor
+	events           *EventBus
+	exchangeRates    ExchangeRateService
+	priceIndexes     PriceIndexService
+	defaultCurrency  string
 }
 
 // NewProductService creates a new instance of ProductService
@@ -27,108 +64,416 @@ func NewProductService(
 	productRepo repository.ProductRepository,
 	categoryRepo repository.CategoryRepository,
 	subcategoryRepo repository.SubcategoryRepository,
+	priceHistoryRepo repository.PriceHistoryRepository,
+	storeRepo repository.StoreRepository,
+	taxProfileRepo repository.TaxProfileRepository,
+	transactor repository.Transactor,
+	events *EventBus,
+	exchangeRates ExchangeRateService,
+	priceIndexes PriceIndexService,
+	defaultCurrency string,
 ) ProductService {
 	return &productService{
-		productRepo:     productRepo,
-		categoryRepo:    categoryRepo,
-		subcategoryRepo: subcategoryRepo,
+		productRepo:      productRepo,
+		categoryRepo:     categoryRepo,
+		subcategoryRepo:  subcategoryRepo,
+		priceHistoryRepo: priceHistoryRepo,
+		storeRepo:        storeRepo,
+		taxProfileRepo:   taxProfileRepo,
+		transactor:       transactor,
+		events:           events,
+		exchangeRates:    exchangeRates,
+		priceIndexes:     priceIndexes,
+		defaultCurrency:  defaultCurrency,
+	}
+}
+
+// WithTx returns a copy of the service whose repositories run inside the given
+// transaction. Nested transactions become savepoints.
+func (s *productService) WithTx(tx *gorm.DB) ProductService {
+	return &productService{
+		productRepo:      s.productRepo.WithTx(tx),
+		categoryRepo:     s.categoryRepo.WithTx(tx),
+		subcategoryRepo:  s.subcategoryRepo.WithTx(tx),
+		priceHistoryRepo: s.priceHistoryRepo.WithTx(tx),
+		storeRepo:        s.storeRepo.WithTx(tx),
+		taxProfileRepo:   s.taxProfileRepo.WithTx(tx),
+		transactor:       repository.NewTransactor(tx),
+		events:           s.events,
+		exchangeRates:    s.exchangeRates,
+		priceIndexes:     s.priceIndexes,
+		defaultCurrency:  s.defaultCurrency,
 	}
 }
 
-// CreateProduct creates a product with validations
+// CreateProduct creates a product with validations. Without a shipping cost it
+// takes the default one of its store, if the store uses the same currency.
 func (s *productService) CreateProduct(product *models.Product) error {
+	if err := s.validateProduct(product); err != nil {
+		return err
+	}
+
+	if product.StoreID != nil && product.ShippingCost.IsZero() {
+		store, err := s.storeRepo.FindByID(product.UserID, *product.StoreID)
+		if err != nil {
+			return err
+		}
+		if store.Currency == product.Currency {
+			product.ShippingCost = store.DefaultShippingCost
+		}
+	}
+
+	// El cálculo de total_price se hace automáticamente en el hook BeforeSave del modelo
+	events := []Event{ProductCreated{Product: product}}
+	return s.write(events, func(tx *gorm.DB) error {
+		return s.productRepo.WithTx(tx).Create(product)
+	})
+}
+
+// UpdateProduct updates a product with validations. If any price component
+// changed, PriceDate is moved to now. The product is saved in a transaction
+// with the subscribers of its events: PriceChanged stores the previous price
+// in the history and, once committed, evaluates the alerts.
+func (s *productService) UpdateProduct(product *models.Product) error {
+	if err := s.validateProduct(product); err != nil {
+		return err
+	}
+
+	current, err := s.productRepo.FindByID(product.UserID, product.ID)
+	if err != nil {
+		return err
+	}
+	if !current.HasSamePrice(product) {
+		now := time.Now()
+		product.PriceDate = &now
+	}
+
+	return s.write(productUpdateEvents(current, product), func(tx *gorm.DB) error {
+		return s.productRepo.WithTx(tx).Update(product)
+	})
+}
+
+// DeleteProduct deletes a product of the user. ProductDeleted carries the
+// data it had.
+func (s *productService) DeleteProduct(userID, id uint) error {
+	product, err := s.productRepo.FindByID(userID, id)
+	if err != nil {
+		return err
+	}
+
+	events := []Event{ProductDeleted{Product: product}}
+	return s.write(events, func(tx *gorm.DB) error {
+		return s.productRepo.WithTx(tx).Delete(userID, id)
+	})
+}
+
+// write runs fn and the transactional subscribers of the events in one
+// transaction. The after-commit subscribers get the events once the
+// outermost transaction commits.
+func (s *productService) write(events []Event, fn func(tx *gorm.DB) error) error {
+	return s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return s.events.PublishTx(tx, events...)
+	})
+}
+
+// GetPriceHistory returns the previous prices of a product within an optional date range
+func (s *productService) GetPriceHistory(userID, productID uint, from, to *time.Time) ([]*models.PriceHistory, error) {
+	return s.priceHistoryRepo.FindByProductID(userID, productID, from, to)
+}
+
+// validateProduct checks that the category and subcategory exist, belong to the
+// product owner and are coherent with the recurrence type of the product. It
+// also links the product to the store of its source URL and computes its taxes.
+func (s *productService) validateProduct(product *models.Product) error {
 	// Validar que la categoría existe
-	category, err := s.categoryRepo.FindByID(product.CategoryID)
+	category, err := s.categoryRepo.FindByID(product.UserID, product.CategoryID)
 	if err != nil {
-		return errors.New("category not found")
+		return invalid("category not found")
 	}
 
-	// Validar que la subcategoría existe
-	_, err = s.subcategoryRepo.FindByID(product.SubcategoryID)
+	// Validar que la subcategoría existe y es de esa categoría
+	subcategory, err := s.subcategoryRepo.FindByID(product.UserID, product.SubcategoryID)
 	if err != nil {
-		return errors.New("subcategory not found")
+		return invalid("subcategory not found")
+	}
+	if subcategory.CategoryID != product.CategoryID {
+		return invalid("subcategory does not belong to the category")
+	}
+
+	// Validar moneda (vacía = la moneda por defecto)
+	if product.Currency, err = s.ResolveCurrency(product.Currency); err != nil {
+		return &ValidationError{Err: err}
 	}
 
-	// Validar coherencia entre category.type y recurrence_type
-	// Si la categoría es "one_time", recurrence_type debe ser null
-	// Si la categoría es "recurring", recurrence_type debe ser "monthly" o "yearly"
+	// Validar precios
+	if product.BasePrice.IsNegative() || product.ShippingCost.IsNegative() || product.Taxes.IsNegative() {
+		return invalid("prices cannot be negative")
+	}
+
+	// Validar configuración de alertas
+	if product.TargetPrice != nil && product.TargetPrice.IsNegative() {
+		return invalid("target price cannot be negative")
+	}
+	if product.TargetDropPercent != nil && (*product.TargetDropPercent <= 0 || *product.TargetDropPercent > 100) {
+		return invalid("target drop percent must be between 0 and 100")
+	}
+
+	// Validar coherencia entre category.type y la recurrencia
+	// Si la categoría es "one_time", no puede tener recurrencia
+	// Si la categoría es "recurring", necesita unidad (day, week, month, year) e intervalo
+	if product.RecurrenceInterval != nil && product.RecurrenceUnit == nil {
+		return invalid("recurrence interval requires a recurrence unit")
+	}
 	if category.Type == "one_time" {
-		if product.RecurrenceType != nil {
-			return errors.New("one-time purchases cannot have recurrence type")
+		if product.RecurrenceUnit != nil {
+			return invalid("one-time purchases cannot have recurrence")
 		}
 	} else if category.Type == "recurring" {
-		if product.RecurrenceType == nil {
-			return errors.New("recurring purchases must have recurrence type")
+		recurrence := product.Recurrence()
+		if recurrence == nil {
+			return invalid("recurring purchases must have recurrence")
 		}
-		if *product.RecurrenceType != "monthly" && *product.RecurrenceType != "yearly" {
-			return errors.New("recurrence type must be 'monthly' or 'yearly'")
+		if err := recurrence.Validate(); err != nil {
+			return &ValidationError{Err: err}
 		}
+		product.SetRecurrence(recurrence) // Intervalo 1 por defecto
 	}
 
-	// El cálculo de total_price se hace automáticamente en el hook BeforeSave del modelo
-	return s.productRepo.Create(product)
+	if err := validateSubscription(product); err != nil {
+		return err
+	}
+	if err := s.linkStore(product); err != nil {
+		return err
+	}
+	if err := s.applyTaxes(product); err != nil {
+		return err
+	}
+	// Después de los impuestos: el total financiado sale del precio con impuestos
+	return validateInstallments(product)
 }
 
-// GetTotalPendingCost calcula el total de productos pendientes de compra (one-time)
-// Laravel: Product::where('is_purchased', false)->whereHas('category', fn($q) => $q->where('type', 'one_time'))->sum('total_price')
-func (s *productService) GetTotalPendingCost() (float64, error) {
-	products, err := s.productRepo.FindPending()
+// linkStore links the product to the store whose domain matches the host of
+// its source URL, or unlinks it if none does
+func (s *productService) linkStore(product *models.Product) error {
+	product.StoreID = nil
+	host := models.NormalizeDomain(product.SourceURL)
+	if host == "" {
+		return nil
+	}
+
+	stores, err := s.storeRepo.FindAll(product.UserID)
 	if err != nil {
-		return 0, err
+		return err
 	}
+	if store := matchStore(stores, host); store != nil {
+		product.StoreID = &store.ID
+	}
+	return nil
+}
 
-	var total float64
-	for _, product := range products {
-		// Solo sumar productos de compra única
-		if product.Category != nil && product.Category.Type == "one_time" {
-			total += product.TotalPrice
+// validateInstallments checks the installment plan and derives its totals.
+// With a rate (0 = interest-free) the financed total is recalculated from the
+// price; without one the loaded total is kept. Unless it is given, the first
+// installment is due a month after the purchase, so a pending product has no
+// dates until it is bought.
+func validateInstallments(product *models.Product) error {
+	if !product.HasInstallments() {
+		if product.InstallmentRate != nil || product.InstallmentTotal != nil || product.FirstInstallmentDate != nil {
+			return invalid("installment fields require an installment count")
+		}
+		product.LastInstallmentDate = nil
+		return nil
+	}
+
+	if product.IsSubscription() {
+		return invalid("subscriptions cannot be paid in installments")
+	}
+	count := *product.InstallmentCount
+	if count < 1 || count > models.MaxInstallments {
+		return invalid("installment count must be between 1 and 72")
+	}
+
+	if product.InstallmentRate != nil {
+		if *product.InstallmentRate < 0 || *product.InstallmentRate > 1000 {
+			return invalid("installment rate must be between 0 and 1000")
+		}
+		total, err := models.FinancedTotal(product.CalculateTotal(), *product.InstallmentRate, count)
+		if err != nil {
+			return &ValidationError{Err: err}
+		}
+		product.InstallmentTotal = &total
+	} else if product.InstallmentTotal == nil || !product.InstallmentTotal.IsPositive() {
+		return invalid("installment plans need a rate or a financed total greater than 0")
+	}
+
+	if product.FirstInstallmentDate == nil {
+		if !product.IsPurchased {
+			product.LastInstallmentDate = nil
+			return nil
+		}
+		start := time.Now()
+		if product.PurchaseDate != nil {
+			start = *product.PurchaseDate
 		}
+		first := models.FirstInstallmentDue(start)
+		product.FirstInstallmentDate = &first
 	}
+	first := models.DateOnly(*product.FirstInstallmentDate)
+	product.FirstInstallmentDate = &first
 
-	return total, nil
+	schedule := product.Installments()
+	last := schedule[len(schedule)-1].DueDate
+	product.LastInstallmentDate = &last
+	return nil
 }
 
-// GetMonthlyRecurringCost calcula el gasto mensual en suscripciones
-func (s *productService) GetMonthlyRecurringCost() (float64, error) {
-	allProducts, err := s.productRepo.FindAll()
-	if err != nil {
-		return 0, err
+// validateSubscription checks the subscription fields and fills in their
+// defaults: a new subscription is active and starts today (or on its purchase
+// date). The next billing date is always derived, never taken from the request.
+func validateSubscription(product *models.Product) error {
+	if !product.IsSubscription() {
+		if product.SubscriptionStatus != nil || product.StartDate != nil || product.TrialEndsAt != nil {
+			return invalid("one-time purchases cannot have subscription fields")
+		}
+		product.NextBillingDate = nil
+		return nil
 	}
 
-	var total float64
-	for _, product := range allProducts {
-		// Solo productos recurring y no comprados (o purchased si son suscripciones activas)
-		if product.Category != nil && product.Category.Type == "recurring" && product.RecurrenceType != nil {
-			if *product.RecurrenceType == "monthly" {
-				total += product.TotalPrice
-			} else if *product.RecurrenceType == "yearly" {
-				// Dividir el costo anual en 12 meses
-				total += product.TotalPrice / 12
+	if product.SubscriptionStatus == nil {
+		status := models.SubscriptionActive
+		product.SubscriptionStatus = &status
+	}
+	if !models.IsValidSubscriptionStatus(*product.SubscriptionStatus) {
+		return invalid("subscription status must be 'trial', 'active', 'paused' or 'cancelled'")
+	}
+
+	if product.StartDate == nil {
+		start := time.Now()
+		if product.PurchaseDate != nil {
+			start = *product.PurchaseDate
+		}
+		product.StartDate = &start
+	}
+	start := models.DateOnly(*product.StartDate)
+	product.StartDate = &start
+
+	if product.TrialEndsAt != nil {
+		trialEnd := models.DateOnly(*product.TrialEndsAt)
+		if trialEnd.Before(start) {
+			return invalid("trial end date cannot be before the start date")
+		}
+		product.TrialEndsAt = &trialEnd
+	} else if *product.SubscriptionStatus == models.SubscriptionTrial {
+		return invalid("trial subscriptions must have a trial end date")
+	}
+
+	product.RollForward(time.Now())
+	return nil
+}
+
+// applyTaxes computes the product's taxes with its tax profile, or else with
+// its store's. Without a profile the taxes are the ones typed in. A purchased
+// product keeps the price it was bought at: its taxes aren't recomputed with
+// today's rules and rates (like RecalculateTaxes, which only sees pending ones).
+func (s *productService) applyTaxes(product *models.Product) error {
+	if product.IsPurchased {
+		return nil
+	}
+	product.TaxBreakdown = nil
+
+	var profile *models.TaxProfile
+	if product.TaxProfileID != nil {
+		var err error
+		if profile, err = s.taxProfileRepo.FindByID(product.UserID, *product.TaxProfileID); err != nil {
+			return invalid("tax profile not found")
+		}
+	} else if product.StoreID != nil {
+		store, err := s.storeRepo.FindByID(product.UserID, *product.StoreID)
+		if err != nil {
+			return err
+		}
+		if store.TaxProfileID != nil {
+			if profile, err = s.taxProfileRepo.FindByID(product.UserID, *store.TaxProfileID); err != nil {
+				return err
 			}
 		}
 	}
+	if profile == nil {
+		return nil
+	}
 
-	return total, nil
+	converter := s.exchangeRates.NewConverter(product.UserID, product.Currency, time.Now())
+	taxes, breakdown, err := computeTaxes(profile, product, converter)
+	if err != nil {
+		return err
+	}
+	product.Taxes = taxes
+	product.TaxBreakdown = breakdown
+	return nil
 }
 
-// GetYearlyRecurringCost calcula el gasto anual en suscripciones
-func (s *productService) GetYearlyRecurringCost() (float64, error) {
-	allProducts, err := s.productRepo.FindAll()
+// RecalculateTaxes recomputes the taxes of the user's pending products after a
+// tax profile or store changed, and saves the ones that changed (so the old
+// price goes to the history). It returns how many changed. A product that
+// can't be saved is logged and skipped.
+func (s *productService) RecalculateTaxes(userID uint) (int, error) {
+	products, err := s.productRepo.FindPending(userID)
 	if err != nil {
 		return 0, err
 	}
 
-	var total float64
-	for _, product := range allProducts {
-		if product.Category != nil && product.Category.Type == "recurring" && product.RecurrenceType != nil {
-			if *product.RecurrenceType == "yearly" {
-				total += product.TotalPrice
-			} else if *product.RecurrenceType == "monthly" {
-				// Multiplicar el costo mensual por 12
-				total += product.TotalPrice * 12
-			}
+	updated := 0
+	for _, product := range products {
+		if product.TaxProfileID == nil && product.StoreID == nil && product.TaxBreakdown == nil {
+			continue
+		}
+		taxes, breakdown := product.Taxes, product.TaxBreakdown
+		if err := s.applyTaxes(product); err != nil {
+			log.Printf("Warning: failed to recalculate taxes of product %d: %v", product.ID, err)
+			continue
+		}
+		if product.Taxes == taxes && reflect.DeepEqual(product.TaxBreakdown, breakdown) {
+			continue
+		}
+
+		if err := s.UpdateProduct(product); err != nil {
+			log.Printf("Warning: failed to recalculate taxes of product %d: %v", product.ID, err)
+			continue
 		}
+		updated++
 	}
+	return updated, nil
+}
 
-	return total, nil
+// ResolveCurrency validates a currency code; an empty code means the default currency
+func (s *productService) ResolveCurrency(code string) (string, error) {
+	if code == "" {
+		return s.defaultCurrency, nil
+	}
+	return money.NormalizeCurrency(code)
+}
+
+// RenewSubscriptions brings every user's subscriptions up to date: trials that
+// ended become active and past billing dates move to the next period.
+// Returns how many subscriptions changed.
+func (s *productService) RenewSubscriptions(now time.Time) (int, error) {
+	products, err := s.productRepo.FindSubscriptionsToRenew(models.DateOnly(now))
+	if err != nil {
+		return 0, err
+	}
+
+	renewed := 0
+	for _, product := range products {
+		if !product.RollForward(now) {
+			continue
+		}
+		if err := s.productRepo.UpdateSubscription(product); err != nil {
+			return renewed, err
+		}
+		renewed++
+	}
+	return renewed, nil
 }
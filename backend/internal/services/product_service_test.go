@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/dbtest"
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+func TestInstallmentTotalIncludesTheProfileTaxes(t *testing.T) {
+	env := newTestEnv(t)
+	profile := &models.TaxProfile{UserID: env.user.ID, Name: "IVA", Rules: []models.TaxRule{
+		{Position: 1, Name: "IVA", Kind: models.TaxRulePercentage, Rate: 21, Base: models.TaxBasePrice},
+	}}
+	dbtest.MustCreate(t, env.db, profile)
+
+	product := env.createProduct(t, "Heladera", "1000.00")
+	count, rate := 3, 60.0
+	product.TaxProfileID = &profile.ID
+	product.InstallmentCount, product.InstallmentRate = &count, &rate
+	if err := env.products.UpdateProduct(product); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(wantTaxes, wantTotal string) {
+		t.Helper()
+		financed, err := models.FinancedTotal(money.MustParse(wantTotal), rate, count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if product.Taxes != money.MustParse(wantTaxes) || product.TotalPrice != money.MustParse(wantTotal) {
+			t.Errorf("taxes %s, total %s; want %s, %s", product.Taxes, product.TotalPrice, wantTaxes, wantTotal)
+		}
+		if product.InstallmentTotal == nil || *product.InstallmentTotal != financed {
+			t.Errorf("installment total = %v, want %s (financed from %s)", product.InstallmentTotal, financed, wantTotal)
+		}
+	}
+	check("210.00", "1210.00")
+
+	// Con otro precio se recalculan los impuestos y, con ellos, el total financiado
+	product.BasePrice = money.MustParse("2000.00")
+	if err := env.products.UpdateProduct(product); err != nil {
+		t.Fatal(err)
+	}
+	check("420.00", "2420.00")
+}
+
+func TestPurchasedProductKeepsItsTaxes(t *testing.T) {
+	env := newTestEnv(t)
+	profile := &models.TaxProfile{UserID: env.user.ID, Name: "IVA", Rules: []models.TaxRule{
+		{Position: 1, Name: "IVA", Kind: models.TaxRulePercentage, Rate: 21, Base: models.TaxBasePrice},
+	}}
+	dbtest.MustCreate(t, env.db, profile)
+
+	product := env.createProduct(t, "Heladera", "1000.00")
+	product.TaxProfileID = &profile.ID
+	if err := env.products.UpdateProduct(product); err != nil {
+		t.Fatal(err)
+	}
+	product.IsPurchased = true
+	if err := env.products.UpdateProduct(product); err != nil {
+		t.Fatal(err)
+	}
+	history := priceHistoryCount(t, env, product.ID)
+
+	// Después de la compra el perfil cambia y suma un impuesto en dólares sin cotización
+	rule := &models.TaxRule{
+		TaxProfileID: profile.ID, Position: 2, Name: "Tasa", Kind: models.TaxRuleFixed,
+		Amount: money.MustParse("10.00"), Base: models.TaxBasePrice, Currency: "USD",
+	}
+	dbtest.MustCreate(t, env.db, rule)
+
+	product.Notes = "Llegó bien"
+	if err := env.products.UpdateProduct(product); err != nil {
+		t.Fatalf("editing the notes of a purchased product: %v", err)
+	}
+	if product.Taxes != money.MustParse("210.00") || product.TotalPrice != money.MustParse("1210.00") {
+		t.Errorf("taxes %s, total %s; want the ones it was bought with, 210.00 and 1210.00", product.Taxes, product.TotalPrice)
+	}
+	if n := priceHistoryCount(t, env, product.ID); n != history {
+		t.Errorf("price history has %d rows, want %d: the price didn't change", n, history)
+	}
+}
+
+// priceHistoryCount returns how many previous prices the product has
+func priceHistoryCount(t *testing.T, env *testEnv, productID uint) int64 {
+	t.Helper()
+	var n int64
+	if err := env.db.Model(&models.PriceHistory{}).Where("product_id = ?", productID).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
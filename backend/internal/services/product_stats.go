@@ -0,0 +1,334 @@
+package services
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+// StatsOptions are the parameters of GetStats
+type StatsOptions struct {
+	Currency string
+	From, To time.Time  // Rango del gasto mensual, To exclusivo
+	AdjustTo *time.Time // Mes a cuyo dinero se actualizan los precios; nil = sin ajustar
+}
+
+// ProductStats are the user's product statistics, converted to one currency
+// with the rates in effect today
+type ProductStats struct {
+	Currency             string       `json:"currency"`
+	AdjustedTo           string       `json:"adjusted_to,omitempty"` // "2026-10" con ?adjust=inflation
+	TotalPendingOneTime  money.Amount `json:"total_pending_one_time"`
+	MonthlyRecurringCost money.Amount `json:"monthly_recurring_cost"`
+	YearlyRecurringCost  money.Amount `json:"yearly_recurring_cost"`
+	StatsGroup
+
+	// Cuotas que faltan pagar de compras ya hechas
+	InstallmentsRemaining money.Amount `json:"installments_remaining"`
+
+	Categories     []*CategoryStats `json:"categories"`
+	MonthlySpend   []*MonthSpend    `json:"monthly_spend"`
+	FutureOutflows []*MonthOutflow  `json:"future_outflows"`
+}
+
+// StatsGroup are the counts and totals of a set of products. Recurring costs
+// only include active subscriptions.
+type StatsGroup struct {
+	PendingCount   int64        `json:"pending_count"`
+	PurchasedCount int64        `json:"purchased_count"`
+	PendingTotal   money.Amount `json:"pending_total"`
+	PurchasedTotal money.Amount `json:"purchased_total"`
+	AveragePrice   money.Amount `json:"average_price"`
+
+	yearly *big.Rat // Costo anual exacto de las suscripciones activas
+}
+
+// CategoryStats are the statistics of a category and its subcategories
+type CategoryStats struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	StatsGroup
+	MonthlyRecurringCost money.Amount        `json:"monthly_recurring_cost"`
+	YearlyRecurringCost  money.Amount        `json:"yearly_recurring_cost"`
+	Subcategories        []*SubcategoryStats `json:"subcategories"`
+}
+
+// SubcategoryStats are the statistics of a subcategory
+type SubcategoryStats struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	StatsGroup
+	MonthlyRecurringCost money.Amount `json:"monthly_recurring_cost"`
+	YearlyRecurringCost  money.Amount `json:"yearly_recurring_cost"`
+}
+
+// MonthSpend is what was purchased in a month
+type MonthSpend struct {
+	Month string       `json:"month"` // "2026-10"
+	Count int64        `json:"count"`
+	Total money.Amount `json:"total"`
+}
+
+// MonthOutflow is what is already committed for a coming month: the
+// installments due that month and the normalized cost of the subscriptions
+type MonthOutflow struct {
+	Month         string       `json:"month"` // "2026-10"
+	Installments  money.Amount `json:"installments"`
+	Subscriptions money.Amount `json:"subscriptions"`
+	Total         money.Amount `json:"total"`
+}
+
+// GetStats calculates the statistics with SQL aggregates: the products are
+// grouped by category, subcategory, currency and recurrence in the database,
+// and only the groups are converted and normalized here. The monthly spend
+// covers the purchases in [from, to), one entry per month even if empty; the
+// future outflows go from the current month to the last installment due, at
+// least a year.
+// With AdjustTo every price is first restated from the month of its price date
+// (purchases, from the month they were made) with the index of its currency.
+// Installments are fixed amounts and are never adjusted.
+func (s *productService) GetStats(userID uint, opts StatsOptions) (*ProductStats, error) {
+	currency, err := s.ResolveCurrency(opts.Currency)
+	if err != nil {
+		return nil, err
+	}
+	from, to := opts.From, opts.To
+
+	categories, err := s.categoryRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	subcategories, err := s.subcategoryRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	var adjuster *InflationAdjuster
+	var totals []*repository.ProductTotals
+	if opts.AdjustTo != nil {
+		adjuster = s.priceIndexes.NewAdjuster(userID, *opts.AdjustTo)
+		totals, err = s.productRepo.TotalsByPriceMonth(userID)
+	} else {
+		totals, err = s.productRepo.Totals(userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	spend, err := s.productRepo.MonthlySpend(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	today := models.DateOnly(time.Now())
+	plans, err := s.productRepo.FindInstallmentPlans(userID, today)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ProductStats{
+		Currency:       currency,
+		StatsGroup:     newStatsGroup(),
+		Categories:     []*CategoryStats{},
+		MonthlySpend:   []*MonthSpend{},
+		FutureOutflows: []*MonthOutflow{},
+	}
+
+	byCategory := make(map[uint]*CategoryStats)
+	for _, category := range categories {
+		cs := &CategoryStats{
+			ID:            category.ID,
+			Name:          category.Name,
+			Type:          category.Type,
+			StatsGroup:    newStatsGroup(),
+			Subcategories: []*SubcategoryStats{},
+		}
+		byCategory[category.ID] = cs
+		stats.Categories = append(stats.Categories, cs)
+	}
+	bySubcategory := make(map[uint]*SubcategoryStats)
+	for _, subcategory := range subcategories {
+		cs := byCategory[subcategory.CategoryID]
+		if cs == nil {
+			continue
+		}
+		ss := &SubcategoryStats{
+			ID:         subcategory.ID,
+			Name:       subcategory.Name,
+			StatsGroup: newStatsGroup(),
+		}
+		bySubcategory[subcategory.ID] = ss
+		cs.Subcategories = append(cs.Subcategories, ss)
+	}
+
+	converter := s.exchangeRates.NewConverter(userID, currency, time.Now())
+	for _, row := range totals {
+		total, err := adjust(adjuster, row.Total, row.Currency, row.PriceMonth)
+		if err != nil {
+			return nil, err
+		}
+		if total, err = converter.Convert(total, row.Currency); err != nil {
+			return nil, err
+		}
+
+		stats.add(row, total)
+		if row.CategoryType == "one_time" && !row.IsPurchased {
+			stats.TotalPendingOneTime = stats.TotalPendingOneTime.Add(total)
+		}
+		if cs := byCategory[row.CategoryID]; cs != nil {
+			cs.add(row, total)
+		}
+		if ss := bySubcategory[row.SubcategoryID]; ss != nil {
+			ss.add(row, total)
+		}
+	}
+
+	// Los totales anuales se dividen por 12 una sola vez, al final
+	stats.MonthlyRecurringCost, stats.YearlyRecurringCost = stats.finish()
+	for _, cs := range stats.Categories {
+		cs.MonthlyRecurringCost, cs.YearlyRecurringCost = cs.finish()
+		for _, ss := range cs.Subcategories {
+			ss.MonthlyRecurringCost, ss.YearlyRecurringCost = ss.finish()
+		}
+	}
+
+	byMonth := make(map[string]*MonthSpend)
+	for month := firstOfMonth(from); month.Before(to); month = month.AddDate(0, 1, 0) {
+		ms := &MonthSpend{Month: month.Format("2006-01")}
+		byMonth[ms.Month] = ms
+		stats.MonthlySpend = append(stats.MonthlySpend, ms)
+	}
+	for _, row := range spend {
+		ms := byMonth[row.Month]
+		if ms == nil {
+			continue
+		}
+		total, err := adjust(adjuster, row.Total, row.Currency, row.Month)
+		if err != nil {
+			return nil, err
+		}
+		if total, err = converter.Convert(total, row.Currency); err != nil {
+			return nil, err
+		}
+		ms.Count += row.Count
+		ms.Total = ms.Total.Add(total)
+	}
+
+	if err := stats.addFutureOutflows(plans, converter, today); err != nil {
+		return nil, err
+	}
+	if adjuster != nil {
+		stats.AdjustedTo = adjuster.Month()
+	}
+	return stats, nil
+}
+
+// adjust restates a total of a month ("2026-10") when there is an adjuster
+func adjust(adjuster *InflationAdjuster, total money.Amount, currency, month string) (money.Amount, error) {
+	if adjuster == nil {
+		return total, nil
+	}
+	priced, err := time.Parse("2006-01", month)
+	if err != nil {
+		return money.Zero, fmt.Errorf("invalid price month %q", month)
+	}
+	return adjuster.Adjust(total, currency, priced)
+}
+
+// addFutureOutflows spreads the pending installments of the plans over the
+// coming months and adds the monthly subscription cost to each one
+func (stats *ProductStats) addFutureOutflows(plans []*models.Product, converter *CurrencyConverter, today time.Time) error {
+	start := firstOfMonth(today)
+	end := start.AddDate(1, 0, 0)
+	for _, product := range plans {
+		if product.LastInstallmentDate != nil && !product.LastInstallmentDate.Before(end) {
+			end = firstOfMonth(*product.LastInstallmentDate).AddDate(0, 1, 0)
+		}
+	}
+
+	byMonth := make(map[string]*MonthOutflow)
+	for month := start; month.Before(end); month = month.AddDate(0, 1, 0) {
+		mo := &MonthOutflow{Month: month.Format("2006-01"), Subscriptions: stats.MonthlyRecurringCost}
+		byMonth[mo.Month] = mo
+		stats.FutureOutflows = append(stats.FutureOutflows, mo)
+	}
+
+	for _, product := range plans {
+		for _, installment := range product.Installments() {
+			if installment.DueDate.Before(today) {
+				continue // Ya pagada
+			}
+			amount, err := converter.Convert(installment.Amount, product.Currency)
+			if err != nil {
+				return err
+			}
+			stats.InstallmentsRemaining = stats.InstallmentsRemaining.Add(amount)
+			if mo := byMonth[installment.DueDate.Format("2006-01")]; mo != nil {
+				mo.Installments = mo.Installments.Add(amount)
+			}
+		}
+	}
+
+	for _, mo := range stats.FutureOutflows {
+		mo.Total = mo.Installments.Add(mo.Subscriptions)
+	}
+	return nil
+}
+
+func newStatsGroup() StatsGroup {
+	return StatsGroup{yearly: new(big.Rat)}
+}
+
+// add counts a group of products whose total is already converted
+func (g *StatsGroup) add(row *repository.ProductTotals, total money.Amount) {
+	if row.IsPurchased {
+		g.PurchasedCount += row.Count
+		g.PurchasedTotal = g.PurchasedTotal.Add(total)
+	} else {
+		g.PendingCount += row.Count
+		g.PendingTotal = g.PendingTotal.Add(total)
+	}
+
+	if recurrence := activeRecurrence(row); recurrence != nil {
+		g.yearly.Add(g.yearly, yearlyCost(total, *recurrence))
+	}
+}
+
+// finish calculates the average price and returns the monthly and yearly
+// recurring cost
+func (g *StatsGroup) finish() (monthly, yearly money.Amount) {
+	if count := g.PendingCount + g.PurchasedCount; count > 0 {
+		g.AveragePrice = g.PendingTotal.Add(g.PurchasedTotal).Div(count)
+	}
+	perMonth := new(big.Rat).Quo(g.yearly, big.NewRat(12, 1))
+	return money.FromRat(perMonth), money.FromRat(g.yearly)
+}
+
+// activeRecurrence returns the billing period of a group of active
+// subscriptions, or nil when the group isn't one
+func activeRecurrence(row *repository.ProductTotals) *models.Recurrence {
+	if row.CategoryType != "recurring" {
+		return nil
+	}
+	product := &models.Product{
+		RecurrenceInterval: row.RecurrenceInterval,
+		RecurrenceUnit:     row.RecurrenceUnit,
+		SubscriptionStatus: row.SubscriptionStatus,
+	}
+	if !product.IsActiveSubscription() {
+		return nil
+	}
+	return product.Recurrence()
+}
+
+// yearlyCost returns the exact cost of a year of a subscription
+func yearlyCost(price money.Amount, r models.Recurrence) *big.Rat {
+	return new(big.Rat).Mul(price.Rat(), r.PerYear())
+}
+
+// firstOfMonth returns midnight of the first day of t's month
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
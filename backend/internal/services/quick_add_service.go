@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/pagemeta"
+	"github.com/buylist-manager/backend/internal/repository"
+	"github.com/buylist-manager/backend/internal/search"
+)
+
+// ErrIncompleteDraft is returned when a quick-add draft can't be saved as it is
+var ErrIncompleteDraft = errors.New("draft is incomplete")
+
+const (
+	// maxSuggestions limits the subcategories suggested for a draft
+	maxSuggestions = 3
+	// minSuggestionScore is the score a subcategory needs to be suggested
+	minSuggestionScore = 0.5
+)
+
+// suggestionStopWords are left out when matching names: they match anything
+var suggestionStopWords = map[string]bool{
+	"de": true, "del": true, "la": true, "el": true, "los": true, "las": true,
+	"y": true, "con": true, "para": true, "por": true, "en": true, "sin": true,
+	"the": true, "and": true, "with": true, "for": true, "of": true,
+}
+
+// SubcategorySuggestion is a subcategory that fits a draft, best first
+type SubcategorySuggestion struct {
+	CategoryID    uint    `json:"category_id"`
+	Category      string  `json:"category"`
+	SubcategoryID uint    `json:"subcategory_id"`
+	Subcategory   string  `json:"subcategory"`
+	Score         float64 `json:"score"` // 0..1
+}
+
+// QuickAddDraft is the product extracted from a web page. It isn't saved
+// until it's confirmed (or created directly), so the user can fix it first.
+type QuickAddDraft struct {
+	Product     *models.Product         `json:"product"`
+	Sources     map[string]string       `json:"sources"` // Campo -> fuente ("json-ld", "opengraph", "microdata", "meta", "title")
+	Missing     []string                `json:"missing"` // Campos que la página no trae
+	Suggestions []SubcategorySuggestion `json:"suggestions"`
+	Created     bool                    `json:"created"`
+}
+
+// QuickAddService builds products from the HTML of the page the user is on.
+// The extraction runs offline: the caller provides the HTML.
+type QuickAddService interface {
+	Draft(userID uint, pageURL, html string) (*QuickAddDraft, error)
+	Create(draft *QuickAddDraft, subcategoryID uint) error
+}
+
+// quickAddService is the concrete implementation
+type quickAddService struct {
+	productRepo     repository.ProductRepository
+	subcategoryRepo repository.SubcategoryRepository
+	productService  ProductService
+}
+
+// NewQuickAddService creates a new instance of QuickAddService
+func NewQuickAddService(
+	productRepo repository.ProductRepository,
+	subcategoryRepo repository.SubcategoryRepository,
+	productService ProductService,
+) QuickAddService {
+	return &quickAddService{
+		productRepo:     productRepo,
+		subcategoryRepo: subcategoryRepo,
+		productService:  productService,
+	}
+}
+
+// Draft extracts a product from the page and suggests where to file it. The
+// best suggestion is already set on the product.
+func (s *quickAddService) Draft(userID uint, pageURL, html string) (*QuickAddDraft, error) {
+	u, err := url.Parse(strings.TrimSpace(pageURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("url must be an http or https URL")
+	}
+	if strings.TrimSpace(html) == "" {
+		return nil, errors.New("html is required")
+	}
+
+	meta := pagemeta.Extract(u.String(), html)
+	now := time.Now()
+	product := &models.Product{
+		UserID:      userID,
+		Name:        limitRunes(meta.Name, 255),
+		Description: meta.Description,
+		SourceURL:   limitRunes(u.String(), 500),
+		ImageURL:    limitRunes(meta.Image, 500),
+		PriceDate:   &now,
+	}
+	draft := &QuickAddDraft{Product: product, Sources: meta.Sources, Missing: []string{}}
+
+	if meta.Price != nil {
+		product.BasePrice = *meta.Price
+	} else {
+		draft.Missing = append(draft.Missing, "price")
+	}
+	// El hook BeforeSave lo calcula al guardar, pero el borrador se muestra antes
+	product.TotalPrice = product.CalculateTotal()
+	// Sin moneda (o con una que no se reconoce) queda la moneda por defecto
+	currency, err := s.productService.ResolveCurrency(meta.Currency)
+	if meta.Currency == "" || err != nil {
+		currency, _ = s.productService.ResolveCurrency("")
+		delete(draft.Sources, "currency")
+		draft.Missing = append(draft.Missing, "currency")
+	}
+	product.Currency = currency
+	if product.Name == "" {
+		draft.Missing = append(draft.Missing, "name")
+	}
+	if product.ImageURL == "" {
+		draft.Missing = append(draft.Missing, "image")
+	}
+
+	if draft.Suggestions, err = s.suggest(userID, product); err != nil {
+		return nil, err
+	}
+	if len(draft.Suggestions) > 0 {
+		product.CategoryID = draft.Suggestions[0].CategoryID
+		product.SubcategoryID = draft.Suggestions[0].SubcategoryID
+	} else {
+		draft.Missing = append(draft.Missing, "subcategory")
+	}
+	return draft, nil
+}
+
+// Create saves a draft as a new product. subcategoryID overrides the
+// suggested subcategory; with 0 the best suggestion is used.
+func (s *quickAddService) Create(draft *QuickAddDraft, subcategoryID uint) error {
+	product := draft.Product
+	if subcategoryID != 0 {
+		subcategory, err := s.subcategoryRepo.FindByID(product.UserID, subcategoryID)
+		if err != nil {
+			return errors.New("subcategory not found")
+		}
+		product.CategoryID = subcategory.CategoryID
+		product.SubcategoryID = subcategory.ID
+
+		missing := draft.Missing[:0]
+		for _, field := range draft.Missing {
+			if field != "subcategory" {
+				missing = append(missing, field)
+			}
+		}
+		draft.Missing = missing
+	}
+
+	switch {
+	case product.Name == "":
+		return fmt.Errorf("%w: the page has no product name", ErrIncompleteDraft)
+	case !product.BasePrice.IsPositive():
+		return fmt.Errorf("%w: the page has no price", ErrIncompleteDraft)
+	case product.SubcategoryID == 0:
+		return fmt.Errorf("%w: no subcategory matches, choose one", ErrIncompleteDraft)
+	}
+
+	if err := s.productService.CreateProduct(product); err != nil {
+		return err
+	}
+	draft.Created = true
+	return nil
+}
+
+// suggest ranks the user's one-time subcategories for a product. A
+// subcategory scores by how much its name (or its category's) matches the
+// product name, or by how much the names of the products already filed in it
+// match the new one, whichever is higher.
+func (s *quickAddService) suggest(userID uint, product *models.Product) ([]SubcategorySuggestion, error) {
+	words := suggestionWords(product.Name)
+	if len(words) == 0 {
+		return []SubcategorySuggestion{}, nil
+	}
+
+	subcategories, err := s.subcategoryRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.productRepo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lo que ya se cargó en cada subcategoría se parece a lo que va a entrar
+	history := make(map[uint]float64)
+	for _, p := range products {
+		score := matchScore(suggestionWords(p.Name), words)
+		if score > history[p.SubcategoryID] {
+			history[p.SubcategoryID] = score
+		}
+	}
+
+	suggestions := []SubcategorySuggestion{}
+	for _, subcategory := range subcategories {
+		// Sólo compras únicas: las recurrentes necesitan una recurrencia que la página no trae
+		if subcategory.Category == nil || subcategory.Category.Type != "one_time" {
+			continue
+		}
+		score := matchScore(suggestionWords(subcategory.Name), words)
+		if byCategory := matchScore(suggestionWords(subcategory.Category.Name), words); byCategory*0.8 > score {
+			score = byCategory * 0.8 // El nombre de la categoría es menos específico
+		}
+		if history[subcategory.ID] > score {
+			score = history[subcategory.ID]
+		}
+		if score < minSuggestionScore {
+			continue
+		}
+		suggestions = append(suggestions, SubcategorySuggestion{
+			CategoryID:    subcategory.CategoryID,
+			Category:      subcategory.Category.Name,
+			SubcategoryID: subcategory.ID,
+			Subcategory:   subcategory.Name,
+			Score:         float64(int(score*100+0.5)) / 100,
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].SubcategoryID < suggestions[j].SubcategoryID
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions, nil
+}
+
+// suggestionWords tokenizes a name without stop words nor single letters
+func suggestionWords(name string) []string {
+	var words []string
+	for _, word := range search.Tokenize(name) {
+		if utf8.RuneCountInString(word) > 1 && !suggestionStopWords[word] {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// matchScore is the share of tokens that match one of the words: 1 for a
+// prefix match, the similarity for a typo, 0 below search.MinSimilarity
+func matchScore(tokens, words []string) float64 {
+	if len(tokens) == 0 || len(words) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, token := range tokens {
+		if score := search.WordScore(token, words); score >= search.MinSimilarity {
+			total += score
+		}
+	}
+	return total / float64(len(tokens))
+}
+
+// limitRunes cuts s to at most max characters, so it fits its column
+func limitRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
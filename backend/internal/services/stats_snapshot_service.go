@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+// StatsSnapshotSummary is what a SnapshotAll run did
+type StatsSnapshotSummary struct {
+	Taken   int
+	Skipped int // Usuarios a los que les falta una cotización
+}
+
+// StatsSnapshotService stores and lists the daily snapshots of the users' stats
+type StatsSnapshotService interface {
+	List(userID uint, from, to *time.Time) ([]*models.StatsSnapshot, error)
+	SnapshotAll(now time.Time) (*StatsSnapshotSummary, error)
+}
+
+// statsSnapshotService is the concrete implementation
+type statsSnapshotService struct {
+	snapshotRepo    repository.StatsSnapshotRepository
+	userRepo        repository.UserRepository
+	productService  ProductService
+	defaultCurrency string
+}
+
+// NewStatsSnapshotService creates a new instance of StatsSnapshotService. The
+// snapshots are taken in defaultCurrency.
+func NewStatsSnapshotService(snapshotRepo repository.StatsSnapshotRepository, userRepo repository.UserRepository, productService ProductService, defaultCurrency string) StatsSnapshotService {
+	return &statsSnapshotService{
+		snapshotRepo:    snapshotRepo,
+		userRepo:        userRepo,
+		productService:  productService,
+		defaultCurrency: defaultCurrency,
+	}
+}
+
+// List retrieves the user's snapshots, oldest first
+func (s *statsSnapshotService) List(userID uint, from, to *time.Time) ([]*models.StatsSnapshot, error) {
+	return s.snapshotRepo.FindAll(userID, from, to)
+}
+
+// SnapshotAll stores today's stats of every user, replacing the ones already
+// taken today. A user with a product in a currency without an exchange rate
+// is skipped, since their totals can't be added up.
+func (s *statsSnapshotService) SnapshotAll(now time.Time) (*StatsSnapshotSummary, error) {
+	userIDs, err := s.userRepo.FindAllIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	// El gasto mensual no se guarda: alcanza con el rango del mes actual
+	from := firstOfMonth(now)
+	opts := StatsOptions{Currency: s.defaultCurrency, From: from, To: from.AddDate(0, 1, 0)}
+	summary := &StatsSnapshotSummary{}
+	for _, userID := range userIDs {
+		stats, err := s.productService.GetStats(userID, opts)
+		if errors.Is(err, ErrNoExchangeRate) {
+			summary.Skipped++
+			continue
+		}
+		if err != nil {
+			return summary, err
+		}
+
+		snapshot := &models.StatsSnapshot{
+			UserID:                userID,
+			Date:                  models.DateOnly(now),
+			Currency:              stats.Currency,
+			PendingCount:          stats.PendingCount,
+			PurchasedCount:        stats.PurchasedCount,
+			PendingTotal:          stats.PendingTotal,
+			PurchasedTotal:        stats.PurchasedTotal,
+			TotalPendingOneTime:   stats.TotalPendingOneTime,
+			MonthlyRecurringCost:  stats.MonthlyRecurringCost,
+			InstallmentsRemaining: stats.InstallmentsRemaining,
+		}
+		if err := s.snapshotRepo.Upsert(snapshot); err != nil {
+			return summary, err
+		}
+		summary.Taken++
+	}
+	return summary, nil
+}
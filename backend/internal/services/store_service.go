@@ -0,0 +1,379 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateStore is returned when the user already has a store with the domain
+var ErrDuplicateStore = errors.New("store already exists")
+
+// StoreSummary is what is planned and spent at one store, in the stats currency.
+// The grouped fields assume all the pending products are bought in one order.
+type StoreSummary struct {
+	StoreID                *uint         `json:"store_id"` // nil = productos sin tienda
+	Name                   string        `json:"name"`
+	Domain                 string        `json:"domain"`
+	PendingCount           int64         `json:"pending_count"`
+	PendingSubtotal        money.Amount  `json:"pending_subtotal"` // Precio + impuestos, sin envío
+	PendingShipping        money.Amount  `json:"pending_shipping"` // El envío de cada producto por separado
+	PendingTotal           money.Amount  `json:"pending_total"`
+	PurchasedCount         int64         `json:"purchased_count"`
+	PurchasedTotal         money.Amount  `json:"purchased_total"`
+	FreeShippingThreshold  *money.Amount `json:"free_shipping_threshold"`
+	MissingForFreeShipping *money.Amount `json:"missing_for_free_shipping"` // Cuánto falta sumar al pedido; nil sin umbral
+	FreeShipping           bool          `json:"free_shipping"`             // El pedido llega al umbral
+	GroupedShipping        money.Amount  `json:"grouped_shipping"`          // Un solo envío: 0 con envío gratis, si no el de la tienda
+	GroupedTotal           money.Amount  `json:"grouped_total"`
+	ShippingSavings        money.Amount  `json:"shipping_savings"` // pending_total - grouped_total
+}
+
+// StoreStats are the one-time purchases grouped by store, the stores with the
+// most pending spend first
+type StoreStats struct {
+	Currency   string          `json:"currency"`
+	Stores     []*StoreSummary `json:"stores"`
+	Unassigned *StoreSummary   `json:"unassigned"` // Productos sin source_url o de un dominio sin tienda
+}
+
+// StoreService manages the user's stores and the links of products to them.
+// Changes to the stores recompute the taxes of the pending products, since a
+// product without a tax profile uses its store's.
+type StoreService interface {
+	List(userID uint) ([]*models.Store, error)
+	CreateStore(store *models.Store) error
+	UpdateStore(store *models.Store) error
+	DeleteStore(userID, id uint) error
+	GetStats(userID uint, currency string) (*StoreStats, error)
+}
+
+// storeService is the concrete implementation
+type storeService struct {
+	repo            repository.StoreRepository
+	productRepo     repository.ProductRepository
+	taxProfileRepo  repository.TaxProfileRepository
+	transactor      repository.Transactor
+	productService  ProductService
+	exchangeRates   ExchangeRateService
+	defaultCurrency string
+}
+
+// NewStoreService creates a new instance of StoreService
+func NewStoreService(
+	repo repository.StoreRepository,
+	productRepo repository.ProductRepository,
+	taxProfileRepo repository.TaxProfileRepository,
+	transactor repository.Transactor,
+	productService ProductService,
+	exchangeRates ExchangeRateService,
+	defaultCurrency string,
+) StoreService {
+	return &storeService{
+		repo:            repo,
+		productRepo:     productRepo,
+		taxProfileRepo:  taxProfileRepo,
+		transactor:      transactor,
+		productService:  productService,
+		exchangeRates:   exchangeRates,
+		defaultCurrency: defaultCurrency,
+	}
+}
+
+// List returns the user's stores sorted by name
+func (s *storeService) List(userID uint) ([]*models.Store, error) {
+	return s.repo.FindAll(userID)
+}
+
+// CreateStore validates and stores a new store, then links the products of
+// its domain to it
+func (s *storeService) CreateStore(store *models.Store) error {
+	if err := s.validateStore(store); err != nil {
+		return err
+	}
+
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Create(store); err != nil {
+			return err
+		}
+		return s.relink(tx, store.UserID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recalculateTaxes(store.UserID)
+	return nil
+}
+
+// UpdateStore validates and saves a store. If the domain changed, the products
+// are linked again.
+func (s *storeService) UpdateStore(store *models.Store) error {
+	if err := s.validateStore(store); err != nil {
+		return err
+	}
+
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Update(store); err != nil {
+			return err
+		}
+		return s.relink(tx, store.UserID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recalculateTaxes(store.UserID)
+	return nil
+}
+
+// DeleteStore removes a store. Its products are unlinked, or linked to another
+// store that also matches their URL.
+func (s *storeService) DeleteStore(userID, id uint) error {
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Delete(userID, id); err != nil {
+			return err
+		}
+		return s.relink(tx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recalculateTaxes(userID)
+	return nil
+}
+
+// recalculateTaxes recomputes the taxes of the pending products once the
+// store change is committed. A failure only gets logged: the store is saved.
+func (s *storeService) recalculateTaxes(userID uint) {
+	if _, err := s.productService.RecalculateTaxes(userID); err != nil {
+		log.Printf("Warning: failed to recalculate taxes for user %d: %v", userID, err)
+	}
+}
+
+// relink links every product of the user to the store matching its source URL
+// and saves the links that changed
+func (s *storeService) relink(tx *gorm.DB, userID uint) error {
+	stores, err := s.repo.WithTx(tx).FindAll(userID)
+	if err != nil {
+		return err
+	}
+	productRepo := s.productRepo.WithTx(tx)
+	products, err := productRepo.FindStoreLinks(userID)
+	if err != nil {
+		return err
+	}
+
+	// Productos a mover por tienda destino; 0 = sin tienda
+	changes := make(map[uint][]uint)
+	for _, product := range products {
+		var target uint
+		if store := matchStore(stores, models.NormalizeDomain(product.SourceURL)); store != nil {
+			target = store.ID
+		}
+		var current uint
+		if product.StoreID != nil {
+			current = *product.StoreID
+		}
+		if target != current {
+			changes[target] = append(changes[target], product.ID)
+		}
+	}
+
+	for target, ids := range changes {
+		var storeID *uint
+		if target != 0 {
+			id := target
+			storeID = &id
+		}
+		if err := productRepo.SetStore(userID, ids, storeID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetStats sums the one-time purchases of each store converted to currency
+// (empty = the default currency) with today's rates, and checks whether the
+// pending ones together reach the store's free-shipping threshold
+func (s *storeService) GetStats(userID uint, currency string) (*StoreStats, error) {
+	if currency == "" {
+		currency = s.defaultCurrency
+	}
+
+	stores, err := s.repo.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.productRepo.StoreTotals(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &StoreStats{
+		Currency:   currency,
+		Stores:     make([]*StoreSummary, 0, len(stores)),
+		Unassigned: &StoreSummary{},
+	}
+	byID := make(map[uint]*StoreSummary, len(stores))
+	for _, store := range stores {
+		summary := &StoreSummary{StoreID: &store.ID, Name: store.Name, Domain: store.Domain}
+		byID[store.ID] = summary
+		stats.Stores = append(stats.Stores, summary)
+	}
+
+	converter := s.exchangeRates.NewConverter(userID, currency, time.Now())
+	for _, row := range rows {
+		summary := stats.Unassigned
+		if row.StoreID != nil && byID[*row.StoreID] != nil {
+			summary = byID[*row.StoreID]
+		}
+		if err := summary.add(row, converter); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, store := range stores {
+		if err := byID[store.ID].group(store, converter); err != nil {
+			return nil, err
+		}
+	}
+	stats.Unassigned.GroupedShipping = stats.Unassigned.PendingShipping
+	stats.Unassigned.GroupedTotal = stats.Unassigned.PendingTotal
+
+	sort.SliceStable(stats.Stores, func(i, j int) bool {
+		return stats.Stores[i].PendingTotal > stats.Stores[j].PendingTotal
+	})
+	return stats, nil
+}
+
+// add sums a group of products, converted, to the summary
+func (summary *StoreSummary) add(row *repository.StoreTotals, converter *CurrencyConverter) error {
+	var amounts [4]money.Amount
+	for i, amount := range []money.Amount{row.BasePrice, row.ShippingCost, row.Taxes, row.Total} {
+		converted, err := converter.Convert(amount, row.Currency)
+		if err != nil {
+			return err
+		}
+		amounts[i] = converted
+	}
+	base, shipping, taxes, total := amounts[0], amounts[1], amounts[2], amounts[3]
+
+	if row.IsPurchased {
+		summary.PurchasedCount += row.Count
+		summary.PurchasedTotal = summary.PurchasedTotal.Add(total)
+		return nil
+	}
+	summary.PendingCount += row.Count
+	summary.PendingSubtotal = money.Sum(summary.PendingSubtotal, base, taxes)
+	summary.PendingShipping = summary.PendingShipping.Add(shipping)
+	summary.PendingTotal = summary.PendingTotal.Add(total)
+	return nil
+}
+
+// group works out the pending products bought in a single order: one shipment
+// at the store's cost, free once the subtotal reaches the threshold. A store
+// without a shipping cost keeps the shipping of each product.
+func (summary *StoreSummary) group(store *models.Store, converter *CurrencyConverter) error {
+	summary.GroupedShipping = summary.PendingShipping
+	if summary.PendingCount > 0 && store.DefaultShippingCost.IsPositive() {
+		shipping, err := converter.Convert(store.DefaultShippingCost, store.Currency)
+		if err != nil {
+			return err
+		}
+		summary.GroupedShipping = shipping
+	}
+
+	if store.FreeShippingThreshold != nil {
+		threshold, err := converter.Convert(*store.FreeShippingThreshold, store.Currency)
+		if err != nil {
+			return err
+		}
+		missing := threshold.Sub(summary.PendingSubtotal)
+		if !missing.IsPositive() {
+			missing = money.Zero
+		}
+		summary.FreeShippingThreshold = &threshold
+		summary.MissingForFreeShipping = &missing
+		summary.FreeShipping = summary.PendingCount > 0 && missing.IsZero()
+		if summary.FreeShipping {
+			summary.GroupedShipping = money.Zero
+		}
+	}
+
+	summary.GroupedTotal = summary.PendingSubtotal.Add(summary.GroupedShipping)
+	summary.ShippingSavings = summary.PendingTotal.Sub(summary.GroupedTotal)
+	return nil
+}
+
+// matchStore returns the store whose domain matches the host, the most
+// specific one if several do. Nil if none does.
+func matchStore(stores []*models.Store, host string) *models.Store {
+	if host == "" {
+		return nil
+	}
+	var match *models.Store
+	for _, store := range stores {
+		if store.Matches(host) && (match == nil || len(store.Domain) > len(match.Domain)) {
+			match = store
+		}
+	}
+	return match
+}
+
+// validateStore normalizes the domain (a URL is accepted too), checks it isn't
+// taken and that the tax profile exists, and fills in the defaults: the domain
+// as name and the default currency
+func (s *storeService) validateStore(store *models.Store) error {
+	store.Domain = models.NormalizeDomain(store.Domain)
+	if store.Domain == "" || !strings.Contains(store.Domain, ".") {
+		return errors.New("a valid domain is required")
+	}
+	if len(store.Domain) > 255 {
+		return errors.New("domain cannot be longer than 255 characters")
+	}
+
+	store.Name = strings.TrimSpace(store.Name)
+	if store.Name == "" {
+		store.Name = store.Domain
+	}
+	if len(store.Name) > 100 {
+		return errors.New("name cannot be longer than 100 characters")
+	}
+
+	if store.DefaultShippingCost.IsNegative() {
+		return errors.New("default shipping cost cannot be negative")
+	}
+	if store.FreeShippingThreshold != nil && store.FreeShippingThreshold.IsNegative() {
+		return errors.New("free shipping threshold cannot be negative")
+	}
+
+	if store.Currency == "" {
+		store.Currency = s.defaultCurrency
+	}
+	var err error
+	if store.Currency, err = money.NormalizeCurrency(store.Currency); err != nil {
+		return err
+	}
+
+	if store.TaxProfileID != nil {
+		if _, err := s.taxProfileRepo.FindByID(store.UserID, *store.TaxProfileID); err != nil {
+			return errors.New("tax profile not found")
+		}
+	}
+
+	existing, err := s.repo.FindByDomain(store.UserID, store.Domain)
+	if err == nil && existing.ID != store.ID {
+		return fmt.Errorf("%w with domain %s", ErrDuplicateStore, store.Domain)
+	}
+	return nil
+}
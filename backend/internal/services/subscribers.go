@@ -0,0 +1,63 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// SubscribePurchaseDate dates the purchase of a product marked as purchased
+// without a purchase date
+func SubscribePurchaseDate(bus *EventBus, productRepo repository.ProductRepository) {
+	bus.SubscribeTx(models.EventProductPurchased, func(tx *gorm.DB, event Event) error {
+		product := event.(ProductPurchased).Product
+		if product.PurchaseDate != nil {
+			return nil
+		}
+		now := time.Now()
+		product.PurchaseDate = &now
+		return productRepo.WithTx(tx).SetPurchaseDate(product.UserID, product.ID, now)
+	})
+}
+
+// SubscribePriceHistory stores the previous price of a product in the
+// history, in the transaction of the change
+func SubscribePriceHistory(bus *EventBus, priceHistoryRepo repository.PriceHistoryRepository) {
+	bus.SubscribeTx(models.EventPriceChanged, func(tx *gorm.DB, event Event) error {
+		previous := event.(PriceChanged).Previous
+		return priceHistoryRepo.WithTx(tx).Create(models.NewPriceHistoryFromProduct(previous))
+	})
+}
+
+// SubscribeAlerts evaluates the price alerts once a price change committed.
+// Un error en las alertas no debe hacer fallar la actualización del precio.
+func SubscribeAlerts(bus *EventBus, alertService AlertService) {
+	bus.Subscribe(models.EventPriceChanged, func(event Event) {
+		change := event.(PriceChanged)
+		// Un precio en otra moneda no es comparable con el anterior
+		if change.Product.Currency != change.Previous.Currency {
+			return
+		}
+		if _, err := alertService.EvaluatePriceChange(change.Product, change.Previous.TotalPrice); err != nil {
+			log.Printf("Warning: failed to evaluate alerts for product %d: %v", change.Product.ID, err)
+		}
+	})
+}
+
+// SubscribeWebhooks stores a delivery of every event for the webhooks
+// subscribed to it, in the transaction of the change, and starts sending them
+// once it committed
+func SubscribeWebhooks(bus *EventBus, webhooks WebhookService) {
+	for _, name := range models.WebhookEvents {
+		bus.SubscribeTx(name, func(tx *gorm.DB, event Event) error {
+			userID, data := webhookPayload(event)
+			return webhooks.WithTx(tx).Publish(userID, event.EventName(), data)
+		})
+		bus.Subscribe(name, func(Event) {
+			webhooks.DeliverPending()
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateTaxProfile is returned when the user already has a profile with the name
+var ErrDuplicateTaxProfile = errors.New("tax profile already exists")
+
+// maxTaxRules limits the rules of a profile
+const maxTaxRules = 20
+
+// TaxProfileService manages the user's tax profiles. Changing or deleting a
+// profile recomputes the taxes of the pending products that use it.
+type TaxProfileService interface {
+	List(userID uint) ([]*models.TaxProfile, error)
+	CreateProfile(profile *models.TaxProfile) error
+	UpdateProfile(profile *models.TaxProfile) error
+	DeleteProfile(userID, id uint) error
+}
+
+// taxProfileService is the concrete implementation
+type taxProfileService struct {
+	repo           repository.TaxProfileRepository
+	productRepo    repository.ProductRepository
+	storeRepo      repository.StoreRepository
+	transactor     repository.Transactor
+	productService ProductService
+}
+
+// NewTaxProfileService creates a new instance of TaxProfileService
+func NewTaxProfileService(
+	repo repository.TaxProfileRepository,
+	productRepo repository.ProductRepository,
+	storeRepo repository.StoreRepository,
+	transactor repository.Transactor,
+	productService ProductService,
+) TaxProfileService {
+	return &taxProfileService{
+		repo:           repo,
+		productRepo:    productRepo,
+		storeRepo:      storeRepo,
+		transactor:     transactor,
+		productService: productService,
+	}
+}
+
+// List returns the user's profiles with their rules, sorted by name
+func (s *taxProfileService) List(userID uint) ([]*models.TaxProfile, error) {
+	return s.repo.FindAll(userID)
+}
+
+// CreateProfile validates and stores a new profile with its rules
+func (s *taxProfileService) CreateProfile(profile *models.TaxProfile) error {
+	if err := s.validateProfile(profile); err != nil {
+		return err
+	}
+	return s.repo.Create(profile)
+}
+
+// UpdateProfile validates a profile, replaces its rules and recomputes the
+// taxes of the pending products that use it
+func (s *taxProfileService) UpdateProfile(profile *models.TaxProfile) error {
+	if err := s.validateProfile(profile); err != nil {
+		return err
+	}
+
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		return s.repo.WithTx(tx).Update(profile)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recalculate(profile.UserID)
+	return nil
+}
+
+// DeleteProfile removes a profile and unlinks the products and stores that
+// used it. Their products keep the last computed taxes.
+func (s *taxProfileService) DeleteProfile(userID, id uint) error {
+	err := s.transactor.WithinTransaction(func(tx *gorm.DB) error {
+		if err := s.repo.WithTx(tx).Delete(userID, id); err != nil {
+			return err
+		}
+		if err := s.productRepo.WithTx(tx).ClearTaxProfile(userID, id); err != nil {
+			return err
+		}
+		return s.storeRepo.WithTx(tx).ClearTaxProfile(userID, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recalculate(userID)
+	return nil
+}
+
+// recalculate recomputes the taxes of the user's pending products. It runs
+// after the commit, like the alerts, and a failure only gets logged: the
+// profile is already saved.
+func (s *taxProfileService) recalculate(userID uint) {
+	if _, err := s.productService.RecalculateTaxes(userID); err != nil {
+		log.Printf("Warning: failed to recalculate taxes for user %d: %v", userID, err)
+	}
+}
+
+// validateProfile checks the name and the rules, numbers the rules in the
+// order they came and fills in the default base: the price
+func (s *taxProfileService) validateProfile(profile *models.TaxProfile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(profile.Name) > 100 {
+		return errors.New("name cannot be longer than 100 characters")
+	}
+	if existing, err := s.repo.FindByName(profile.UserID, profile.Name); err == nil && existing.ID != profile.ID {
+		return fmt.Errorf("%w with name %s", ErrDuplicateTaxProfile, profile.Name)
+	}
+
+	if len(profile.Rules) == 0 {
+		return errors.New("a tax profile needs at least one rule")
+	}
+	if len(profile.Rules) > maxTaxRules {
+		return fmt.Errorf("a tax profile can have at most %d rules", maxTaxRules)
+	}
+	for i := range profile.Rules {
+		rule := &profile.Rules[i]
+		rule.Position = i + 1
+		if err := validateTaxRule(rule); err != nil {
+			return fmt.Errorf("rule %d: %w", rule.Position, err)
+		}
+	}
+	return nil
+}
+
+// validateTaxRule checks one rule. A percentage rule has no amount and a
+// fixed one no rate.
+func validateTaxRule(rule *models.TaxRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(rule.Name) > 100 {
+		return errors.New("name cannot be longer than 100 characters")
+	}
+
+	if rule.Base == "" {
+		rule.Base = models.TaxBasePrice
+	}
+	if !models.IsValidTaxBase(rule.Base) {
+		return errors.New("base must be 'price', 'price_shipping' or 'accumulated'")
+	}
+
+	switch rule.Kind {
+	case models.TaxRulePercentage:
+		if rule.Rate <= 0 || rule.Rate >= 1000 {
+			return errors.New("rate must be greater than 0 and less than 1000")
+		}
+		rule.Amount = money.Zero
+	case models.TaxRuleFixed:
+		if !rule.Amount.IsPositive() {
+			return errors.New("amount must be greater than 0")
+		}
+		rule.Rate = 0
+	default:
+		return errors.New("kind must be 'percentage' or 'fixed'")
+	}
+
+	if rule.Franchise != nil && rule.Franchise.IsNegative() {
+		return errors.New("franchise cannot be negative")
+	}
+	if rule.Currency != "" {
+		var err error
+		if rule.Currency, err = money.NormalizeCurrency(rule.Currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// computeTaxes applies the profile's rules in order to the product's price.
+// The amount and franchise of a rule in another currency are converted to the
+// product's.
+func computeTaxes(profile *models.TaxProfile, product *models.Product, converter *CurrencyConverter) (money.Amount, *models.TaxBreakdown, error) {
+	breakdown := &models.TaxBreakdown{
+		ProfileID: profile.ID,
+		Profile:   profile.Name,
+		Lines:     make([]models.TaxLine, 0, len(profile.Rules)),
+	}
+
+	taxes := money.Zero
+	for _, rule := range profile.Rules {
+		base := product.BasePrice
+		switch rule.Base {
+		case models.TaxBasePriceShipping:
+			base = base.Add(product.ShippingCost)
+		case models.TaxBaseAccumulated:
+			base = money.Sum(base, product.ShippingCost, taxes)
+		}
+
+		currency := rule.Currency
+		if currency == "" {
+			currency = product.Currency
+		}
+
+		// La franquicia se descuenta de la base; si la cubre, la regla no cobra
+		taxable := base
+		if rule.Franchise != nil {
+			franchise, err := converter.Convert(*rule.Franchise, currency)
+			if err != nil {
+				return money.Zero, nil, err
+			}
+			if taxable = base.Sub(franchise); taxable.IsNegative() {
+				taxable = money.Zero
+			}
+		}
+
+		line := models.TaxLine{Name: rule.Name, Kind: rule.Kind, Taxable: taxable}
+		switch rule.Kind {
+		case models.TaxRulePercentage:
+			line.Rate = rule.Rate
+			line.Amount = taxable.Percent(rule.Rate)
+		case models.TaxRuleFixed:
+			if rule.Franchise == nil || taxable.IsPositive() {
+				amount, err := converter.Convert(rule.Amount, currency)
+				if err != nil {
+					return money.Zero, nil, err
+				}
+				line.Amount = amount
+			}
+		}
+
+		taxes = taxes.Add(line.Amount)
+		breakdown.Lines = append(breakdown.Lines, line)
+	}
+	return taxes, breakdown, nil
+}
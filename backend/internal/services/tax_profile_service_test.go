@@ -0,0 +1,129 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+)
+
+func amountPtr(value string) *money.Amount {
+	a := money.MustParse(value)
+	return &a
+}
+
+func TestComputeTaxes(t *testing.T) {
+	product := &models.Product{
+		BasePrice:    money.MustParse("300000.00"),
+		ShippingCost: money.MustParse("20000.00"),
+		Currency:     "ARS",
+	}
+
+	type line struct{ taxable, amount string }
+	tests := []struct {
+		name  string
+		rules []models.TaxRule
+		want  string
+		lines []line
+	}{
+		{
+			name: "percentage without franchise",
+			rules: []models.TaxRule{
+				{Name: "IVA", Kind: models.TaxRulePercentage, Rate: 21, Base: models.TaxBasePrice},
+			},
+			want:  "63000.00",
+			lines: []line{{"300000.00", "63000.00"}},
+		},
+		{
+			name: "franchise in another currency is deducted from the base",
+			rules: []models.TaxRule{
+				{Name: "Aduana", Kind: models.TaxRulePercentage, Rate: 50, Base: models.TaxBasePrice, Franchise: amountPtr("50.00"), Currency: "USD"},
+			},
+			want:  "125000.00",
+			lines: []line{{"250000.00", "125000.00"}},
+		},
+		{
+			name: "franchise covering the base charges nothing",
+			rules: []models.TaxRule{
+				{Name: "Aduana", Kind: models.TaxRulePercentage, Rate: 50, Base: models.TaxBasePrice, Franchise: amountPtr("400.00"), Currency: "USD"},
+			},
+			want:  "0.00",
+			lines: []line{{"0.00", "0.00"}},
+		},
+		{
+			name: "fixed amount with the base above the franchise",
+			rules: []models.TaxRule{
+				{Name: "Tasa", Kind: models.TaxRuleFixed, Amount: money.MustParse("10.00"), Base: models.TaxBasePrice, Franchise: amountPtr("200.00"), Currency: "USD"},
+			},
+			want:  "10000.00",
+			lines: []line{{"100000.00", "10000.00"}},
+		},
+		{
+			name: "fixed amount with the base equal to the franchise",
+			rules: []models.TaxRule{
+				{Name: "Tasa", Kind: models.TaxRuleFixed, Amount: money.MustParse("10.00"), Base: models.TaxBasePrice, Franchise: amountPtr("300.00"), Currency: "USD"},
+			},
+			want:  "0.00",
+			lines: []line{{"0.00", "0.00"}},
+		},
+		{
+			name: "fixed amount without franchise always applies",
+			rules: []models.TaxRule{
+				{Name: "Correo", Kind: models.TaxRuleFixed, Amount: money.MustParse("5000.00"), Base: models.TaxBasePrice},
+			},
+			want:  "5000.00",
+			lines: []line{{"300000.00", "5000.00"}},
+		},
+		{
+			name: "franchise on the price plus shipping",
+			rules: []models.TaxRule{
+				{Name: "Aduana", Kind: models.TaxRulePercentage, Rate: 50, Base: models.TaxBasePriceShipping, Franchise: amountPtr("300000.00")},
+			},
+			want:  "10000.00",
+			lines: []line{{"20000.00", "10000.00"}},
+		},
+		{
+			name: "accumulated base includes the previous taxes",
+			rules: []models.TaxRule{
+				{Name: "IVA", Kind: models.TaxRulePercentage, Rate: 21, Base: models.TaxBasePriceShipping},
+				{Name: "Percepción", Kind: models.TaxRulePercentage, Rate: 35, Base: models.TaxBaseAccumulated},
+			},
+			want:  "202720.00",
+			lines: []line{{"320000.00", "67200.00"}, {"387200.00", "135520.00"}},
+		},
+		{
+			name: "franchise on the accumulated base",
+			rules: []models.TaxRule{
+				{Name: "IVA", Kind: models.TaxRulePercentage, Rate: 21, Base: models.TaxBasePriceShipping},
+				{Name: "Aduana", Kind: models.TaxRulePercentage, Rate: 50, Base: models.TaxBaseAccumulated, Franchise: amountPtr("300.00"), Currency: "USD"},
+			},
+			want:  "110800.00",
+			lines: []line{{"320000.00", "67200.00"}, {"87200.00", "43600.00"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Con la cotización ya cargada el conversor no consulta la base
+			converter := &CurrencyConverter{to: "ARS", rates: map[string]conversion{"USD": {rate: 1000}}}
+			profile := &models.TaxProfile{ID: 1, Name: "Test", Rules: tt.rules}
+
+			taxes, breakdown, err := computeTaxes(profile, product, converter)
+			if err != nil {
+				t.Fatalf("computeTaxes: %v", err)
+			}
+			if want := money.MustParse(tt.want); taxes != want {
+				t.Errorf("taxes = %s, want %s", taxes, want)
+			}
+			if len(breakdown.Lines) != len(tt.lines) {
+				t.Fatalf("breakdown has %d lines, want %d", len(breakdown.Lines), len(tt.lines))
+			}
+			for i, want := range tt.lines {
+				got := breakdown.Lines[i]
+				if got.Taxable != money.MustParse(want.taxable) || got.Amount != money.MustParse(want.amount) {
+					t.Errorf("line %d = taxable %s, amount %s; want %s, %s", i+1, got.Taxable, got.Amount, want.taxable, want.amount)
+				}
+			}
+		})
+	}
+}
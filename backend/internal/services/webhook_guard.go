@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// webhookResolveTimeout bounds the DNS lookup of a webhook host when it's saved
+const webhookResolveTimeout = 5 * time.Second
+
+// errWebhookAddressBlocked is returned for hosts in the server's own networks
+var errWebhookAddressBlocked = errors.New("webhook URL must point to a public address")
+
+// Rangos que no son privados para net.IP pero tampoco son destinos públicos
+var blockedWebhookNets = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),     // "Esta red"
+	mustParseCIDR("100.64.0.0/10"), // CGNAT, compartida con el proveedor
+	mustParseCIDR("192.0.0.0/24"),  // Asignaciones de protocolo
+	mustParseCIDR("198.18.0.0/15"), // Benchmarking
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// publicIP tells whether a webhook may be sent to ip: loopback, private,
+// link-local, unspecified and multicast addresses are the server's own network
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range blockedWebhookNets {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkWebhookHost resolves a webhook host and rejects it if any of its
+// addresses isn't public. The dialer checks again on every connection, since
+// the DNS answer can change after the webhook is saved.
+func checkWebhookHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !publicIP(ip) {
+			return errWebhookAddressBlocked
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return errors.New("webhook host could not be resolved")
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return errWebhookAddressBlocked
+		}
+	}
+	return nil
+}
+
+// webhookDialControl runs right before each connection, with the address
+// already resolved, so neither DNS rebinding nor a redirect reach a
+// non-public address
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return errWebhookAddressBlocked
+	}
+	return nil
+}
+
+// newWebhookClient builds the client that posts the deliveries. Without a
+// proxy: it would connect in our place and skip the address check.
+func newWebhookClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: webhookTimeout}
+	if !allowPrivate {
+		dialer.Control = webhookDialControl
+	}
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// webhookFailure is what the delivery log shows of a failed request. Only a
+// category: the network error would tell the user which ports and hosts the
+// server reaches.
+func webhookFailure(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errWebhookAddressBlocked):
+		return errWebhookAddressBlocked.Error()
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "webhook request timed out"
+	default:
+		return "webhook request failed"
+	}
+}
@@ -0,0 +1,466 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/money"
+	"github.com/buylist-manager/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	// webhookMaxAttempts is how many times a delivery is tried before it's failed
+	webhookMaxAttempts = 10
+	// webhookFirstRetry is the wait after the first failure; it doubles on each one
+	webhookFirstRetry = time.Minute
+	// webhookMaxRetry caps the wait between attempts
+	webhookMaxRetry = 6 * time.Hour
+	// webhookTimeout bounds a single POST
+	webhookTimeout = 10 * time.Second
+	// webhookBatchSize is how many due deliveries are sent per pass
+	webhookBatchSize = 100
+	// webhookPassTimeout bounds a DeliverPending pass
+	webhookPassTimeout = 5 * time.Minute
+	// minWebhookSecretLength keeps the HMAC secrets from being guessable
+	minWebhookSecretLength = 16
+)
+
+// Headers of the webhook requests
+const (
+	WebhookEventHeader     = "X-Buylist-Event"
+	WebhookDeliveryHeader  = "X-Buylist-Delivery"
+	WebhookSignatureHeader = "X-Buylist-Signature"
+)
+
+// WebhookEvent is the body posted to the webhooks
+type WebhookEvent struct {
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// ProductPayload is the data of the product.* events
+type ProductPayload struct {
+	Product *models.Product `json:"product"`
+}
+
+// newProductPayload copies the product without its relations: the loaded
+// ones may be stale after changing category_id or subcategory_id
+func newProductPayload(product *models.Product) ProductPayload {
+	copied := *product
+	copied.Category, copied.Subcategory = nil, nil
+	return ProductPayload{Product: &copied}
+}
+
+// PriceChangePayload is the data of price.changed: the product with its new
+// price and the price it had before
+type PriceChangePayload struct {
+	ProductPayload
+	Previous PriceSnapshot `json:"previous"`
+}
+
+// PriceSnapshot is a product's price at some moment
+type PriceSnapshot struct {
+	BasePrice    money.Amount `json:"base_price"`
+	ShippingCost money.Amount `json:"shipping_cost"`
+	Taxes        money.Amount `json:"taxes"`
+	TotalPrice   money.Amount `json:"total_price"`
+	Currency     string       `json:"currency"`
+}
+
+// NewPriceSnapshot copies the price of a product
+func NewPriceSnapshot(product *models.Product) PriceSnapshot {
+	return PriceSnapshot{
+		BasePrice:    product.BasePrice,
+		ShippingCost: product.ShippingCost,
+		Taxes:        product.Taxes,
+		TotalPrice:   product.TotalPrice,
+		Currency:     product.Currency,
+	}
+}
+
+// webhookPayload returns the owner of a domain event and the data sent for it
+func webhookPayload(event Event) (uint, interface{}) {
+	switch e := event.(type) {
+	case ProductCreated:
+		return e.Product.UserID, newProductPayload(e.Product)
+	case ProductUpdated:
+		return e.Product.UserID, newProductPayload(e.Product)
+	case ProductPurchased:
+		return e.Product.UserID, newProductPayload(e.Product)
+	case ProductDeleted:
+		return e.Product.UserID, newProductPayload(e.Product)
+	case PriceChanged:
+		return e.Product.UserID, PriceChangePayload{
+			ProductPayload: newProductPayload(e.Product),
+			Previous:       NewPriceSnapshot(e.Previous),
+		}
+	default:
+		return 0, nil
+	}
+}
+
+// WebhookDeliverySummary counts what a delivery pass did
+type WebhookDeliverySummary struct {
+	Sent     int
+	Retrying int
+	Failed   int // Agotaron los intentos en esta pasada
+}
+
+// WebhookService manages the user's webhooks and delivers their events.
+// Publish only stores the deliveries, in the caller's transaction when the
+// service comes from WithTx, so an event is sent only if its change was
+// committed. DeliverDue sends them and retries the failed ones with
+// exponential backoff.
+type WebhookService interface {
+	List(userID uint) ([]*models.Webhook, error)
+	CreateWebhook(webhook *models.Webhook) error
+	UpdateWebhook(webhook *models.Webhook) error
+	DeleteWebhook(userID, id uint) error
+	GetDeliveries(userID, webhookID uint, status string, limit int) ([]*models.WebhookDelivery, error)
+	Publish(userID uint, event string, data interface{}) error
+	DeliverDue(ctx context.Context) (*WebhookDeliverySummary, error)
+	DeliverPending()
+	WithTx(tx *gorm.DB) WebhookService
+}
+
+// webhookService is the concrete implementation
+type webhookService struct {
+	repo         repository.WebhookRepository
+	deliveryRepo repository.WebhookDeliveryRepository
+	client       *http.Client
+	allowPrivate bool            // Webhooks a la red local (self-hosted); nunca en un servidor público
+	root         *webhookService // El servicio fuera de transacciones, para enviar en segundo plano
+
+	// Del root: a lo sumo un envío en segundo plano a la vez
+	mu          sync.Mutex
+	passRunning bool
+	passAgain   bool
+}
+
+// NewWebhookService creates a new instance of WebhookService. Unless
+// allowPrivate, webhooks can only point to public addresses.
+func NewWebhookService(repo repository.WebhookRepository, deliveryRepo repository.WebhookDeliveryRepository, allowPrivate bool) WebhookService {
+	s := &webhookService{
+		repo:         repo,
+		deliveryRepo: deliveryRepo,
+		client:       newWebhookClient(allowPrivate),
+		allowPrivate: allowPrivate,
+	}
+	s.root = s
+	return s
+}
+
+// WithTx returns a copy of the service that stores the deliveries inside the
+// given transaction
+func (s *webhookService) WithTx(tx *gorm.DB) WebhookService {
+	return &webhookService{
+		repo:         s.repo.WithTx(tx),
+		deliveryRepo: s.deliveryRepo.WithTx(tx),
+		client:       s.client,
+		allowPrivate: s.allowPrivate,
+		root:         s.root,
+	}
+}
+
+// List returns the user's webhooks
+func (s *webhookService) List(userID uint) ([]*models.Webhook, error) {
+	return s.repo.FindAll(userID)
+}
+
+// CreateWebhook validates and stores a webhook
+func (s *webhookService) CreateWebhook(webhook *models.Webhook) error {
+	if err := s.validateWebhook(webhook); err != nil {
+		return err
+	}
+	return s.repo.Create(webhook)
+}
+
+// UpdateWebhook validates and saves a webhook. Its pending deliveries are
+// sent to the new URL with the new secret.
+func (s *webhookService) UpdateWebhook(webhook *models.Webhook) error {
+	if err := s.validateWebhook(webhook); err != nil {
+		return err
+	}
+	return s.repo.Update(webhook)
+}
+
+// DeleteWebhook deletes a webhook with its deliveries
+func (s *webhookService) DeleteWebhook(userID, id uint) error {
+	return s.repo.Delete(userID, id)
+}
+
+// GetDeliveries returns the latest deliveries of a webhook
+func (s *webhookService) GetDeliveries(userID, webhookID uint, status string, limit int) ([]*models.WebhookDelivery, error) {
+	if _, err := s.repo.FindByID(userID, webhookID); err != nil {
+		return nil, err
+	}
+	return s.deliveryRepo.FindByWebhookID(userID, webhookID, status, limit)
+}
+
+// Publish stores a delivery of the event for each active webhook of the user
+// subscribed to it. They're sent by DeliverDue.
+func (s *webhookService) Publish(userID uint, event string, data interface{}) error {
+	webhooks, err := s.repo.FindActive(userID)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(event) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(WebhookEvent{Event: event, OccurredAt: now, Data: data}); err != nil {
+				return err
+			}
+		}
+
+		delivery := &models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			UserID:        userID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        models.DeliveryStatusPending,
+			NextAttemptAt: &now,
+		}
+		if err := s.deliveryRepo.Create(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverPending sends the due deliveries in the background. Call it after
+// committing the changes that published events; inside a transaction the
+// events wait for the scheduled job instead. It never uses the transaction.
+// Calls while a pass is running make it do one more pass instead of starting
+// another one.
+func (s *webhookService) DeliverPending() {
+	root := s.root
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if root.passRunning {
+		root.passAgain = true
+		return
+	}
+	root.passRunning = true
+
+	go func() {
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), webhookPassTimeout)
+			if _, err := root.DeliverDue(ctx); err != nil {
+				log.Printf("Warning: failed to deliver webhooks: %v", err)
+			}
+			cancel()
+
+			root.mu.Lock()
+			again := root.passAgain
+			root.passAgain, root.passRunning = false, again
+			root.mu.Unlock()
+			if !again {
+				return
+			}
+		}
+	}()
+}
+
+// DeliverDue sends the pending deliveries whose attempt has come, of every
+// user. Each one is claimed first, so a delivery isn't sent twice at once by
+// the scheduled job and a DeliverPending.
+func (s *webhookService) DeliverDue(ctx context.Context) (*WebhookDeliverySummary, error) {
+	now := time.Now()
+	deliveries, err := s.deliveryRepo.FindDue(now, webhookBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &WebhookDeliverySummary{}
+	for _, delivery := range deliveries {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		// Si el proceso se cae a mitad del envío, la entrega se reintenta al vencer la reserva
+		claimed, err := s.deliveryRepo.Claim(delivery.ID, now, time.Now().Add(2*webhookTimeout))
+		if err != nil {
+			return summary, err
+		}
+		if !claimed {
+			continue
+		}
+
+		s.attempt(ctx, delivery)
+		if err := s.deliveryRepo.Update(delivery); err != nil {
+			return summary, err
+		}
+		switch delivery.Status {
+		case models.DeliveryStatusSuccess:
+			summary.Sent++
+		case models.DeliveryStatusFailed:
+			summary.Failed++
+		default:
+			summary.Retrying++
+		}
+	}
+	return summary, nil
+}
+
+// attempt posts a delivery to its webhook and records the outcome: delivered
+// on a 2xx response, otherwise retried after an exponential backoff until the
+// attempts run out
+func (s *webhookService) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+
+	status, err := s.post(ctx, delivery)
+	delivery.ResponseStatus = status
+	if err == nil {
+		delivery.Status = models.DeliveryStatusSuccess
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = limitRunes(err.Error(), 500)
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = models.DeliveryStatusFailed
+		delivery.NextAttemptAt = nil
+		return
+	}
+	next := now.Add(webhookBackoff(delivery.Attempts))
+	delivery.NextAttemptAt = &next
+}
+
+// post sends the payload signed with the webhook's secret. Returns the
+// response status (0 without a response) and an error unless it's a 2xx.
+func (s *webhookService) post(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	webhook := delivery.Webhook
+	if webhook == nil {
+		return 0, errors.New("webhook not found")
+	}
+	if !webhook.Active {
+		return 0, errors.New("webhook is inactive")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "BuyList-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(webhook.Secret, time.Now(), []byte(delivery.Payload)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.Printf("Warning: webhook delivery %d failed: %v", delivery.ID, err)
+		return 0, errors.New(webhookFailure(err))
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhook returns the signature header of a payload: "t=<unix
+// timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<payload>">". The receiver
+// recomputes it with the secret and can reject old timestamps (replays).
+func SignWebhook(secret string, at time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// webhookBackoff is the wait after the given failed attempt: 1m, 2m, 4m...
+// up to webhookMaxRetry
+func webhookBackoff(attempts int) time.Duration {
+	wait := webhookFirstRetry
+	for i := 1; i < attempts && wait < webhookMaxRetry; i++ {
+		wait *= 2
+	}
+	if wait > webhookMaxRetry {
+		wait = webhookMaxRetry
+	}
+	return wait
+}
+
+// validateWebhook checks the URL, whose host must resolve to public
+// addresses, the secret and the events, which are deduplicated
+func (s *webhookService) validateWebhook(webhook *models.Webhook) error {
+	webhook.URL = strings.TrimSpace(webhook.URL)
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("a valid http or https URL is required")
+	}
+	if len(webhook.URL) > 500 {
+		return errors.New("url cannot be longer than 500 characters")
+	}
+	if !s.allowPrivate {
+		ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+		defer cancel()
+		if err := checkWebhookHost(ctx, parsed.Hostname()); err != nil {
+			return err
+		}
+	}
+
+	if len(webhook.Secret) < minWebhookSecretLength {
+		return fmt.Errorf("secret must be at least %d characters", minWebhookSecretLength)
+	}
+	if len(webhook.Secret) > 255 {
+		return errors.New("secret cannot be longer than 255 characters")
+	}
+
+	if len(webhook.Events) == 0 {
+		return fmt.Errorf("at least one event is required: %s", strings.Join(models.WebhookEvents, ", "))
+	}
+	seen := make(map[string]bool, len(webhook.Events))
+	events := make(models.EventList, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if !isWebhookEvent(event) {
+			return fmt.Errorf("unknown event %q: use %s", event, strings.Join(models.WebhookEvents, ", "))
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	webhook.Events = events
+	return nil
+}
+
+// isWebhookEvent tells whether a webhook can subscribe to the event
+func isWebhookEvent(event string) bool {
+	for _, e := range models.WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/buylist-manager/backend/internal/models"
+	"github.com/buylist-manager/backend/internal/repository"
+)
+
+func TestSignWebhook(t *testing.T) {
+	// Calculado aparte: printf '%s' '1700000000.{"event":"product.created"}' | openssl dgst -sha256 -hmac whsec_0123456789abcdef
+	got := SignWebhook("whsec_0123456789abcdef", time.Unix(1700000000, 0), []byte(`{"event":"product.created"}`))
+	want := "t=1700000000,v1=7981e91436b9025f528b3e3eacae4cfdf19f10715d2211a42ed7291a0f77d62f"
+	if got != want {
+		t.Errorf("SignWebhook() = %q, want %q", got, want)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, webhookMaxRetry},
+		{50, webhookMaxRetry},
+	}
+
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// webhookReceiver is a stand-in for the user's endpoint that answers with
+// the given statuses in order, the last one from then on
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, string(body))
+	status := r.statuses[0]
+	if len(r.statuses) > 1 {
+		r.statuses = r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+// newTestWebhook starts a receiver and subscribes a webhook of the test user
+// to product.created on it
+func newTestWebhook(t *testing.T, env *testEnv, statuses ...int) (WebhookService, *webhookReceiver, repository.WebhookDeliveryRepository) {
+	t.Helper()
+	receiver := &webhookReceiver{statuses: statuses}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	deliveries := repository.NewWebhookDeliveryRepository(env.db)
+	webhooks := NewWebhookService(repository.NewWebhookRepository(env.db), deliveries, true)
+	webhook := &models.Webhook{
+		UserID: env.user.ID, URL: server.URL, Secret: "whsec_0123456789abcdef",
+		Events: models.EventList{models.EventProductCreated}, Active: true,
+	}
+	if err := webhooks.CreateWebhook(webhook); err != nil {
+		t.Fatal(err)
+	}
+	return webhooks, receiver, deliveries
+}
+
+// delivery returns the only webhook delivery of the test user
+func (e *testEnv) delivery(t *testing.T) *models.WebhookDelivery {
+	t.Helper()
+	var delivery models.WebhookDelivery
+	if err := e.db.Where("user_id = ?", e.user.ID).First(&delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &delivery
+}
+
+func TestDeliverDueRetriesWithBackoffUntilDelivered(t *testing.T) {
+	env := newTestEnv(t)
+	webhooks, receiver, _ := newTestWebhook(t, env, http.StatusInternalServerError, http.StatusOK)
+
+	if err := webhooks.Publish(env.user.ID, models.EventProductCreated, map[string]string{"name": "Mate"}); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := webhooks.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *summary != (WebhookDeliverySummary{Retrying: 1}) {
+		t.Fatalf("first pass = %+v, want one retrying", *summary)
+	}
+	delivery := env.delivery(t)
+	if delivery.Status != models.DeliveryStatusPending || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusInternalServerError {
+		t.Fatalf("after a 500: status %q, %d attempts, response %d", delivery.Status, delivery.Attempts, delivery.ResponseStatus)
+	}
+	if delivery.NextAttemptAt == nil || !delivery.NextAttemptAt.Equal(delivery.LastAttemptAt.Add(webhookFirstRetry)) {
+		t.Fatalf("next attempt at %v, want %v after %v", delivery.NextAttemptAt, webhookFirstRetry, delivery.LastAttemptAt)
+	}
+
+	// Antes de que venza la espera no se reintenta
+	summary, err = webhooks.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *summary != (WebhookDeliverySummary{}) {
+		t.Fatalf("pass before the retry = %+v, want nothing sent", *summary)
+	}
+
+	past := time.Now().Add(-time.Second)
+	if err := env.db.Model(delivery).Update("next_attempt_at", past).Error; err != nil {
+		t.Fatal(err)
+	}
+	summary, err = webhooks.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *summary != (WebhookDeliverySummary{Sent: 1}) {
+		t.Fatalf("retry pass = %+v, want one sent", *summary)
+	}
+	delivery = env.delivery(t)
+	if delivery.Status != models.DeliveryStatusSuccess || delivery.Attempts != 2 || delivery.NextAttemptAt != nil || delivery.DeliveredAt == nil {
+		t.Fatalf("after a 200: status %q, %d attempts, next %v, delivered %v",
+			delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.DeliveredAt)
+	}
+
+	if len(receiver.requests) != 2 {
+		t.Fatalf("receiver got %d requests, want 2", len(receiver.requests))
+	}
+	for i, req := range receiver.requests {
+		if req.Header.Get(WebhookEventHeader) != models.EventProductCreated {
+			t.Errorf("request %d: event header %q", i, req.Header.Get(WebhookEventHeader))
+		}
+		if !strings.Contains(receiver.bodies[i], `"name":"Mate"`) {
+			t.Errorf("request %d: body %s", i, receiver.bodies[i])
+		}
+		signature := req.Header.Get(WebhookSignatureHeader)
+		timestamp, err := strconv.ParseInt(strings.TrimPrefix(strings.SplitN(signature, ",", 2)[0], "t="), 10, 64)
+		if err != nil {
+			t.Fatalf("request %d: signature %q has no timestamp", i, signature)
+		}
+		if want := SignWebhook("whsec_0123456789abcdef", time.Unix(timestamp, 0), []byte(receiver.bodies[i])); signature != want {
+			t.Errorf("request %d: signature %q, want %q", i, signature, want)
+		}
+	}
+}
+
+func TestDeliverDueSkipsClaimedDeliveries(t *testing.T) {
+	env := newTestEnv(t)
+	webhooks, receiver, deliveries := newTestWebhook(t, env, http.StatusOK)
+
+	if err := webhooks.Publish(env.user.ID, models.EventProductCreated, map[string]string{"name": "Mate"}); err != nil {
+		t.Fatal(err)
+	}
+	delivery := env.delivery(t)
+
+	// Otro proceso la reservó: ni él ni DeliverDue pueden volver a tomarla
+	now := time.Now()
+	claimed, err := deliveries.Claim(delivery.ID, now, now.Add(time.Minute))
+	if err != nil || !claimed {
+		t.Fatalf("Claim() = %v, %v, want true", claimed, err)
+	}
+	if claimed, err = deliveries.Claim(delivery.ID, now, now.Add(time.Minute)); err != nil || claimed {
+		t.Fatalf("second Claim() = %v, %v, want false", claimed, err)
+	}
+
+	summary, err := webhooks.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *summary != (WebhookDeliverySummary{}) || len(receiver.requests) != 0 {
+		t.Fatalf("pass over a claimed delivery = %+v with %d requests, want nothing sent", *summary, len(receiver.requests))
+	}
+
+	// Si el proceso se cayó, la entrega se envía al vencer la reserva
+	if err := env.db.Model(delivery).Update("next_attempt_at", now.Add(-time.Second)).Error; err != nil {
+		t.Fatal(err)
+	}
+	summary, err = webhooks.DeliverDue(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *summary != (WebhookDeliverySummary{Sent: 1}) || len(receiver.requests) != 1 {
+		t.Fatalf("pass after the claim expired = %+v with %d requests, want one sent", *summary, len(receiver.requests))
+	}
+}